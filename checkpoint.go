@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+)
+
+// checkpointEntry is one NDJSON line of a batch checkpoint file
+type checkpointEntry struct {
+	GTID     string               `json:"gtid"`               // Input GTID set, as printed by GTIDSet.String
+	Position *models.GTIDPosition `json:"position,omitempty"` // Nil when not found
+	Error    string               `json:"error,omitempty"`    // Search failure skipped with -continue-on-error
+}
+
+// checkpoint records the resolved entries of a -gtid-file batch, so a restarted run
+// skips them. Entries are appended as they are resolved; a line cut off by a crash
+// is dropped on open.
+type checkpoint struct {
+	file     *os.File
+	resolved map[string]*checkpointEntry
+}
+
+// openCheckpoint loads the entries already resolved in path and opens it for appending
+func openCheckpoint(path string) (*checkpoint, error) {
+	resolved, size, err := loadCheckpoint(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+
+	// Drop a partial last line so new entries start on a line of their own
+	if err := file.Truncate(size); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to truncate checkpoint: %w", err)
+	}
+
+	return &checkpoint{file: file, resolved: resolved}, nil
+}
+
+// loadCheckpoint reads the entries of a checkpoint file, none if it does not exist.
+// It also returns the size of its complete lines.
+func loadCheckpoint(path string) (map[string]*checkpointEntry, int64, error) {
+	resolved := make(map[string]*checkpointEntry)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return resolved, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	// A line cut off by a crash has no trailing newline
+	complete := data[:bytes.LastIndexByte(data, '\n')+1]
+
+	for i, line := range bytes.Split(complete, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+
+		var entry checkpointEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, 0, fmt.Errorf("invalid checkpoint line %d: %v", i+1, err)
+		}
+		resolved[entry.GTID] = &entry
+	}
+
+	return resolved, int64(len(complete)), nil
+}
+
+// Lookup returns the recorded entry of an input GTID set
+func (c *checkpoint) Lookup(gtid string) (*checkpointEntry, bool) {
+	entry, ok := c.resolved[gtid]
+	return entry, ok
+}
+
+// Record appends a resolved entry; position is nil when the set was not found
+func (c *checkpoint) Record(gtid string, position *models.GTIDPosition) error {
+	return c.append(&checkpointEntry{GTID: gtid, Position: position})
+}
+
+// RecordFailure appends an entry whose search failed, so a restart does not retry it
+func (c *checkpoint) RecordFailure(gtid string, err error) error {
+	return c.append(&checkpointEntry{GTID: gtid, Error: err.Error()})
+}
+
+// append writes an entry as one line
+func (c *checkpoint) append(entry *checkpointEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint entry: %w", err)
+	}
+	if _, err := c.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+
+	c.resolved[entry.GTID] = entry
+	return nil
+}
+
+// Close closes the checkpoint file
+func (c *checkpoint) Close() error {
+	return c.file.Close()
+}
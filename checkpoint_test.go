@@ -0,0 +1,155 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
+	"github.com/quyetmv/mysql-gtid-position/models"
+)
+
+func TestCheckpoint_Restart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gtids.checkpoint")
+
+	cp, err := openCheckpoint(path)
+	if err != nil {
+		t.Fatalf("openCheckpoint() error = %v", err)
+	}
+	position := &models.GTIDPosition{BinlogFile: "mysql-bin.000002", GTID: testutil.FixtureUUID + ":150", GNO: 150}
+	if err := cp.Record("a", position); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := cp.Record("b", nil); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := cp.RecordFailure("c", errors.New("-uuid not in the set")); err != nil {
+		t.Fatalf("RecordFailure() error = %v", err)
+	}
+	cp.Close()
+
+	// A restarted run finds every entry resolved by the first one
+	cp, err = openCheckpoint(path)
+	if err != nil {
+		t.Fatalf("openCheckpoint() error = %v", err)
+	}
+	defer cp.Close()
+
+	if entry, ok := cp.Lookup("a"); !ok || entry.Position == nil || entry.Position.GNO != 150 {
+		t.Errorf("Expected the found position of a, got %+v", entry)
+	}
+	if entry, ok := cp.Lookup("b"); !ok || entry.Position != nil || entry.Error != "" {
+		t.Errorf("Expected b not found, got %+v", entry)
+	}
+	if entry, ok := cp.Lookup("c"); !ok || entry.Error != "-uuid not in the set" {
+		t.Errorf("Expected the recorded failure of c, got %+v", entry)
+	}
+	if _, ok := cp.Lookup("d"); ok {
+		t.Error("Expected d unresolved")
+	}
+}
+
+func TestCheckpoint_PartialLastLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gtids.checkpoint")
+	complete := `{"gtid":"a"}` + "\n"
+	if err := os.WriteFile(path, []byte(complete+`{"gtid":"b","posi`), 0644); err != nil {
+		t.Fatalf("Failed to write checkpoint: %v", err)
+	}
+
+	cp, err := openCheckpoint(path)
+	if err != nil {
+		t.Fatalf("openCheckpoint() error = %v", err)
+	}
+	if _, ok := cp.Lookup("a"); !ok {
+		t.Error("Expected a resolved")
+	}
+	if _, ok := cp.Lookup("b"); ok {
+		t.Error("Expected b, cut off by a crash, to be searched again")
+	}
+	if err := cp.Record("b", nil); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	cp.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read checkpoint: %v", err)
+	}
+	if want := complete + `{"gtid":"b"}` + "\n"; string(data) != want {
+		t.Errorf("Expected the partial line replaced, got %q", data)
+	}
+}
+
+func TestCheckpoint_CorruptLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gtids.checkpoint")
+	if err := os.WriteFile(path, []byte(`{"gtid":"a"}`+"\nnot json\n"), 0644); err != nil {
+		t.Fatalf("Failed to write checkpoint: %v", err)
+	}
+
+	_, err := openCheckpoint(path)
+	if err == nil || !strings.Contains(err.Error(), "invalid checkpoint line 2") {
+		t.Errorf("Expected an invalid line 2 error, got %v", err)
+	}
+}
+
+// TestRunBatch_CheckpointSkips checks that a restarted batch outputs the recorded
+// results without searching: the recorded position is not in the binlogs, and the
+// recorded failure is reported again rather than retried
+func TestRunBatch_CheckpointSkips(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := testutil.WriteFixtures(dir); err != nil { // FixtureUUID:1-300 over 3 files
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+	status = io.Discard
+
+	found := testutil.FixtureUUID + ":50"
+	failed := testutil.FixtureUUID + ":60"
+	path := filepath.Join(t.TempDir(), "gtids.checkpoint")
+	cp, err := openCheckpoint(path)
+	if err != nil {
+		t.Fatalf("openCheckpoint() error = %v", err)
+	}
+	recorded := &models.GTIDPosition{BinlogFile: "recorded-bin.000042", GTID: found, ServerUUID: testutil.FixtureUUID, GNO: 50}
+	if err := cp.Record(found, recorded); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+	if err := cp.RecordFailure(failed, errors.New("recorded failure")); err != nil {
+		t.Fatalf("RecordFailure() error = %v", err)
+	}
+	cp.Close()
+
+	output := filepath.Join(t.TempDir(), "positions.json")
+	cfg := &models.Config{
+		BinlogDir:       dir,
+		FilePattern:     "mysql-bin.*",
+		Parallel:        1,
+		TargetGTID:      found + ";" + failed + ";" + testutil.FixtureUUID + ":250",
+		OutputFormat:    models.FormatJSON,
+		OutputFile:      output,
+		Checkpoint:      path,
+		ContinueOnError: true,
+	}
+	if _, err := runBatch(cfg, time.Now()); err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	var result models.SearchResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatalf("Invalid JSON output: %v", err)
+	}
+	if len(result.Positions) != 2 || result.Positions[0].BinlogFile != "recorded-bin.000042" || result.Positions[1].GNO != 250 {
+		t.Errorf("Expected the recorded position then GNO 250, got %s", data)
+	}
+	if len(result.Failures) != 1 || result.Failures[0].Error != "recorded failure" {
+		t.Errorf("Expected the recorded failure, got %s", data)
+	}
+}
@@ -0,0 +1,39 @@
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+)
+
+// CanalExporter writes the resume position of each GTID position as the JSON of a
+// go-mysql mysql.Position ({"Name": "mysql-bin.000004", "Pos": 1025445319}), one per line,
+// ready to decode and pass to canal.RunFrom
+type CanalExporter struct{}
+
+// NewCanalExporter creates a new canal exporter
+func NewCanalExporter() *CanalExporter {
+	return &CanalExporter{}
+}
+
+// Export writes the mysql.Position of every position to output (stdout if empty)
+func (e *CanalExporter) Export(positions []*models.GTIDPosition, output string) error {
+	file, err := createOutput(output, "canal")
+	if err != nil {
+		return err
+	}
+	return closeOutput(file, e.ExportTo(file, positions))
+}
+
+// ExportTo writes the mysql.Position of every position to w
+func (e *CanalExporter) ExportTo(w io.Writer, positions []*models.GTIDPosition) error {
+	encoder := json.NewEncoder(w)
+	for _, pos := range positions {
+		if err := encoder.Encode(pos.ToMySQLPosition()); err != nil {
+			return fmt.Errorf("failed to encode position: %w", err)
+		}
+	}
+	return nil
+}
@@ -2,15 +2,22 @@ package exporter
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+	"text/tabwriter"
 	"time"
 
+	"github.com/quyetmv/mysql-gtid-position/internal/emoji"
 	"github.com/quyetmv/mysql-gtid-position/models"
 )
 
 // ConsoleExporter exports results to console with formatting
 type ConsoleExporter struct {
-	UseColor bool
+	UseColor   bool
+	TimeFormat string // Layout of timestamps, see models.FormatTimestamp (RFC3339 if empty)
 }
 
 // NewConsoleExporter creates a new console exporter
@@ -22,56 +29,217 @@ func NewConsoleExporter() *ConsoleExporter {
 
 // Export prints GTID positions to console
 func (e *ConsoleExporter) Export(positions []*models.GTIDPosition, output string) error {
+	return e.ExportTo(os.Stdout, positions)
+}
+
+// ExportTo prints GTID positions to w
+func (e *ConsoleExporter) ExportTo(w io.Writer, positions []*models.GTIDPosition) error {
 	if len(positions) == 0 {
-		fmt.Println("❌ No GTID positions found")
+		fmt.Fprintln(w, emoji.NotFound, "No GTID positions found")
 		return nil
 	}
 
-	fmt.Println(strings.Repeat("=", 70))
-	fmt.Printf("📊 Found %d GTID Position(s)\n", len(positions))
-	fmt.Println(strings.Repeat("=", 70))
+	fmt.Fprintln(w, strings.Repeat("=", 70))
+	fmt.Fprintf(w, "%s Found %d GTID Position(s)\n", emoji.Info, len(positions))
+	fmt.Fprintln(w, strings.Repeat("=", 70))
 
 	for i, pos := range positions {
-		fmt.Printf("\n[%d] GTID Position:\n", i+1)
-		fmt.Println(strings.Repeat("-", 70))
-		fmt.Printf("  📄 Binlog File: %s\n", pos.BinlogFile)
-		fmt.Printf("  📍 Position:    %d\n", pos.Position)
-		fmt.Printf("  🆔 GTID:        %s\n", pos.GTID)
-		fmt.Printf("  🕐 Timestamp:   %s (%d)\n",
-			time.Unix(int64(pos.Timestamp), 0).Format(time.RFC3339),
+		fmt.Fprintf(w, "\n[%d] GTID Position:\n", i+1)
+		fmt.Fprintln(w, strings.Repeat("-", 70))
+		fmt.Fprintf(w, "  %s Binlog File:    %s\n", emoji.File, pos.BinlogFile)
+		fmt.Fprintf(w, "  %s Start Position: %d\n", emoji.Position, pos.Position)
+		fmt.Fprintf(w, "  %s GTID:           %s\n", emoji.GTID, pos.GTID)
+		fmt.Fprintf(w, "  %s Timestamp:      %s (%d)\n", emoji.Time,
+			models.FormatTimestamp(pos.Timestamp, e.TimeFormat),
 			pos.Timestamp)
 	}
 
-	fmt.Println(strings.Repeat("=", 70))
+	fmt.Fprintln(w, strings.Repeat("=", 70))
 	return nil
 }
 
 // ExportSingle prints a single GTID position (for backward compatibility)
 func (e *ConsoleExporter) ExportSingle(pos *models.GTIDPosition) error {
+	return e.ExportSingleTo(os.Stdout, pos)
+}
+
+// ExportSingleTo prints a single GTID position to w
+func (e *ConsoleExporter) ExportSingleTo(w io.Writer, pos *models.GTIDPosition) error {
 	if pos == nil {
-		fmt.Println("❌ GTID not found")
+		fmt.Fprintln(w, emoji.NotFound, "GTID not found")
 		return nil
 	}
 
-	fmt.Println(strings.Repeat("-", 60))
-	fmt.Println("✅ Found GTID")
-	fmt.Printf("📄 Binlog File: %s\n", pos.BinlogFile)
-	fmt.Printf("🆔 GTID: %s\n\n", pos.GTID)
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+	fmt.Fprintln(w, emoji.OK, "Found GTID")
+	fmt.Fprintf(w, "%s Binlog File: %s\n", emoji.File, pos.BinlogFile)
+	fmt.Fprintf(w, "%s GTID: %s\n\n", emoji.GTID, pos.GTID)
 	
-	fmt.Printf("📍 Start Position (GTID):     %d\n", pos.Position)
-	fmt.Printf("📍 Commit Position (Xid):     %d\n", pos.CommitPosition)
-	fmt.Printf("📍 Resume Position:           %d   ✅\n", pos.ResumePosition)
+	fmt.Fprintf(w, "%s Start Position (GTID):     %d\n", emoji.Position, pos.Position)
+	fmt.Fprintf(w, "%s Commit Position (Xid):     %d\n", emoji.Position, pos.CommitPosition)
+	if pos.ResumeFile != "" {
+		fmt.Fprintf(w, "%s Resume Position:           %s:%d   %s\n", emoji.Position, filepath.Base(pos.ResumeFile), pos.ResumePosition, emoji.OK)
+	} else {
+		fmt.Fprintf(w, "%s Resume Position:           %d   %s\n", emoji.Position, pos.ResumePosition, emoji.OK)
+	}
+	if pos.PrevGTID != "" {
+		fmt.Fprintf(w, "%s Previous GTID:             %s\n", emoji.Previous, pos.PrevGTID)
+	}
 	if pos.NextGTID != "" {
-		fmt.Printf("🔄 Next GTID:                 %s\n", pos.NextGTID)
+		fmt.Fprintf(w, "%s Next GTID:                 %s\n", emoji.Next, pos.NextGTID)
+	}
+	if pos.ExecutedSet != "" {
+		fmt.Fprintf(w, "%s Executed GTID Set:         %s\n", emoji.GTIDSet, pos.ExecutedSet)
+	}
+	if pos.MatchInterval[1] > 0 {
+		fmt.Fprintf(w, "%s Match Interval:            %d-%d\n", emoji.Target, pos.MatchInterval[0], pos.MatchInterval[1])
 	}
-	fmt.Println()
+	if pos.SequenceNumber > 0 {
+		fmt.Fprintf(w, "%s Group Commit:              last_committed=%d sequence_number=%d\n", emoji.GroupCommit, pos.LastCommitted, pos.SequenceNumber)
+	}
+	fmt.Fprintln(w)
 	
-	fmt.Printf("🕐 Timestamp: %s\n",
-		time.Unix(int64(pos.Timestamp), 0).Format(time.RFC3339))
+	fmt.Fprintf(w, "%s Timestamp: %s\n", emoji.Time,
+		models.FormatTimestamp(pos.Timestamp, e.TimeFormat))
+	if pos.AgeSeconds > 0 {
+		fmt.Fprintf(w, "%s Age: %s ago\n", emoji.Time, formatAge(pos.AgeSeconds))
+	}
 	if pos.Database != "" {
-		fmt.Printf("💾 Database: %s\n", pos.Database)
+		fmt.Fprintf(w, "%s Database: %s\n", emoji.Database, pos.Database)
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+
+	return nil
+}
+
+// ExportRange prints the position range to replay for a GTID set
+func (e *ConsoleExporter) ExportRange(positionRange *models.PositionRange) error {
+	return e.ExportRangeTo(os.Stdout, positionRange)
+}
+
+// ExportRangeTo prints the position range to replay for a GTID set to w
+func (e *ConsoleExporter) ExportRangeTo(w io.Writer, positionRange *models.PositionRange) error {
+	if positionRange == nil {
+		fmt.Fprintln(w, emoji.NotFound, "GTID not found")
+		return nil
+	}
+
+	files := make([]string, len(positionRange.Files))
+	for i, file := range positionRange.Files {
+		files[i] = filepath.Base(file)
+	}
+
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+	fmt.Fprintln(w, emoji.OK, "Found GTID range")
+	fmt.Fprintf(w, "%s First GTID: %s\n", emoji.GTID, positionRange.FirstGTID)
+	fmt.Fprintf(w, "%s Last GTID:  %s\n", emoji.GTID, positionRange.LastGTID)
+	fmt.Fprintf(w, "%s Files:      %s\n\n", emoji.File, strings.Join(files, ", "))
+	fmt.Fprintln(w, emoji.Replay, formatReplay(positionRange))
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+
+	return nil
+}
+
+// ExportStats prints transaction size statistics, overall then per server UUID
+func (e *ConsoleExporter) ExportStats(stats *models.TransactionStats) error {
+	return e.ExportStatsTo(os.Stdout, stats)
+}
+
+// ExportStatsTo prints transaction size statistics to w
+func (e *ConsoleExporter) ExportStatsTo(w io.Writer, stats *models.TransactionStats) error {
+	if stats == nil || stats.Overall.Count == 0 {
+		fmt.Fprintln(w, emoji.NotFound, "No transactions of the GTID set found")
+		return nil
+	}
+
+	uuids := make([]string, 0, len(stats.ByUUID))
+	for uuid := range stats.ByUUID {
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+	fmt.Fprintln(w, emoji.Sizes, "Transaction sizes (bytes)")
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "SERVER UUID\tCOUNT\tMIN\tAVG\tMAX\tP95\tLARGEST GTID")
+	printSizeStats(table, "overall", stats.Overall)
+	for _, uuid := range uuids {
+		printSizeStats(table, uuid, stats.ByUUID[uuid])
+	}
+	table.Flush()
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+
+	return nil
+}
+
+// printSizeStats prints one row of the transaction size table
+func printSizeStats(w io.Writer, name string, size models.SizeStats) {
+	fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d\t%s\n", name, size.Count, size.Min, size.Avg, size.Max, size.P95, size.LargestGTID)
+}
+
+// ExportDatabases prints the databases written by the binlogs with their transaction counts
+func (e *ConsoleExporter) ExportDatabases(databases []models.DatabaseCount) error {
+	return e.ExportDatabasesTo(os.Stdout, databases)
+}
+
+// ExportDatabasesTo prints the databases written by the binlogs to w
+func (e *ConsoleExporter) ExportDatabasesTo(w io.Writer, databases []models.DatabaseCount) error {
+	if len(databases) == 0 {
+		fmt.Fprintln(w, emoji.NotFound, "No transaction writing a database found")
+		return nil
+	}
+
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+	fmt.Fprintf(w, "%s %d databases written\n", emoji.Databases, len(databases))
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "DATABASE\tTRANSACTIONS")
+	for _, database := range databases {
+		fmt.Fprintf(table, "%s\t%d\n", database.Database, database.Transactions)
 	}
-	fmt.Println(strings.Repeat("-", 60))
+	table.Flush()
+	fmt.Fprintln(w, strings.Repeat("-", 60))
 
 	return nil
 }
+
+// ExportDuplicates prints the GTIDs committed more than once with their copies
+func (e *ConsoleExporter) ExportDuplicates(duplicates []*models.DuplicateGTID) error {
+	return e.ExportDuplicatesTo(os.Stdout, duplicates)
+}
+
+// ExportDuplicatesTo prints the GTIDs committed more than once to w
+func (e *ConsoleExporter) ExportDuplicatesTo(w io.Writer, duplicates []*models.DuplicateGTID) error {
+	if len(duplicates) == 0 {
+		fmt.Fprintln(w, emoji.OK, "No GTID is committed more than once")
+		return nil
+	}
+
+	fmt.Fprintf(w, "%s %d GTIDs committed more than once (errant transactions):\n", emoji.Warning, len(duplicates))
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+	for _, duplicate := range duplicates {
+		fmt.Fprintf(w, "%s (%d copies)\n", duplicate.GTID, len(duplicate.Occurrences))
+		for _, occurrence := range duplicate.Occurrences {
+			fmt.Fprintf(w, "  %s:%d-%d at %s\n", filepath.Base(occurrence.BinlogFile), occurrence.Position,
+				occurrence.CommitPosition, models.FormatTimestamp(occurrence.Timestamp, e.TimeFormat))
+		}
+	}
+	fmt.Fprintln(w, strings.Repeat("-", 60))
+
+	return nil
+}
+
+// formatReplay formats a range as "Replay: file:startPos → file:endPos"
+func formatReplay(positionRange *models.PositionRange) string {
+	return fmt.Sprintf("Replay: %s:%d %s %s:%d",
+		filepath.Base(positionRange.StartFile), positionRange.StartPosition, emoji.Arrow,
+		filepath.Base(positionRange.EndFile), positionRange.EndPosition)
+}
+
+// formatAge formats an age in seconds as "3h12m", or "45s" under a minute
+func formatAge(seconds int64) string {
+	age := time.Duration(seconds) * time.Second
+	if age < time.Minute {
+		return age.String()
+	}
+	return strings.TrimSuffix(age.Truncate(time.Minute).String(), "0s")
+}
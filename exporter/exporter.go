@@ -1,10 +1,9 @@
 package exporter
 
 import (
-	"encoding/csv"
 	"encoding/json"
 	"fmt"
-	"os"
+	"io"
 
 	"github.com/quyetmv/mysql-gtid-position/models"
 )
@@ -18,63 +17,110 @@ type Exporter interface {
 type CSVExporter struct {
 	IncludeHeader bool
 	Delimiter     rune
+	Fields        []string // Project only these JSON fields (see ParseFields), default columns if empty
+	FlushEvery    int      // Flush buffered rows to the output every N rows (0 = only at the end)
+	TimeFormat    string   // Layout of timestamp_readable, see models.FormatTimestamp (RFC3339 if empty)
 }
 
+// DefaultFlushEvery is the default CSVExporter.FlushEvery
+const DefaultFlushEvery = 100
+
 // NewCSVExporter creates a new CSV exporter
 func NewCSVExporter() *CSVExporter {
 	return &CSVExporter{
 		IncludeHeader: true,
 		Delimiter:     ',',
+		FlushEvery:    DefaultFlushEvery,
 	}
 }
 
 // Export writes GTID positions to CSV file
 func (e *CSVExporter) Export(positions []*models.GTIDPosition, output string) error {
-	var file *os.File
-	var err error
-
-	if output == "" || output == "-" {
-		file = os.Stdout
-	} else {
-		file, err = os.Create(output)
-		if err != nil {
-			return fmt.Errorf("failed to create CSV file: %w", err)
-		}
-		defer file.Close()
+	stream, err := e.Stream(output)
+	if err != nil {
+		return err
 	}
+	return exportAll(stream, positions)
+}
 
-	writer := csv.NewWriter(file)
-	writer.Comma = e.Delimiter
-	defer writer.Flush()
+// ExportTo writes GTID positions as CSV to w
+func (e *CSVExporter) ExportTo(w io.Writer, positions []*models.GTIDPosition) error {
+	stream, err := e.StreamTo(w)
+	if err != nil {
+		return err
+	}
+	return exportAll(stream, positions)
+}
 
-	// Write header
-	if e.IncludeHeader {
-		header := []string{"binlog_file", "position", "gtid", "timestamp", "timestamp_readable"}
-		if err := writer.Write(header); err != nil {
-			return fmt.Errorf("failed to write CSV header: %w", err)
+// exportAll writes positions to stream and closes it
+func exportAll(stream StreamWriter, positions []*models.GTIDPosition) error {
+	for _, pos := range positions {
+		if err := stream.Write(pos); err != nil {
+			stream.Close()
+			return err
 		}
 	}
 
-	// Write data rows
-	for _, pos := range positions {
-		row := []string{
+	return stream.Close()
+}
+
+// header returns the CSV header: the projected fields, or the default columns
+func (e *CSVExporter) header() []string {
+	if len(e.Fields) > 0 {
+		return e.Fields
+	}
+	return []string{"binlog_file", "start_position", "gtid", "timestamp", "timestamp_readable"}
+}
+
+// row returns the CSV row of a position, matching header
+func (e *CSVExporter) row(pos *models.GTIDPosition) ([]string, error) {
+	if len(e.Fields) == 0 {
+		return []string{
 			pos.BinlogFile,
 			fmt.Sprintf("%d", pos.Position),
 			pos.GTID,
 			fmt.Sprintf("%d", pos.Timestamp),
-			pos.TimestampReadable(),
-		}
-		if err := writer.Write(row); err != nil {
-			return fmt.Errorf("failed to write CSV row: %w", err)
+			models.FormatTimestamp(pos.Timestamp, e.TimeFormat),
+		}, nil
+	}
+
+	// One column per requested field, named after its JSON tag
+	projected, err := projectPosition(pos, e.Fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to project fields: %w", err)
+	}
+
+	row := make([]string, len(e.Fields))
+	for i, name := range e.Fields {
+		if value, ok := projected[name]; ok {
+			row[i] = fmt.Sprint(value)
 		}
 	}
+	return row, nil
+}
 
-	return nil
+// JSONSchemaVersion is the version of the JSON output. It is bumped on every
+// breaking change to the envelope or GTIDPosition fields; adding fields is not breaking.
+const JSONSchemaVersion = "1"
+
+// jsonEnvelope is the top-level JSON output object
+type jsonEnvelope struct {
+	SchemaVersion string                 `json:"schema_version"`
+	Total         int                    `json:"total"`
+	TotalFiles    int                    `json:"total_files"`
+	ScannedFiles  int                    `json:"scanned_files"`
+	DurationMs    int64                  `json:"duration_ms"`
+	UnseenUUIDs   []string               `json:"unseen_uuids,omitempty"`
+	ServerVersion string                 `json:"server_version,omitempty"`
+	Failures      []*models.EntryFailure `json:"failures,omitempty"`
+	Positions     interface{}            `json:"positions"` // []*models.GTIDPosition, or projected maps
 }
 
 // JSONExporter exports results to JSON format
 type JSONExporter struct {
 	PrettyPrint bool
+	Fields      []string // Project only these fields (see ParseFields), all fields if empty
+	Bare        bool     // Write a bare array of the positions, without the envelope and scan metadata
 }
 
 // NewJSONExporter creates a new JSON exporter
@@ -84,35 +130,185 @@ func NewJSONExporter(prettyPrint bool) *JSONExporter {
 	}
 }
 
-// Export writes GTID positions to JSON file
+// Export writes GTID positions to JSON file, without scan metadata
 func (e *JSONExporter) Export(positions []*models.GTIDPosition, output string) error {
-	var file *os.File
-	var err error
-
-	if output == "" || output == "-" {
-		file = os.Stdout
-	} else {
-		file, err = os.Create(output)
-		if err != nil {
-			return fmt.Errorf("failed to create JSON file: %w", err)
+	return e.ExportResult(&models.SearchResult{Positions: positions}, output)
+}
+
+// ExportTo writes GTID positions as JSON to w, without scan metadata
+func (e *JSONExporter) ExportTo(w io.Writer, positions []*models.GTIDPosition) error {
+	return e.ExportResultTo(w, &models.SearchResult{Positions: positions})
+}
+
+// ExportResult writes a search result, positions and scan metadata, to JSON file.
+// With Bare only the positions array is written.
+func (e *JSONExporter) ExportResult(search *models.SearchResult, output string) error {
+	file, err := createOutput(output, "JSON")
+	if err != nil {
+		return err
+	}
+	return closeOutput(file, e.ExportResultTo(file, search))
+}
+
+// ExportResultTo writes a search result, positions and scan metadata, as JSON to w
+func (e *JSONExporter) ExportResultTo(w io.Writer, search *models.SearchResult) error {
+	encoder := json.NewEncoder(w)
+	if e.PrettyPrint {
+		encoder.SetIndent("", "  ")
+	}
+
+	// Wrap in versioned result object
+	result := jsonEnvelope{
+		SchemaVersion: JSONSchemaVersion,
+		Total:         len(search.Positions),
+		TotalFiles:    search.TotalFiles,
+		ScannedFiles:  search.ScannedFiles,
+		DurationMs:    search.Duration.Milliseconds(),
+		UnseenUUIDs:   search.UnseenUUIDs,
+		ServerVersion: search.ServerVersion,
+		Failures:      search.Failures,
+		Positions:     search.Positions,
+	}
+
+	if len(e.Fields) > 0 {
+		projected := make([]map[string]interface{}, 0, len(search.Positions))
+		for _, pos := range search.Positions {
+			p, err := projectPosition(pos, e.Fields)
+			if err != nil {
+				return fmt.Errorf("failed to project fields: %w", err)
+			}
+			projected = append(projected, p)
 		}
-		defer file.Close()
+		result.Positions = projected
 	}
 
-	encoder := json.NewEncoder(file)
+	var value interface{} = result
+	if e.Bare {
+		value = result.Positions
+		if search.Positions == nil {
+			value = []*models.GTIDPosition{} // An empty array, not null
+		}
+	}
+
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+
+	return nil
+}
+
+// ExportRange writes a position range (see models.PositionRange) to JSON file
+func (e *JSONExporter) ExportRange(positionRange *models.PositionRange, output string) error {
+	file, err := createOutput(output, "JSON")
+	if err != nil {
+		return err
+	}
+	return closeOutput(file, e.ExportRangeTo(file, positionRange))
+}
+
+// ExportRangeTo writes a position range as JSON to w
+func (e *JSONExporter) ExportRangeTo(w io.Writer, positionRange *models.PositionRange) error {
+	encoder := json.NewEncoder(w)
+	if e.PrettyPrint {
+		encoder.SetIndent("", "  ")
+	}
+
+	result := struct {
+		SchemaVersion string                `json:"schema_version"`
+		Range         *models.PositionRange `json:"range"`
+	}{JSONSchemaVersion, positionRange}
+
+	if err := encoder.Encode(result); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return nil
+}
+
+// ExportDuplicates writes the GTIDs committed more than once (see models.DuplicateGTID) to JSON file
+func (e *JSONExporter) ExportDuplicates(duplicates []*models.DuplicateGTID, output string) error {
+	file, err := createOutput(output, "JSON")
+	if err != nil {
+		return err
+	}
+	return closeOutput(file, e.ExportDuplicatesTo(file, duplicates))
+}
+
+// ExportDuplicatesTo writes the GTIDs committed more than once as JSON to w
+func (e *JSONExporter) ExportDuplicatesTo(w io.Writer, duplicates []*models.DuplicateGTID) error {
+	encoder := json.NewEncoder(w)
 	if e.PrettyPrint {
 		encoder.SetIndent("", "  ")
 	}
 
-	// Wrap in result object
-	result := map[string]interface{}{
-		"total":     len(positions),
-		"positions": positions,
+	if duplicates == nil {
+		duplicates = []*models.DuplicateGTID{} // An empty list, not null
 	}
+	result := struct {
+		SchemaVersion string                  `json:"schema_version"`
+		Total         int                     `json:"total"`
+		Duplicates    []*models.DuplicateGTID `json:"duplicates"`
+	}{JSONSchemaVersion, len(duplicates), duplicates}
 
 	if err := encoder.Encode(result); err != nil {
 		return fmt.Errorf("failed to encode JSON: %w", err)
 	}
+	return nil
+}
 
+// ExportDatabases writes the databases written by the binlogs (see models.DatabaseCount) to JSON file
+func (e *JSONExporter) ExportDatabases(databases []models.DatabaseCount, output string) error {
+	file, err := createOutput(output, "JSON")
+	if err != nil {
+		return err
+	}
+	return closeOutput(file, e.ExportDatabasesTo(file, databases))
+}
+
+// ExportDatabasesTo writes the databases written by the binlogs as JSON to w
+func (e *JSONExporter) ExportDatabasesTo(w io.Writer, databases []models.DatabaseCount) error {
+	encoder := json.NewEncoder(w)
+	if e.PrettyPrint {
+		encoder.SetIndent("", "  ")
+	}
+
+	if databases == nil {
+		databases = []models.DatabaseCount{} // An empty list, not null
+	}
+	result := struct {
+		SchemaVersion string                 `json:"schema_version"`
+		Total         int                    `json:"total"`
+		Databases     []models.DatabaseCount `json:"databases"`
+	}{JSONSchemaVersion, len(databases), databases}
+
+	if err := encoder.Encode(result); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return nil
+}
+
+// ExportStats writes transaction size statistics (see models.TransactionStats) to JSON file
+func (e *JSONExporter) ExportStats(stats *models.TransactionStats, output string) error {
+	file, err := createOutput(output, "JSON")
+	if err != nil {
+		return err
+	}
+	return closeOutput(file, e.ExportStatsTo(file, stats))
+}
+
+// ExportStatsTo writes transaction size statistics as JSON to w
+func (e *JSONExporter) ExportStatsTo(w io.Writer, stats *models.TransactionStats) error {
+	encoder := json.NewEncoder(w)
+	if e.PrettyPrint {
+		encoder.SetIndent("", "  ")
+	}
+
+	result := struct {
+		SchemaVersion string                   `json:"schema_version"`
+		Stats         *models.TransactionStats `json:"stats"`
+	}{JSONSchemaVersion, stats}
+
+	if err := encoder.Encode(result); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
 	return nil
 }
@@ -1,15 +1,21 @@
 package exporter
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/csv"
 	"encoding/json"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/quyetmv/mysql-gtid-position/internal/emoji"
 	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
 )
 
 func createTestPositions() []*models.GTIDPosition {
@@ -105,7 +111,7 @@ func TestCSVExporter_Export(t *testing.T) {
 				// Verify header if included
 				if tt.includeHeader && len(records) > 0 {
 					header := records[0]
-					expectedHeader := []string{"binlog_file", "position", "gtid", "timestamp", "timestamp_readable"}
+					expectedHeader := []string{"binlog_file", "start_position", "gtid", "timestamp", "timestamp_readable"}
 					for i, h := range header {
 						if h != expectedHeader[i] {
 							t.Errorf("Header[%d]: got %s, want %s", i, h, expectedHeader[i])
@@ -141,6 +147,40 @@ func TestCSVExporter_CustomDelimiter(t *testing.T) {
 	}
 }
 
+func TestCSVExporter_TimeFormat(t *testing.T) {
+	tests := []struct {
+		name       string
+		timeFormat string
+		want       string
+	}{
+		{name: "default RFC3339", want: time.Unix(1703750400, 0).Format(time.RFC3339)},
+		{name: "unix", timeFormat: models.TimeFormatUnix, want: "1703750400"},
+		{name: "unix millis", timeFormat: models.TimeFormatUnixMilli, want: "1703750400000"},
+		{name: "Go layout", timeFormat: "2006-01-02", want: time.Unix(1703750400, 0).Format("2006-01-02")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputFile := filepath.Join(t.TempDir(), "time.csv")
+			exporter := NewCSVExporter()
+			exporter.TimeFormat = tt.timeFormat
+
+			if err := exporter.Export(createTestPositions()[:1], outputFile); err != nil {
+				t.Fatalf("CSVExporter.Export() error = %v", err)
+			}
+
+			content, err := os.ReadFile(outputFile)
+			if err != nil {
+				t.Fatalf("Failed to read file: %v", err)
+			}
+			lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+			if got := lines[1][strings.LastIndex(lines[1], ",")+1:]; got != tt.want {
+				t.Errorf("Expected timestamp_readable %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
 func TestJSONExporter_Export(t *testing.T) {
 	tmpDir := t.TempDir()
 	positions := createTestPositions()
@@ -207,11 +247,62 @@ func TestJSONExporter_Export(t *testing.T) {
 				if _, ok := result["positions"]; !ok {
 					t.Error("JSON missing 'positions' field")
 				}
+				if result["schema_version"] != JSONSchemaVersion {
+					t.Errorf("Expected schema_version %q, got %v", JSONSchemaVersion, result["schema_version"])
+				}
 			}
 		})
 	}
 }
 
+func TestJSONExporter_ExportResult(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "result.json")
+
+	search := &models.SearchResult{
+		Positions:     createTestPositions(),
+		TotalFiles:    12,
+		ScannedFiles:  5,
+		Duration:      1500 * time.Millisecond,
+		ServerVersion: "8.0.36",
+		Failures:      []*models.EntryFailure{{Entry: 2, GTID: "bad", Error: "UUID not found"}},
+	}
+
+	if err := NewJSONExporter(false).ExportResult(search, outputFile); err != nil {
+		t.Fatalf("JSONExporter.ExportResult() error = %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	var result struct {
+		SchemaVersion string                 `json:"schema_version"`
+		Total         int                    `json:"total"`
+		TotalFiles    int                    `json:"total_files"`
+		ScannedFiles  int                    `json:"scanned_files"`
+		DurationMs    int64                  `json:"duration_ms"`
+		ServerVersion string                 `json:"server_version"`
+		Failures      []*models.EntryFailure `json:"failures"`
+	}
+	if err := json.Unmarshal(content, &result); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+
+	if result.ServerVersion != "8.0.36" {
+		t.Errorf("Expected server_version 8.0.36, got %q", result.ServerVersion)
+	}
+	if len(result.Failures) != 1 || *result.Failures[0] != *search.Failures[0] {
+		t.Errorf("Expected failures %+v, got %+v", search.Failures, result.Failures)
+	}
+	if result.SchemaVersion != JSONSchemaVersion {
+		t.Errorf("Expected schema_version %q, got %q", JSONSchemaVersion, result.SchemaVersion)
+	}
+	if result.Total != len(search.Positions) || result.TotalFiles != 12 || result.ScannedFiles != 5 || result.DurationMs != 1500 {
+		t.Errorf("Unexpected scan metadata: %+v", result)
+	}
+}
+
 func TestConsoleExporter_Export(t *testing.T) {
 	positions := createTestPositions()
 	exporter := NewConsoleExporter()
@@ -245,3 +336,521 @@ func TestConsoleExporter_ExportSingle(t *testing.T) {
 		t.Errorf("ConsoleExporter.ExportSingle() with nil error = %v", err)
 	}
 }
+
+func TestParseFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		want    []string
+		wantErr bool
+	}{
+		{name: "valid fields", spec: "gtid,resume_position,binlog_file", want: []string{"gtid", "resume_position", "binlog_file"}},
+		{name: "spaces trimmed", spec: " gtid , gno ", want: []string{"gtid", "gno"}},
+		{name: "unknown field", spec: "gtid,position", wantErr: true},
+		{name: "empty", spec: " , ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFields(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFields() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ParseFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONExporter_Fields(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "fields.json")
+
+	exporter := NewJSONExporter(false)
+	exporter.Fields = []string{"gtid", "start_position", "next_gtid"}
+	if err := exporter.Export(createTestPositions(), outputFile); err != nil {
+		t.Fatalf("JSONExporter.Export() error = %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	var result struct {
+		Positions []map[string]interface{} `json:"positions"`
+	}
+	if err := json.Unmarshal(content, &result); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+
+	if len(result.Positions) != 2 {
+		t.Fatalf("Expected 2 positions, got %d", len(result.Positions))
+	}
+	// Empty omitempty fields (next_gtid) stay absent
+	if len(result.Positions[0]) != 2 {
+		t.Errorf("Expected only gtid and start_position, got %v", result.Positions[0])
+	}
+	if result.Positions[0]["start_position"] != float64(12345) {
+		t.Errorf("Expected start_position 12345, got %v", result.Positions[0]["start_position"])
+	}
+}
+
+func TestCSVExporter_Fields(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "fields.csv")
+
+	exporter := NewCSVExporter()
+	exporter.Fields = []string{"gtid", "start_position", "next_gtid"}
+	if err := exporter.Export(createTestPositions(), outputFile); err != nil {
+		t.Fatalf("CSVExporter.Export() error = %v", err)
+	}
+
+	file, err := os.Open(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+
+	want := [][]string{
+		{"gtid", "start_position", "next_gtid"},
+		{"3E11FA47-71CA-11E1-9E33-C80AA9429562:23", "12345", ""},
+		{"3E11FA47-71CA-11E1-9E33-C80AA9429562:24", "67890", ""},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("Expected %d records, got %d", len(want), len(records))
+	}
+	for i := range want {
+		if strings.Join(records[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("Record %d = %v, want %v", i, records[i], want[i])
+		}
+	}
+}
+
+func TestCSVExporter_StreamFlush(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "stream.csv")
+
+	exporter := NewCSVExporter()
+	exporter.FlushEvery = 1
+	stream, err := exporter.Stream(outputFile)
+	if err != nil {
+		t.Fatalf("CSVExporter.Stream() error = %v", err)
+	}
+
+	positions := createTestPositions()
+	if err := stream.Write(positions[0]); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	// The row must be on disk before Close, so an interrupted run keeps it
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+	if !strings.Contains(string(content), positions[0].GTID) {
+		t.Errorf("Expected first row flushed before Close, got %q", content)
+	}
+
+	if err := stream.Write(positions[1]); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	content, _ = os.ReadFile(outputFile)
+	if lines := strings.Split(strings.TrimSpace(string(content)), "\n"); len(lines) != 3 {
+		t.Errorf("Expected header and 2 rows, got %d lines", len(lines))
+	}
+}
+
+func TestJSONExporter_Stream(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "stream.ndjson")
+
+	exporter := NewJSONExporter(true) // PrettyPrint is ignored, one object per line
+	exporter.Fields = []string{"gtid", "start_position"}
+	stream, err := exporter.Stream(outputFile)
+	if err != nil {
+		t.Fatalf("JSONExporter.Stream() error = %v", err)
+	}
+	for _, pos := range createTestPositions() {
+		if err := stream.Write(pos); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %q", len(lines), content)
+	}
+	for i, line := range lines {
+		var pos map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &pos); err != nil {
+			t.Fatalf("Line %d is not a JSON object: %v", i, err)
+		}
+		if len(pos) != 2 {
+			t.Errorf("Line %d: expected only gtid and start_position, got %v", i, pos)
+		}
+	}
+}
+
+func TestJSONExporter_Bare(t *testing.T) {
+	positions := createTestPositions()
+
+	// Exported at once and streamed, with and without positions, the output is a bare array
+	tests := []struct {
+		name      string
+		positions []*models.GTIDPosition
+		stream    bool
+	}{
+		{name: "export", positions: positions},
+		{name: "export empty"},
+		{name: "stream", positions: positions, stream: true},
+		{name: "stream empty", stream: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter := NewJSONExporter(true)
+			exporter.Bare = true
+
+			var buf bytes.Buffer
+			if tt.stream {
+				stream := exporter.StreamTo(&buf)
+				if err := exportAll(stream, tt.positions); err != nil {
+					t.Fatalf("Stream error = %v", err)
+				}
+			} else if err := exporter.ExportResultTo(&buf, &models.SearchResult{Positions: tt.positions, TotalFiles: 3}); err != nil {
+				t.Fatalf("ExportResultTo() error = %v", err)
+			}
+
+			var decoded []models.GTIDPosition
+			if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+				t.Fatalf("Output is not a JSON array: %v\n%s", err, buf.String())
+			}
+			if len(decoded) != len(tt.positions) {
+				t.Fatalf("Expected %d positions, got %d", len(tt.positions), len(decoded))
+			}
+			for i, pos := range decoded {
+				if pos.GTID != tt.positions[i].GTID {
+					t.Errorf("Position %d: expected %s, got %s", i, tt.positions[i].GTID, pos.GTID)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		seconds int64
+		want    string
+	}{
+		{45, "45s"},
+		{60, "1m"},
+		{3*3600 + 12*60 + 30, "3h12m"},
+		{50 * 3600, "50h0m"},
+	}
+
+	for _, tt := range tests {
+		if got := formatAge(tt.seconds); got != tt.want {
+			t.Errorf("formatAge(%d) = %q, want %q", tt.seconds, got, tt.want)
+		}
+	}
+}
+
+func TestFormatReplay(t *testing.T) {
+	positionRange := &models.PositionRange{
+		StartFile:     "/var/lib/mysql/mysql-bin.000002",
+		StartPosition: 4900,
+		EndFile:       "/var/lib/mysql/mysql-bin.000003",
+		EndPosition:   5157,
+	}
+
+	want := "Replay: mysql-bin.000002:4900 " + emoji.Arrow.String() + " mysql-bin.000003:5157"
+	if got := formatReplay(positionRange); got != want {
+		t.Errorf("formatReplay() = %q, want %q", got, want)
+	}
+}
+
+func TestJSONExporter_ExportRange(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "range.json")
+
+	positionRange := &models.PositionRange{
+		StartFile:     "/var/lib/mysql/mysql-bin.000002",
+		StartPosition: 4900,
+		EndFile:       "/var/lib/mysql/mysql-bin.000003",
+		EndPosition:   5157,
+		Files:         []string{"/var/lib/mysql/mysql-bin.000002", "/var/lib/mysql/mysql-bin.000003"},
+	}
+	if err := NewJSONExporter(false).ExportRange(positionRange, outputFile); err != nil {
+		t.Fatalf("JSONExporter.ExportRange() error = %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	var result struct {
+		SchemaVersion string               `json:"schema_version"`
+		Range         models.PositionRange `json:"range"`
+	}
+	if err := json.Unmarshal(content, &result); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+
+	if result.SchemaVersion != JSONSchemaVersion {
+		t.Errorf("Expected schema_version %q, got %q", JSONSchemaVersion, result.SchemaVersion)
+	}
+	if result.Range.StartPosition != 4900 || result.Range.EndPosition != 5157 || len(result.Range.Files) != 2 {
+		t.Errorf("Unexpected range: %+v", result.Range)
+	}
+}
+
+func TestChangeReplicationSQL(t *testing.T) {
+	pos := &models.GTIDPosition{
+		BinlogFile:     "/data/log/mysql-bin.000004",
+		Position:       1025441563,
+		ResumePosition: 1025445319,
+	}
+
+	want := "CHANGE REPLICATION SOURCE TO SOURCE_LOG_FILE='mysql-bin.000004', SOURCE_LOG_POS=1025445319;"
+	if got := ChangeReplicationSQL(pos); got != want {
+		t.Errorf("ChangeReplicationSQL() = %q, want %q", got, want)
+	}
+}
+
+func TestCloneSQL(t *testing.T) {
+	pos := &models.GTIDPosition{
+		BinlogFile:     "/data/log/mysql-bin.000004",
+		GTID:           "3e11fa47-71ca-11e1-9e33-c80aa9429562:5795043",
+		ResumePosition: 1025445319,
+		ExecutedSet:    "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5795043",
+	}
+
+	var buf bytes.Buffer
+	if err := NewCloneSQLExporter().ExportTo(&buf, []*models.GTIDPosition{pos}); err != nil {
+		t.Fatalf("ExportTo() error = %v", err)
+	}
+
+	// GTID_PURGED can only be set once RESET MASTER emptied GTID_EXECUTED
+	var statements []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if line != "" && !strings.HasPrefix(line, "--") {
+			statements = append(statements, line)
+		}
+	}
+	want := []string{
+		"STOP REPLICA;",
+		"RESET MASTER;",
+		"SET @@GLOBAL.GTID_PURGED='3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5795043';",
+		ChangeReplicationSQL(pos),
+		"START REPLICA;",
+	}
+	if strings.Join(statements, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected statements:\n%s", strings.Join(statements, "\n"))
+	}
+}
+
+func TestTemplateExporter(t *testing.T) {
+	positions := []*models.GTIDPosition{
+		{BinlogFile: "/data/log/mysql-bin.000004", ResumePosition: 1025445319, GTID: "3e11fa47-71ca-11e1-9e33-c80aa9429562:5"},
+		{BinlogFile: "/data/log/mysql-bin.000005", ResumePosition: 4, GTID: "3e11fa47-71ca-11e1-9e33-c80aa9429562:6"},
+	}
+
+	exp, err := NewTemplateExporter("{{base .BinlogFile}} {{.ResumePosition}} {{.GTID}}")
+	if err != nil {
+		t.Fatalf("NewTemplateExporter() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if err := exp.ExportTo(&buf, positions); err != nil {
+		t.Fatalf("ExportTo() error = %v", err)
+	}
+	want := "mysql-bin.000004 1025445319 3e11fa47-71ca-11e1-9e33-c80aa9429562:5\n" +
+		"mysql-bin.000005 4 3e11fa47-71ca-11e1-9e33-c80aa9429562:6\n"
+	if buf.String() != want {
+		t.Errorf("ExportTo() = %q, want %q", buf.String(), want)
+	}
+
+	// Syntax errors and unknown fields are caught before any output
+	for _, text := range []string{"{{.GTID", "{{.NoSuchField}}"} {
+		if _, err := NewTemplateExporter(text); err == nil {
+			t.Errorf("NewTemplateExporter(%q) expected an error", text)
+		}
+	}
+}
+
+func TestCanalExporter(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "canal.json")
+	positions := createTestPositions()
+	positions[0].ResumePosition = 12400
+
+	if err := NewCanalExporter().Export(positions[:1], outputFile); err != nil {
+		t.Fatalf("CanalExporter.Export() error = %v", err)
+	}
+
+	content, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatalf("Failed to read file: %v", err)
+	}
+
+	// Decodes straight into what canal.RunFrom takes
+	var got mysql.Position
+	if err := json.Unmarshal(content, &got); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	if got.Name != "mysql-bin.000001" {
+		t.Errorf("Expected binlog base name mysql-bin.000001, got %s", got.Name)
+	}
+	if got.Pos != 12400 {
+		t.Errorf("Expected resume position 12400, got %d", got.Pos)
+	}
+}
+
+func TestExportTo(t *testing.T) {
+	positions := createTestPositions()
+
+	var buf bytes.Buffer
+	if err := NewCSVExporter().ExportTo(&buf, positions); err != nil {
+		t.Fatalf("CSVExporter.ExportTo() error = %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to parse CSV: %v", err)
+	}
+	if len(records) != len(positions)+1 || records[1][2] != positions[0].GTID {
+		t.Errorf("Unexpected CSV records: %v", records)
+	}
+
+	buf.Reset()
+	if err := NewJSONExporter(false).ExportTo(&buf, positions); err != nil {
+		t.Fatalf("JSONExporter.ExportTo() error = %v", err)
+	}
+	var result jsonEnvelope
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	if result.Total != len(positions) {
+		t.Errorf("Expected %d positions, got %d", len(positions), result.Total)
+	}
+
+	buf.Reset()
+	if err := NewConsoleExporter().ExportSingleTo(&buf, positions[0]); err != nil {
+		t.Fatalf("ConsoleExporter.ExportSingleTo() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), positions[0].GTID) {
+		t.Errorf("Console output misses the GTID: %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := NewCanalExporter().ExportTo(&buf, positions[:1]); err != nil {
+		t.Fatalf("CanalExporter.ExportTo() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `"Name":"mysql-bin.000001"`) {
+		t.Errorf("Unexpected canal output: %q", buf.String())
+	}
+}
+
+func TestGzipOutput(t *testing.T) {
+	dir := t.TempDir()
+	positions := createTestPositions()
+
+	readGzip := func(path string) []byte {
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", path, err)
+		}
+		defer file.Close()
+
+		reader, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatalf("%s is not gzip compressed: %v", path, err)
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatalf("Failed to decompress %s: %v", path, err)
+		}
+		return data
+	}
+
+	csvFile := filepath.Join(dir, "positions.csv.gz")
+	if err := NewCSVExporter().Export(positions, csvFile); err != nil {
+		t.Fatalf("CSV Export() error = %v", err)
+	}
+	records, err := csv.NewReader(bytes.NewReader(readGzip(csvFile))).ReadAll()
+	if err != nil {
+		t.Fatalf("Failed to read CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Errorf("Expected header and 2 rows, got %d records", len(records))
+	}
+
+	jsonFile := filepath.Join(dir, "result.json.gz")
+	if err := NewJSONExporter(false).ExportResult(&models.SearchResult{Positions: positions}, jsonFile); err != nil {
+		t.Fatalf("JSON ExportResult() error = %v", err)
+	}
+	var result struct {
+		Positions []*models.GTIDPosition `json:"positions"`
+	}
+	if err := json.Unmarshal(readGzip(jsonFile), &result); err != nil {
+		t.Fatalf("Failed to parse JSON: %v", err)
+	}
+	if len(result.Positions) != 2 {
+		t.Errorf("Expected 2 positions, got %d", len(result.Positions))
+	}
+
+	// NDJSON streams are flushed on Close
+	ndjsonFile := filepath.Join(dir, "positions.ndjson.gz")
+	stream, err := NewJSONExporter(false).Stream(ndjsonFile)
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if err := exportAll(stream, positions); err != nil {
+		t.Fatalf("Stream write error = %v", err)
+	}
+	if lines := strings.Count(string(readGzip(ndjsonFile)), "\n"); lines != 2 {
+		t.Errorf("Expected 2 NDJSON lines, got %d", lines)
+	}
+}
+
+// TestConsoleExporter_ExportRangeNoEmoji checks that -no-emoji leaves the range
+// output plain ASCII. emoji.Disable cannot be undone, so this test runs last
+func TestConsoleExporter_ExportRangeNoEmoji(t *testing.T) {
+	positionRange := &models.PositionRange{
+		FirstGTID:     "3E11FA47-71CA-11E1-9E33-C80AA9429562:1",
+		LastGTID:      "3E11FA47-71CA-11E1-9E33-C80AA9429562:5",
+		StartFile:     "/var/lib/mysql/mysql-bin.000002",
+		StartPosition: 4900,
+		EndFile:       "/var/lib/mysql/mysql-bin.000003",
+		EndPosition:   5157,
+		Files:         []string{"/var/lib/mysql/mysql-bin.000002", "/var/lib/mysql/mysql-bin.000003"},
+	}
+
+	emoji.Disable()
+	var buf bytes.Buffer
+	if err := NewConsoleExporter().ExportRangeTo(&buf, positionRange); err != nil {
+		t.Fatalf("ConsoleExporter.ExportRangeTo() error = %v", err)
+	}
+
+	output := buf.String()
+	for i, r := range output {
+		if r > 127 {
+			t.Fatalf("Expected ASCII only, got %q at byte %d of:\n%s", r, i, output)
+		}
+	}
+	if !strings.Contains(output, "[REPLAY] Replay: mysql-bin.000002:4900 -> mysql-bin.000003:5157") {
+		t.Errorf("Expected the replay line with ASCII tags, got:\n%s", output)
+	}
+}
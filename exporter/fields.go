@@ -0,0 +1,75 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+)
+
+// PositionFields returns the JSON field names of models.GTIDPosition, in struct order
+func PositionFields() []string {
+	var fields []string
+	t := reflect.TypeOf(models.GTIDPosition{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// ParseFields parses a comma-separated field list (e.g. "gtid,resume_position")
+// and checks every name against the JSON tags of models.GTIDPosition
+func ParseFields(spec string) ([]string, error) {
+	valid := make(map[string]bool)
+	for _, name := range PositionFields() {
+		valid[name] = true
+	}
+
+	var fields []string
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !valid[name] {
+			return nil, fmt.Errorf("unknown field %q (valid: %s)", name, strings.Join(PositionFields(), ", "))
+		}
+		fields = append(fields, name)
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no fields given")
+	}
+	return fields, nil
+}
+
+// projectPosition returns only the requested JSON fields of a position.
+// Empty omitempty fields stay absent, as in the full JSON output.
+func projectPosition(pos *models.GTIDPosition, fields []string) (map[string]interface{}, error) {
+	data, err := json.Marshal(pos)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep numbers as json.Number so positions and GNOs are not turned into floats
+	var all map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&all); err != nil {
+		return nil, err
+	}
+
+	projected := make(map[string]interface{}, len(fields))
+	for _, name := range fields {
+		if value, ok := all[name]; ok {
+			projected[name] = value
+		}
+	}
+	return projected, nil
+}
@@ -0,0 +1,60 @@
+package exporter
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+)
+
+// ChangeReplicationSQL returns the statement pointing a replica at the resume position
+// of pos (MySQL 8.0.23+ syntax, file/position based replication)
+func ChangeReplicationSQL(pos *models.GTIDPosition) string {
+	return fmt.Sprintf("CHANGE REPLICATION SOURCE TO SOURCE_LOG_FILE='%s', SOURCE_LOG_POS=%d;",
+		filepath.Base(pos.ResumeBinlogFile()), pos.ResumePosition)
+}
+
+// CloneSQLExporter writes, for each GTID position, the statements an operator runs on a
+// new replica restored from a backup or archive to seed it at the resume position
+type CloneSQLExporter struct{}
+
+// NewCloneSQLExporter creates a new clone-sql exporter
+func NewCloneSQLExporter() *CloneSQLExporter {
+	return &CloneSQLExporter{}
+}
+
+// Export writes the clone statements of every position to output (stdout if empty)
+func (e *CloneSQLExporter) Export(positions []*models.GTIDPosition, output string) error {
+	file, err := createOutput(output, "SQL")
+	if err != nil {
+		return err
+	}
+	return closeOutput(file, e.ExportTo(file, positions))
+}
+
+// ExportTo writes the clone statements of every position to w
+func (e *CloneSQLExporter) ExportTo(w io.Writer, positions []*models.GTIDPosition) error {
+	for _, pos := range positions {
+		if _, err := io.WriteString(w, CloneSQL(pos)); err != nil {
+			return fmt.Errorf("failed to write SQL: %w", err)
+		}
+	}
+	return nil
+}
+
+// CloneSQL returns the statements seeding a new replica at the resume position of pos.
+// RESET MASTER comes first since it empties GTID_EXECUTED, which GTID_PURGED can only be
+// set on; GTID_PURGED then declares every transaction up to the resume position applied
+// before replication is pointed at it.
+func CloneSQL(pos *models.GTIDPosition) string {
+	return fmt.Sprintf(`-- Seed a new replica after %s, resuming at %s:%d
+-- WARNING: RESET MASTER deletes all binary logs and the GTID history of the server it
+-- runs on. Run this on the new replica only, never on the source.
+STOP REPLICA;
+RESET MASTER;
+SET @@GLOBAL.GTID_PURGED='%s';
+%s
+START REPLICA;
+`, pos.GTID, filepath.Base(pos.ResumeBinlogFile()), pos.ResumePosition, pos.ExecutedSet, ChangeReplicationSQL(pos))
+}
@@ -0,0 +1,285 @@
+package exporter
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+)
+
+// StreamWriter writes positions one at a time as they are found,
+// so a killed process still leaves the rows written so far on disk
+type StreamWriter interface {
+	Write(pos *models.GTIDPosition) error
+	Close() error
+}
+
+// createOutput opens the output file, or stdout for "" and "-". A ".gz" output is
+// gzip compressed and only complete once closed.
+func createOutput(output, kind string) (io.WriteCloser, error) {
+	if output == "" || output == "-" {
+		return os.Stdout, nil
+	}
+
+	file, err := os.Create(output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s file: %w", kind, err)
+	}
+	if strings.HasSuffix(output, ".gz") {
+		return &gzipFile{Writer: gzip.NewWriter(file), file: file}, nil
+	}
+	return file, nil
+}
+
+// outputCloser returns file as the closer of a stream, nil for stdout
+func outputCloser(file io.WriteCloser) io.Closer {
+	if file == os.Stdout {
+		return nil
+	}
+	return file
+}
+
+// closeOutput closes file once written, unless it is stdout, and returns the
+// write error err or else the close error (a gzip output is flushed on close)
+func closeOutput(file io.WriteCloser, err error) error {
+	if file == os.Stdout {
+		return err
+	}
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		return fmt.Errorf("failed to write output file: %w", closeErr)
+	}
+	return err
+}
+
+// gzipFile compresses what is written to file
+type gzipFile struct {
+	*gzip.Writer
+	file *os.File
+}
+
+// Close writes the gzip footer and closes the file
+func (f *gzipFile) Close() error {
+	err := f.Writer.Close()
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// csvStream writes CSV rows, flushing every FlushEvery rows
+type csvStream struct {
+	exporter *CSVExporter
+	closer   io.Closer // Output file, nil when the caller owns the writer
+	writer   *csv.Writer
+	rows     int
+}
+
+// Stream opens output and writes the CSV header; rows follow with Write
+func (e *CSVExporter) Stream(output string) (StreamWriter, error) {
+	file, err := createOutput(output, "CSV")
+	if err != nil {
+		return nil, err
+	}
+	return e.stream(file, outputCloser(file))
+}
+
+// StreamTo writes the CSV header to w; rows follow with Write.
+// Close flushes the rows but leaves w open.
+func (e *CSVExporter) StreamTo(w io.Writer) (StreamWriter, error) {
+	return e.stream(w, nil)
+}
+
+// stream starts a CSV stream on w, closing closer (if not nil) with it
+func (e *CSVExporter) stream(w io.Writer, closer io.Closer) (StreamWriter, error) {
+	writer := csv.NewWriter(w)
+	writer.Comma = e.Delimiter
+
+	stream := &csvStream{exporter: e, closer: closer, writer: writer}
+	if e.IncludeHeader {
+		if err := writer.Write(e.header()); err != nil {
+			stream.Close()
+			return nil, fmt.Errorf("failed to write CSV header: %w", err)
+		}
+	}
+
+	return stream, nil
+}
+
+// Write writes a row and flushes when FlushEvery rows are buffered
+func (w *csvStream) Write(pos *models.GTIDPosition) error {
+	row, err := w.exporter.row(pos)
+	if err != nil {
+		return err
+	}
+	if err := w.writer.Write(row); err != nil {
+		return fmt.Errorf("failed to write CSV row: %w", err)
+	}
+
+	w.rows++
+	if w.exporter.FlushEvery > 0 && w.rows%w.exporter.FlushEvery == 0 {
+		w.writer.Flush()
+		if err := w.writer.Error(); err != nil {
+			return fmt.Errorf("failed to flush CSV rows: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Close flushes the remaining rows and closes the output file
+func (w *csvStream) Close() error {
+	w.writer.Flush()
+	err := w.writer.Error()
+
+	if w.closer != nil {
+		if closeErr := w.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to write CSV file: %w", err)
+	}
+	return nil
+}
+
+// consoleStream prints each position as it is found
+type consoleStream struct {
+	exporter *ConsoleExporter
+	out      io.Writer
+}
+
+// Stream returns a StreamWriter printing each position with ExportSingle
+func (e *ConsoleExporter) Stream() StreamWriter {
+	return e.StreamTo(os.Stdout)
+}
+
+// StreamTo returns a StreamWriter printing each position to w with ExportSingleTo
+func (e *ConsoleExporter) StreamTo(w io.Writer) StreamWriter {
+	return &consoleStream{exporter: e, out: w}
+}
+
+func (w *consoleStream) Write(pos *models.GTIDPosition) error {
+	return w.exporter.ExportSingleTo(w.out, pos)
+}
+
+func (w *consoleStream) Close() error {
+	return nil
+}
+
+// ndjsonStream writes one JSON object per line (NDJSON), unbuffered
+type ndjsonStream struct {
+	closer  io.Closer // Output file, nil when the caller owns the writer
+	encoder *json.Encoder
+	fields  []string
+}
+
+// Stream opens output for NDJSON: one position per line, written as soon as it is found.
+// With Bare the lines are elements of a JSON array, closed by Close. There is no
+// envelope, so PrettyPrint is ignored.
+func (e *JSONExporter) Stream(output string) (StreamWriter, error) {
+	file, err := createOutput(output, "JSON")
+	if err != nil {
+		return nil, err
+	}
+
+	if e.Bare {
+		return &jsonArrayStream{closer: outputCloser(file), out: file, fields: e.Fields}, nil
+	}
+	return &ndjsonStream{closer: outputCloser(file), encoder: json.NewEncoder(file), fields: e.Fields}, nil
+}
+
+// StreamTo writes NDJSON to w like Stream; Close leaves w open
+func (e *JSONExporter) StreamTo(w io.Writer) StreamWriter {
+	if e.Bare {
+		return &jsonArrayStream{out: w, fields: e.Fields}
+	}
+	return &ndjsonStream{encoder: json.NewEncoder(w), fields: e.Fields}
+}
+
+// Write encodes a position (or its projection) as one line
+func (w *ndjsonStream) Write(pos *models.GTIDPosition) error {
+	value, err := streamValue(pos, w.fields)
+	if err != nil {
+		return err
+	}
+
+	if err := w.encoder.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return nil
+}
+
+// Close closes the output file
+func (w *ndjsonStream) Close() error {
+	if w.closer == nil {
+		return nil
+	}
+	return w.closer.Close()
+}
+
+// streamValue is the JSON value of a streamed position: the position, or its projection
+func streamValue(pos *models.GTIDPosition, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return pos, nil
+	}
+	projected, err := projectPosition(pos, fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to project fields: %w", err)
+	}
+	return projected, nil
+}
+
+// jsonArrayStream writes positions as the elements of a JSON array, one per line,
+// unbuffered. The array is only complete, and valid JSON, once closed.
+type jsonArrayStream struct {
+	closer  io.Closer // Output file, nil when the caller owns the writer
+	out     io.Writer
+	fields  []string
+	written bool
+}
+
+// Write encodes a position (or its projection) as the next element
+func (w *jsonArrayStream) Write(pos *models.GTIDPosition) error {
+	value, err := streamValue(pos, w.fields)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+
+	separator := ",\n"
+	if !w.written {
+		separator = "[\n"
+	}
+	if _, err := io.WriteString(w.out, separator+string(data)); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	w.written = true
+	return nil
+}
+
+// Close ends the array, empty if nothing was written, and closes the output file
+func (w *jsonArrayStream) Close() error {
+	end := "\n]\n"
+	if !w.written {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(w.out, end)
+	if w.closer != nil {
+		if closeErr := w.closer.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
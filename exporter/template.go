@@ -0,0 +1,113 @@
+package exporter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+)
+
+// TemplateExporter renders each GTID position with a text/template over
+// models.GTIDPosition (e.g. "{{.BinlogFile}} {{.ResumePosition}} {{.GTID}}"),
+// one line per position
+type TemplateExporter struct {
+	tmpl *template.Template
+}
+
+// templateFuncs are the functions available to output templates besides the builtins
+var templateFuncs = template.FuncMap{
+	"base": filepath.Base, // File name of a binlog path
+}
+
+// NewTemplateExporter parses an output template. It is rendered once against an
+// empty position, so references to unknown fields fail here rather than mid-output.
+func NewTemplateExporter(text string) (*TemplateExporter, error) {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &TemplateExporter{tmpl: tmpl}
+	if _, err := e.render(&models.GTIDPosition{}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// render returns the line of a position, newline terminated
+func (e *TemplateExporter) render(pos *models.GTIDPosition) (string, error) {
+	var buf bytes.Buffer
+	if err := e.tmpl.Execute(&buf, pos); err != nil {
+		return "", err
+	}
+	line := buf.String()
+	if !strings.HasSuffix(line, "\n") {
+		line += "\n"
+	}
+	return line, nil
+}
+
+// Export writes the line of every position to output (stdout if empty)
+func (e *TemplateExporter) Export(positions []*models.GTIDPosition, output string) error {
+	file, err := createOutput(output, "template")
+	if err != nil {
+		return err
+	}
+	return closeOutput(file, e.ExportTo(file, positions))
+}
+
+// ExportTo writes the line of every position to w
+func (e *TemplateExporter) ExportTo(w io.Writer, positions []*models.GTIDPosition) error {
+	stream := e.StreamTo(w)
+	for _, pos := range positions {
+		if err := stream.Write(pos); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateStream writes the line of each position as it is found, unbuffered
+type templateStream struct {
+	exporter *TemplateExporter
+	closer   io.Closer // Output file, nil when the caller owns the writer
+	out      io.Writer
+}
+
+// Stream opens output and writes the line of each position as it is found
+func (e *TemplateExporter) Stream(output string) (StreamWriter, error) {
+	file, err := createOutput(output, "template")
+	if err != nil {
+		return nil, err
+	}
+	return &templateStream{exporter: e, closer: outputCloser(file), out: file}, nil
+}
+
+// StreamTo writes lines to w like Stream; Close leaves w open
+func (e *TemplateExporter) StreamTo(w io.Writer) StreamWriter {
+	return &templateStream{exporter: e, out: w}
+}
+
+// Write renders a position as one line
+func (w *templateStream) Write(pos *models.GTIDPosition) error {
+	line, err := w.exporter.render(pos)
+	if err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	if _, err := io.WriteString(w.out, line); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// Close closes the output file
+func (w *templateStream) Close() error {
+	if w.closer == nil {
+		return nil
+	}
+	return w.closer.Close()
+}
@@ -0,0 +1,88 @@
+// Package emoji holds the emoji prefixes of the status lines and the console output
+// in one place, so -no-emoji switches all of them to plain ASCII tags at once.
+package emoji
+
+import "sync/atomic"
+
+var disabled atomic.Bool
+
+// Disable prints every icon as its ASCII tag, for terminals and log collectors that
+// render emoji badly or misalign them
+func Disable() {
+	disabled.Store(true)
+}
+
+// Icon is an emoji prefix with its ASCII replacement
+type Icon struct {
+	emoji string // Padded with a space when it renders wider than it counts (variation selector)
+	tag   string
+}
+
+// String returns the emoji, or the tag once Disable is called
+func (i Icon) String() string {
+	if disabled.Load() {
+		return i.tag
+	}
+	return i.emoji
+}
+
+// Outcomes
+var (
+	OK       = Icon{"✅", "[OK]"}
+	Error    = Icon{"❌", "[ERROR]"}
+	NotFound = Icon{"❌", "[NOT FOUND]"}
+	Warning  = Icon{"⚠️ ", "[WARN]"}
+	Timeout  = Icon{"⏱️ ", "[TIMEOUT]"}
+	Hint     = Icon{"💡", "[HINT]"}
+)
+
+// Status lines
+var (
+	Search     = Icon{"🔍", "[SEARCH]"}
+	Info       = Icon{"📊", "[INFO]"}
+	List       = Icon{"📋", "[LIST]"}
+	Directory  = Icon{"📂", "[DIR]"}
+	Output     = Icon{"📁", "[OUTPUT]"}
+	Config     = Icon{"⚙️ ", "[CONFIG]"}
+	Lock       = Icon{"🔒", "[LOCK]"}
+	Server     = Icon{"🌐", "[SERVER]"}
+	Replica    = Icon{"🔗", "[REPLICA]"}
+	Stop       = Icon{"⏹️ ", "[STOP]"}
+	S3         = Icon{"☁️ ", "[S3]"}
+	SSH        = Icon{"🔐", "[SSH]"}
+	Precheck   = Icon{"🩺", "[PRECHECK]"}
+	Target     = Icon{"🎯", "[TARGET]"}
+	Verified   = Icon{"🔎", "[VERIFIED]"}
+	Raw        = Icon{"🧬", "[RAW]"}
+	Compare    = Icon{"🆚", "[COMPARE]"}
+	Extra      = Icon{"➕", "[EXTRA]"}
+	Missing    = Icon{"🧮", "[MISSING]"}
+	Dedupe     = Icon{"🧹", "[DEDUPE]"}
+	Checkpoint = Icon{"💾", "[CHECKPOINT]"}
+	Resolved   = Icon{"↩️ ", "[CHECKPOINT]"}
+	Repoint    = Icon{"📌", "[REPOINT]"}
+	Replay     = Icon{"▶️ ", "[REPLAY]"}
+	Elapsed    = Icon{"⏱️ ", "[TIME]"}
+)
+
+// Position fields
+var (
+	GTID        = Icon{"🆔", "[GTID]"}
+	File        = Icon{"📄", "[FILE]"}
+	Position    = Icon{"📍", "[POS]"}
+	Previous    = Icon{"🔙", "[PREV]"}
+	Next        = Icon{"🔄", "[NEXT]"}
+	GTIDSet     = Icon{"📦", "[SET]"}
+	Count       = Icon{"🔢", "[COUNT]"}
+	Time        = Icon{"🕐", "[TIME]"}
+	Database    = Icon{"💾", "[DB]"}
+	GroupCommit = Icon{"🔗", "[GROUP]"}
+	Sizes       = Icon{"📏", "[SIZES]"}
+	Databases   = Icon{"🗄️ ", "[DATABASES]"}
+)
+
+// Arrows between two files or directories
+var (
+	Arrow    = Icon{"→", "->"}
+	BothWays = Icon{"↔", "<->"}
+)
@@ -0,0 +1,23 @@
+package emoji
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestDisable(t *testing.T) {
+	if got := fmt.Sprintf("%s Found GTID", OK); got != "✅ Found GTID" {
+		t.Errorf("enabled = %q", got)
+	}
+	if got := fmt.Sprintf("%s Partial result", Warning); got != "⚠️  Partial result" {
+		t.Errorf("enabled with padding = %q", got)
+	}
+
+	Disable()
+	if got := fmt.Sprintf("%s Found GTID", OK); got != "[OK] Found GTID" {
+		t.Errorf("disabled = %q", got)
+	}
+	if got := fmt.Sprintln(Warning, "Partial result"); got != "[WARN] Partial result\n" {
+		t.Errorf("disabled with padding = %q", got)
+	}
+}
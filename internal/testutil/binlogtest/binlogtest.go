@@ -0,0 +1,27 @@
+// Package binlogtest holds the testutil helpers that fail a test directly. It imports
+// the testing package, so unlike testutil it is only imported by tests.
+package binlogtest
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
+)
+
+// WriteBinlogs writes binlogs to dir as mysql-bin.000001, mysql-bin.000002 and so on,
+// in order, failing the test on error. It returns the binlog paths.
+func WriteBinlogs(t testing.TB, dir string, binlogs []*testutil.Binlog) []string {
+	t.Helper()
+
+	files := make([]string, 0, len(binlogs))
+	for i, binlog := range binlogs {
+		path := filepath.Join(dir, fmt.Sprintf("mysql-bin.%06d", i+1))
+		if err := binlog.WriteFile(path); err != nil {
+			t.Fatalf("Failed to write binlog: %v", err)
+		}
+		files = append(files, path)
+	}
+	return files
+}
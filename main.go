@@ -1,199 +1,2144 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"math"
+	"net"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/quyetmv/mysql-gtid-position/exporter"
+	"github.com/quyetmv/mysql-gtid-position/internal/emoji"
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
 	"github.com/quyetmv/mysql-gtid-position/models"
 	"github.com/quyetmv/mysql-gtid-position/parser"
 	"github.com/quyetmv/mysql-gtid-position/searcher"
+	"github.com/quyetmv/mysql-gtid-position/server"
+	"github.com/quyetmv/mysql-gtid-position/tui"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"gopkg.in/yaml.v3"
 )
 
-func main() {
-	cfg := parseFlags()
+// exitTimeout is the exit code when -timeout stops the search before it finished
+const exitTimeout = 3
+
+// status receives progress and summary lines. It is stderr for csv and json output,
+// or with -quiet, so that stdout carries nothing but the result.
+var status io.Writer = os.Stdout
+
+func main() {
+	cfg, err := parseFlags()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Hidden fixtures command: sample binlogs for manual testing, no search
+	if cfg.GenFixtures != "" {
+		files, err := testutil.WriteFixtures(cfg.GenFixtures)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s Error: %v\n", emoji.Error, err)
+			os.Exit(1)
+		}
+		for _, file := range files {
+			fmt.Println(file)
+		}
+		fmt.Printf("%s Wrote %d binlogs with %s:1-300\n", emoji.OK, len(files), testutil.FixtureUUID)
+		return
+	}
+
+	if err := validateConfig(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if cfg.OutputFormat != models.FormatConsole || cfg.Quiet {
+		status = os.Stderr
+	}
+
+	if cfg.Parallel == 0 {
+		cfg.Parallel = searcher.AutoParallel(cfg.Storage, cfg.BinlogDir)
+		if cfg.Verbose {
+			fmt.Fprintf(status, "%s Auto parallelism: %d workers (storage: %s)\n", emoji.Config, cfg.Parallel, cfg.Storage)
+		}
+	}
+
+	if cfg.MaxGlobalParallel > 0 {
+		lockFile, err := searcher.OpenGlobalLock(cfg.BinlogDir, cfg.MaxGlobalParallel)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s Error: %v\n", emoji.Error, err)
+			os.Exit(1)
+		}
+		if cfg.Verbose {
+			fmt.Fprintf(status, "%s Global parallelism: %d files at once across instances (%s)\n", emoji.Lock, cfg.MaxGlobalParallel, lockFile)
+		}
+	}
+
+	// Server mode: lookups come from HTTP requests, -dir and -gtid are only defaults
+	if cfg.Serve != "" {
+		fmt.Fprintf(status, "%s Serving GTID lookups on %s\n", emoji.Server, cfg.Serve)
+		if err := server.NewServer(cfg).ListenAndServe(cfg.Serve); err != nil {
+			fmt.Fprintf(os.Stderr, "%s Server error: %v\n", emoji.Error, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// The replica's executed set drives the diff-and-locate mode, as if given with -executed
+	if cfg.FromReplica != "" {
+		executed, err := searcher.ReadReplicaExecutedGTID(context.Background(), cfg.FromReplica, cfg.ReplicaUser, cfg.ReplicaPassword)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s Error: %v\n", emoji.Error, err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(status, "%s Executed GTID set of replica %s: %s\n", emoji.Replica, cfg.FromReplica, executed)
+		cfg.ExecutedGTID = executed
+	}
+
+	if cfg.PosToGTID != "" {
+		err := gtidAtPosition(cfg)
+
+		var timeoutErr *searcher.TimeoutError
+		if errors.As(err, &timeoutErr) {
+			fmt.Fprintf(os.Stderr, "%s Lookup %v\n", emoji.Timeout, timeoutErr)
+			os.Exit(exitTimeout)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s Error: %v\n", emoji.Error, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(cfg.CompareDirs) > 0 {
+		same, err := compareDirectories(cfg)
+
+		var timeoutErr *searcher.TimeoutError
+		if errors.As(err, &timeoutErr) {
+			fmt.Fprintf(os.Stderr, "%s Compare %v\n", emoji.Timeout, timeoutErr)
+			os.Exit(exitTimeout)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s Error: %v\n", emoji.Error, err)
+			os.Exit(1)
+		}
+		if !same {
+			os.Exit(1)
+		}
+		return
+	}
+
+	start := time.Now()
+	if cfg.ExecutedGTID != "" {
+		fmt.Fprintf(status, "%s Searching for first GTID missing from: %s\n", emoji.Search, cfg.ExecutedGTID)
+	} else if cfg.BeforeGTID != "" {
+		fmt.Fprintf(status, "%s Searching for the transaction before: %s\n", emoji.Search, cfg.BeforeGTID)
+	} else if cfg.AfterGTID != "" {
+		fmt.Fprintf(status, "%s Searching for the transaction after: %s\n", emoji.Search, cfg.AfterGTID)
+	} else if cfg.Last {
+		fmt.Fprintln(status, emoji.Search, "Searching for the last GTID")
+	} else if cfg.Expr != "" {
+		fmt.Fprintf(status, "%s Searching for the first transaction satisfying: %s\n", emoji.Search, cfg.Expr)
+	} else if cfg.DetectDuplicates {
+		fmt.Fprintln(status, emoji.Search, "Searching for GTIDs committed more than once")
+	} else if cfg.ListDatabases {
+		fmt.Fprintln(status, emoji.Search, "Listing the databases written by the binlogs")
+	} else if cfg.ExecutedSet {
+		fmt.Fprintln(status, emoji.Search, "Computing the GTID set executed by the binlogs")
+	} else if cfg.GTIDFile != "" {
+		fmt.Fprintf(status, "%s Searching for GTIDs from file: %s\n", emoji.Search, cfg.GTIDFile)
+	} else if batchMode(cfg) {
+		fmt.Fprintf(status, "%s Searching for GTIDs: %s\n", emoji.Search, cfg.TargetGTID)
+	} else if cfg.TargetGTID == "" {
+		fmt.Fprintln(status, emoji.Search, "Searching for any GTID in the GNO window")
+	} else {
+		fmt.Fprintf(status, "%s Searching for GTID: %s\n", emoji.Search, cfg.TargetGTID)
+	}
+	if cfg.MinGNO > 0 || cfg.MaxGNO > 0 {
+		fmt.Fprintf(status, "%s GNO window: %s\n", emoji.Count, gnoWindow(cfg))
+	}
+	if cfg.EndGTID != "" {
+		fmt.Fprintf(status, "%s Stopping after GTID: %s\n", emoji.Stop, cfg.EndGTID)
+	}
+	if cfg.S3Source != "" {
+		fmt.Fprintf(status, "%s Binlog source: %s\n", emoji.S3, cfg.S3Source)
+	} else if cfg.SSHSource != "" {
+		fmt.Fprintf(status, "%s Binlog source: %s\n", emoji.SSH, cfg.SSHSource)
+	} else if cfg.FilesFrom != "" {
+		fmt.Fprintf(status, "%s Binlog files from: %s\n", emoji.Directory, cfg.FilesFrom)
+	} else if searcher.IsBinlogFile(cfg.BinlogDir) {
+		fmt.Fprintf(status, "%s Binlog file: %s\n", emoji.File, cfg.BinlogDir)
+	} else {
+		fmt.Fprintf(status, "%s Binlog directory: %s\n", emoji.Directory, cfg.BinlogDir)
+	}
+	fmt.Fprintf(status, "%s Output format: %s\n", emoji.Info, cfg.OutputFormat)
+	fmt.Fprintln(status, strings.Repeat("-", 60))
+
+	if cfg.TUI {
+		if err := browsePositions(cfg); err != nil {
+			fmt.Fprintf(os.Stderr, "%s Error: %v\n", emoji.Error, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if batchMode(cfg) {
+		found, err := runBatch(cfg, start)
+		if errors.Is(err, errBatchTimeout) {
+			fmt.Fprintf(os.Stderr, "%s Batch %v\n", emoji.Timeout, err)
+			os.Exit(exitTimeout)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s Error: %v\n", emoji.Error, err)
+			os.Exit(1)
+		}
+		if found == 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if cfg.Contains {
+		contained, err := containsGTID(cfg)
+
+		var timeoutErr *searcher.TimeoutError
+		if errors.As(err, &timeoutErr) {
+			fmt.Fprintf(os.Stderr, "%s Check %v\n", emoji.Timeout, timeoutErr)
+			os.Exit(exitTimeout)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s Error: %v\n", emoji.Error, err)
+			os.Exit(1)
+		}
+
+		fmt.Fprintln(status, strings.Repeat("-", 60))
+		if !contained {
+			fmt.Printf("%s GTID set is not contained in the binlogs (checked in %.2f seconds)\n", emoji.NotFound, time.Since(start).Seconds())
+			os.Exit(1)
+		}
+		fmt.Printf("%s GTID set is contained in the binlogs (checked in %.2f seconds)\n", emoji.OK, time.Since(start).Seconds())
+		return
+	}
+
+	if cfg.Range {
+		positionRange, err := findPositionRange(cfg)
+
+		var timeoutErr *searcher.TimeoutError
+		if errors.As(err, &timeoutErr) {
+			fmt.Fprintf(os.Stderr, "%s Search %v\n", emoji.Timeout, timeoutErr)
+			os.Exit(exitTimeout)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s Error: %v\n", emoji.Error, err)
+			os.Exit(1)
+		}
+		if positionRange == nil {
+			fmt.Fprintln(status, emoji.NotFound, "GTID not found in binlog files")
+			os.Exit(1)
+		}
+
+		if err := exportRange(positionRange, cfg, time.Since(start)); err != nil {
+			fmt.Fprintf(os.Stderr, "%s Export error: %v\n", emoji.Error, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if cfg.ExecutedSet {
+		executed, err := unionExecutedSet(cfg)
+
+		var timeoutErr *searcher.TimeoutError
+		if errors.As(err, &timeoutErr) {
+			fmt.Fprintf(os.Stderr, "%s Executed set %v\n", emoji.Timeout, timeoutErr)
+			os.Exit(exitTimeout)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s Error: %v\n", emoji.Error, err)
+			os.Exit(1)
+		}
+
+		fmt.Fprintln(status, strings.Repeat("-", 60))
+		fmt.Fprintf(status, "%s Computed the executed GTID set in %.2f seconds (%d transactions)\n",
+			emoji.OK, time.Since(start).Seconds(), transactionCount(executed))
+		fmt.Println(executed.String())
+		return
+	}
+
+	if cfg.ListDatabases {
+		databases, err := listDatabases(cfg)
+
+		var timeoutErr *searcher.TimeoutError
+		if err != nil && !errors.As(err, &timeoutErr) {
+			fmt.Fprintf(os.Stderr, "%s Error: %v\n", emoji.Error, err)
+			os.Exit(1)
+		}
+
+		if err := exportDatabases(databases, cfg, time.Since(start)); err != nil {
+			fmt.Fprintf(os.Stderr, "%s Export error: %v\n", emoji.Error, err)
+			os.Exit(1)
+		}
+		if timeoutErr != nil {
+			fmt.Fprintf(os.Stderr, "%s Database counts are partial: %v\n", emoji.Timeout, timeoutErr)
+			os.Exit(exitTimeout)
+		}
+		return
+	}
+
+	if cfg.DetectDuplicates {
+		duplicates, err := findDuplicates(cfg)
+
+		var timeoutErr *searcher.TimeoutError
+		if errors.As(err, &timeoutErr) {
+			fmt.Fprintf(os.Stderr, "%s Duplicate scan %v\n", emoji.Timeout, timeoutErr)
+			os.Exit(exitTimeout)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s Error: %v\n", emoji.Error, err)
+			os.Exit(1)
+		}
+
+		if err := exportDuplicates(duplicates, cfg, time.Since(start)); err != nil {
+			fmt.Fprintf(os.Stderr, "%s Export error: %v\n", emoji.Error, err)
+			os.Exit(1)
+		}
+		if len(duplicates) > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if cfg.Stats {
+		stats, err := transactionStats(cfg)
+
+		var timeoutErr *searcher.TimeoutError
+		if err != nil && !errors.As(err, &timeoutErr) {
+			fmt.Fprintf(os.Stderr, "%s Error: %v\n", emoji.Error, err)
+			os.Exit(1)
+		}
+
+		if err := exportStats(stats, cfg, time.Since(start)); err != nil {
+			fmt.Fprintf(os.Stderr, "%s Export error: %v\n", emoji.Error, err)
+			os.Exit(1)
+		}
+		if timeoutErr != nil {
+			fmt.Fprintf(os.Stderr, "%s Stats %v, the stats are partial\n", emoji.Timeout, timeoutErr)
+			os.Exit(exitTimeout)
+		}
+		return
+	}
+
+	if cfg.CountOnly {
+		count, err := countMatches(cfg)
+
+		var timeoutErr *searcher.TimeoutError
+		if err != nil && !errors.As(err, &timeoutErr) {
+			fmt.Fprintf(os.Stderr, "%s Error: %v\n", emoji.Error, err)
+			os.Exit(1)
+		}
+
+		printMatchCount(count, time.Since(start))
+		if timeoutErr != nil {
+			fmt.Fprintf(os.Stderr, "%s Count %v, the count is partial\n", emoji.Timeout, timeoutErr)
+			os.Exit(exitTimeout)
+		}
+		return
+	}
+
+	var search *models.SearchResult
+	if cfg.ExecutedGTID != "" {
+		search, err = findFirstMissingPosition(cfg)
+	} else if cfg.BeforeGTID != "" || cfg.AfterGTID != "" {
+		search, err = findNeighborPosition(cfg)
+	} else if cfg.Last {
+		search, err = findLastPosition(cfg)
+	} else if cfg.Expr != "" {
+		search, err = findExprPosition(cfg)
+	} else {
+		search, err = findGTIDPosition(cfg)
+	}
+
+	if errors.Is(err, errNothingMissing) {
+		fmt.Fprintln(status, emoji.OK, "Replica has executed all GTIDs of the master, nothing is missing")
+		return
+	}
+
+	var result *models.GTIDPosition
+	if search != nil && len(search.Positions) > 0 {
+		result = search.Positions[0]
+	}
+
+	var timeoutErr *searcher.TimeoutError
+	if errors.As(err, &timeoutErr) {
+		fmt.Fprintf(os.Stderr, "%s Search %v\n", emoji.Timeout, timeoutErr)
+		if result != nil {
+			fmt.Fprintln(status, emoji.Warning, "Partial result, a better match may exist in files not scanned")
+			search.Duration = time.Since(start)
+			if err := exportResult(search, cfg); err != nil {
+				fmt.Fprintf(os.Stderr, "%s Export error: %v\n", emoji.Error, err)
+			}
+		}
+		os.Exit(exitTimeout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s Error: %v\n", emoji.Error, err)
+		os.Exit(1)
+	}
+
+	if result == nil {
+		if cfg.ExecutedGTID != "" {
+			fmt.Fprintln(status, emoji.NotFound, "No missing transaction found in binlog files")
+		} else if cfg.BeforeGTID != "" {
+			fmt.Fprintln(status, emoji.NotFound, "No committed transaction before the GTID in binlog files")
+		} else if cfg.AfterGTID != "" {
+			fmt.Fprintln(status, emoji.NotFound, "No committed transaction after the GTID in binlog files")
+		} else if cfg.Last {
+			fmt.Fprintln(status, emoji.NotFound, "No committed transaction in binlog files")
+		} else if cfg.Expr != "" {
+			fmt.Fprintln(status, emoji.NotFound, "No transaction of the binlog files satisfies the expression")
+		} else {
+			fmt.Fprintln(status, emoji.NotFound, "GTID not found in binlog files")
+			if hint := notFoundHint(search.NotFoundReason); hint != "" {
+				fmt.Fprintf(status, "%s %s (%s)\n", emoji.Hint, hint, search.NotFoundReason)
+			}
+		}
+		if cfg.Recent > 0 {
+			fmt.Fprintf(status, "%s Only the newest %d files were searched (-recent)\n", emoji.Hint, cfg.Recent)
+		}
+		os.Exit(1)
+	}
+
+	// A position that does not decode would break the replica it is handed to
+	if cfg.Verify {
+		if err := verifyPosition(cfg, result); err != nil {
+			fmt.Fprintf(os.Stderr, "%s Verification failed: %v\n", emoji.Error, err)
+			os.Exit(1)
+		}
+	}
+
+	if cfg.Raw {
+		if err := dumpRawEvents(cfg, result); err != nil {
+			fmt.Fprintf(os.Stderr, "%s Raw dump failed: %v\n", emoji.Warning, err)
+		}
+	}
+
+	if cfg.ExecutedGTID == "" && cfg.BeforeGTID == "" && cfg.AfterGTID == "" && !cfg.Last && cfg.Expr == "" {
+		printFilterNotice(cfg)
+	}
+
+	search.Duration = time.Since(start)
+
+	// Export result based on format
+	if err := exportResult(search, cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "%s Export error: %v\n", emoji.Error, err)
+		os.Exit(1)
+	}
+
+	// The replica must restart at the beginning of the first missing transaction
+	if cfg.ExecutedGTID != "" && cfg.OutputFormat == models.FormatConsole {
+		fmt.Printf("%s Re-point replica at: %s:%d\n", emoji.Repoint, filepath.Base(result.BinlogFile), result.Position)
+	}
+}
+
+// printFilterNotice labels a match found with -database or -table, and warns that
+// its resume position skips the transactions filtered out after it unless
+// -filter-for-display-only resumes at the very next one
+func printFilterNotice(cfg *models.Config) {
+	var filters []string
+	if cfg.FilterDatabase != "" {
+		filters = append(filters, "database "+cfg.FilterDatabase)
+	}
+	if cfg.FilterTable != "" {
+		filters = append(filters, "table "+cfg.FilterTable)
+	}
+	if len(filters) == 0 {
+		return
+	}
+
+	fmt.Fprintf(status, "%s Position of the matching transaction in %s\n", emoji.Target, strings.Join(filters, ", "))
+	if cfg.FilterForDisplayOnly {
+		fmt.Fprintln(status, emoji.Info, "Resume position is the next transaction of the unfiltered stream (-filter-for-display-only)")
+	} else {
+		fmt.Fprintln(status, emoji.Warning, "The resume position skips the transactions filtered out after the match, which a replica may still need; -filter-for-display-only resumes right after it")
+	}
+}
+
+// hiddenFlags are left out of -help
+var hiddenFlags = map[string]bool{"gen-fixtures": true}
+
+// usage prints the default usage message without hiddenFlags
+func usage() {
+	visible := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	visible.SetOutput(flag.CommandLine.Output())
+	flag.VisitAll(func(f *flag.Flag) {
+		if !hiddenFlags[f.Name] {
+			visible.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+
+	fmt.Fprintf(flag.CommandLine.Output(), "Usage of %s:\n", os.Args[0])
+	visible.PrintDefaults()
+}
+
+func parseFlags() (*models.Config, error) {
+	cfg := &models.Config{}
+
+	var formatStr, selectionStr, sortStr, fieldsStr, allowedUUIDsStr, compareStr string
+	var parallelStr string
+	var startTimeStr, endTimeStr string
+
+	flag.StringVar(&cfg.BinlogDir, "dir", "", "Binlog directory path, or a single binlog file to scan (required unless -s3, -ssh or -files-from)")
+	flag.StringVar(&cfg.S3Source, "s3", "", "Stream binlogs from S3 instead of -dir (e.g., s3://bucket/prefix/mysql-bin.*, needs a -tags s3 build)")
+	flag.StringVar(&cfg.SSHSource, "ssh", "", "Stream binlogs over SFTP instead of -dir (e.g., ssh://user@host/var/lib/mysql/mysql-bin.*, needs a -tags ssh build)")
+	flag.StringVar(&cfg.TargetGTID, "gtid", "", "Target GTID to find (required); several sets separated by \";\" are searched one by one like -gtid-file")
+	flag.StringVar(&cfg.GTIDFile, "gtid-file", "", "File containing multiple GTIDs (one per line)")
+	flag.StringVar(&cfg.GTIDSetFile, "gtid-set-file", "", "Read the -gtid set from this file, one set that may span lines and UUIDs (e.g., a saved gtid_executed)")
+	flag.BoolVar(&cfg.ContinueOnError, "continue-on-error", false, "With -gtid-file, report an entry whose search fails as not found and go on with the batch")
+	flag.BoolVar(&cfg.Dedupe, "dedupe", false, "With -gtid-file, search identical GTID sets only once (compared in canonical form)")
+	flag.BoolVar(&cfg.SummaryOnly, "summary-only", false, "With -gtid-file, print only a summary (found, not found, purged, timed out, failed, file range) instead of each result")
+	flag.StringVar(&cfg.Checkpoint, "checkpoint", "", "With -gtid-file, record resolved GTIDs in this NDJSON file and skip them on restart")
+	flag.StringVar(&cfg.ExecutedGTID, "executed", "", "Replica's gtid_executed set; locate the first transaction it is missing (-gtid is the master's set)")
+	flag.StringVar(&cfg.FromReplica, "from-replica", "", "Read -executed from SHOW REPLICA STATUS of this live replica (host:port), password from MYSQL_PWD")
+	flag.StringVar(&cfg.ReplicaUser, "replica-user", "root", "User for -from-replica")
+	flag.StringVar(&cfg.BeforeGTID, "before-gtid", "", "Locate the committed transaction just before this GTID (e.g., UUID:500), across files")
+	flag.StringVar(&cfg.AfterGTID, "after-gtid", "", "Locate the committed transaction just after this GTID (e.g., UUID:500), across files")
+	flag.StringVar(&cfg.FilePattern, "pattern", "mysql-bin.*", "Binlog file pattern (glob, with {a,b} alternatives)")
+	flag.StringVar(&cfg.FilePatternRegex, "pattern-regex", "", "Select binlog files whose name fully matches this regular expression, instead of -pattern")
+	flag.StringVar(&cfg.IndexFile, "index-file", "", "Read binlog file order from this index file (e.g., mysql-bin.index) instead of -pattern")
+	flag.StringVar(&cfg.FilesFrom, "files-from", "", "Scan the binlogs listed in this file, one path per line and in order (\"-\" for stdin), instead of -dir")
+	flag.BoolVar(&cfg.NoSmartStart, "no-smart-start", false, "Ignore PREVIOUS_GTIDS headers (purged check, -before-gtid/-after-gtid/-expr file lookup) and scan from the first file")
+	flag.Var(showSkippedFlag{&cfg.ShowSkipped}, "show-skipped", "Log the PREVIOUS_GTIDS headers smart selection reads and its decisions; -show-skipped=full reads every file's header")
+	flag.BoolVar(&cfg.Precheck, "precheck", false, "Read the header of every binlog before scanning and report unreadable, non-binlog, encrypted and version-mismatched files")
+	flag.BoolVar(&cfg.Strict, "strict", false, "With -precheck, exit 1 instead of scanning when an issue is found")
+	flag.StringVar(&cfg.StartFile, "start-file", "", "Start searching from this binlog file (e.g., mysql-bin.000100)")
+	flag.StringVar(&cfg.BeforeFile, "before-file", "", "Stop searching before this binlog file, which is not scanned (e.g., mysql-bin.000500)")
+	flag.IntVar(&cfg.Recent, "recent", 0, "Only search the newest N binlog files, e.g. when the GTID is known to be recent")
+	flag.StringVar(&cfg.EndGTID, "end-gtid", "", "Stop scanning once past this GTID (e.g., UUID:500) of its server, -gtid being the start bound")
+	flag.StringVar(&parallelStr, "parallel", "4", "Number of parallel workers, or \"auto\" to pick from CPUs and -storage")
+	flag.StringVar(&cfg.Storage, "storage", searcher.StorageAuto, "Storage hint for -parallel auto: auto, ssd, hdd, nfs")
+	flag.IntVar(&cfg.MaxGlobalParallel, "max-global-parallel", 0, "Cap the files scanned at once by all instances on the same -dir, coordinated through a lock file in it (0 = no cap)")
+	flag.BoolVar(&cfg.Bisect, "bisect", false, "Bracket the target in large binlog files (over 32MB) by sampling GTIDs at offsets, then scan only that window; assumes GNOs grow through a file")
+	flag.DurationVar(&cfg.Timeout, "timeout", 0, "Stop the search after this duration (e.g., 5m) and report the best partial result")
+	flag.BoolVar(&cfg.Verbose, "verbose", false, "Verbose output")
+	flag.BoolVar(&cfg.Quiet, "quiet", false, "Write status lines to stderr, stdout only carries the result (always so with csv and json)")
+	flag.BoolVar(&cfg.NoEmoji, "no-emoji", false, "Print plain ASCII tags ([OK], [ERROR], [SEARCH], ...) instead of emoji in status lines and console output")
+	flag.StringVar(&cfg.LogFormat, "log-format", models.LogFormatText, "Log format for verbose messages: text, json")
+	flag.StringVar(&formatStr, "format", "console", "Output format: console, csv, json, json-array (positions without the envelope), canal (go-mysql mysql.Position of the resume position), clone-sql (SQL seeding a new replica)")
+	flag.StringVar(&cfg.OutputFile, "output", "", "Output file (default: stdout)")
+	flag.StringVar(&cfg.OutputTemplate, "output-template", "", "Render each result with this Go text/template over the position fields, one line each (e.g., '{{.BinlogFile}} {{.ResumePosition}} {{.GTID}}')")
+	flag.StringVar(&cfg.OutputDir, "output-dir", "", "With -gtid-file, write each result to its own <dir>/<gtid>.<format> file (csv, json)")
+	flag.BoolVar(&cfg.Stream, "stream", false, "Write results as soon as they are found (NDJSON for json, per-row flush for csv)")
+	flag.StringVar(&cfg.TimeFormat, "time-format", "", "Timestamp format of console, csv and tui output: a Go layout, unix or unixmilli (default RFC3339)")
+	flag.StringVar(&fieldsStr, "fields", "", "Comma-separated fields to output for json/csv (e.g., gtid,resume_position,binlog_file)")
+	flag.BoolVar(&cfg.FindActiveMaster, "find-active-master", false, "Auto-detect and search for active master UUID (highest GNO)")
+	flag.StringVar(&cfg.FilterUUID, "uuid", "", "Filter search by specific server UUID, or a unique prefix of it (e.g. 3e11fa47)")
+	flag.Uint64Var(&cfg.MinGNO, "min-gno", 0, "Only match GTIDs with a GNO of at least this, of any server UUID without -gtid or -uuid")
+	flag.Uint64Var(&cfg.MaxGNO, "max-gno", 0, "Only match GTIDs with a GNO of at most this, of any server UUID without -gtid or -uuid")
+	flag.StringVar(&allowedUUIDsStr, "allowed-uuids", "", "Only accept transactions of these server UUIDs: comma-separated list or file (one per line); a -gtid set with other UUIDs is an error")
+	flag.StringVar(&cfg.FilterDatabase, "database", "", "Filter search by database name")
+	flag.StringVar(&cfg.FilterTable, "table", "", "Filter search by table written, \"table\" (in -database if set) or \"db.table\" (from TABLE_MAP events, or the SQL of statement-based binlogs)")
+	flag.BoolVar(&cfg.FilterForDisplayOnly, "filter-for-display-only", false, "With -database or -table, still report the matching transaction but resume at the next transaction of the unfiltered stream, so none filtered out is skipped")
+	flag.StringVar(&startTimeStr, "start-time", "", "Filter events after this time (format: 2006-01-02 15:04:05 or RFC3339)")
+	flag.StringVar(&endTimeStr, "end-time", "", "Filter events before this time (format: 2006-01-02 15:04:05 or RFC3339)")
+	flag.BoolVar(&cfg.FindAll, "find-all", false, "Find all GTIDs in range (not just first match)")
+	flag.StringVar(&cfg.Serve, "serve", "", "Run as HTTP server on this address (e.g., :8080), serving GET /find?gtid=...&dir=... and /healthz")
+	flag.BoolVar(&cfg.Contains, "contains", false, "Only check whether the -gtid set is contained in the binlogs (exit code 0 yes, 1 no)")
+	flag.BoolVar(&cfg.TUI, "tui", false, "Browse the found positions of -gtid or -gtid-file in an interactive table (needs a -tags tui build)")
+	flag.BoolVar(&cfg.Range, "range", false, "Report the position range to replay for the -gtid set: start of its first transaction to resume after its last (PITR)")
+	flag.StringVar(&compareStr, "compare", "", "Compare the executed GTID sets of -dir with those of this directory: -dir dirA -compare dirB")
+	flag.StringVar(&cfg.PosToGTID, "pos-to-gtid", "", "Report the GTID set executed up to a binlog position, file:pos (a bare file name is looked up in -dir)")
+	flag.StringVar(&cfg.Expr, "expr", "", "Locate the first transaction after which a boolean expression over GTID sets holds, e.g. \"contains(A) and any(B)\"")
+	flag.BoolVar(&cfg.Last, "last", false, "Report the newest transaction of the binlogs (head position), no -gtid needed")
+	flag.BoolVar(&cfg.Raw, "raw", false, "Debug: print the GTID and commit events of the found transaction in hex to stderr")
+	flag.BoolVar(&cfg.Verify, "verify", false, "Re-open the binlog and check that the reported position is a clean event boundary (exit 1 if not)")
+	flag.BoolVar(&cfg.CountOnly, "count-only", false, "Count transactions contained in the -gtid set (per UUID) instead of locating one")
+	flag.BoolVar(&cfg.Stats, "stats", false, "Report min/avg/max/p95 sizes of the transactions contained in the -gtid set (per UUID)")
+	flag.BoolVar(&cfg.DetectDuplicates, "detect-duplicates", false, "Report GTIDs committed more than once across the binlogs (errant transactions), exit 1 if any")
+	flag.BoolVar(&cfg.ExecutedSet, "executed-set", false, "Print the GTID set executed by the binlogs: the first file's previous GTIDs plus every GTID, e.g. for SET GLOBAL gtid_purged")
+	flag.BoolVar(&cfg.ListDatabases, "list-databases", false, "Report the databases written by the binlogs with their transaction counts, e.g. to pick a -database")
+	flag.StringVar(&selectionStr, "select", string(models.SelectionHighestGNO), "Which matching transaction to return: highest-gno, first, last")
+	flag.StringVar(&sortStr, "sort", "", "Order of batch results before export: position, timestamp (timelines) or gno (default: the order of the sets)")
+	flag.StringVar(&cfg.GenFixtures, "gen-fixtures", "", "Write sample binlogs to this directory and exit")
+	flag.String("config", "", "Read options from this YAML or JSON file, keys being flag names; command-line flags override it")
+
+	flag.Usage = usage
+
+	// Config file values are set as flags first, so that the command line overrides them
+	if path := configFileArg(os.Args[1:]); path != "" {
+		if err := loadConfigFile(path); err != nil {
+			return nil, err
+		}
+	}
+	flag.Parse()
+	if flag.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q, every option is a flag (-dir dirA -compare dirB to compare directories)", flag.Arg(0))
+	}
+
+	if cfg.NoEmoji {
+		emoji.Disable()
+	}
+
+	// Parse parallelism, 0 for auto (resolved after validation), -1 if invalid
+	if parallelStr == "auto" {
+		cfg.Parallel = 0
+	} else if n, err := strconv.Atoi(parallelStr); err == nil && n > 0 {
+		cfg.Parallel = n
+	} else {
+		cfg.Parallel = -1
+	}
+
+	// Parse format
+	cfg.OutputFormat = models.ExportFormat(formatStr)
+	cfg.Selection = models.Selection(selectionStr)
+	cfg.Sort = models.SortOrder(sortStr)
+	if fieldsStr != "" {
+		cfg.Fields = strings.Split(fieldsStr, ",")
+	}
+	if allowedUUIDsStr != "" {
+		cfg.AllowedUUIDs = strings.Split(allowedUUIDsStr, ",")
+	}
+	if compareStr != "" {
+		cfg.CompareDirs = []string{cfg.BinlogDir, compareStr}
+	}
+
+	// Kept off the command line, where other users can read it
+	cfg.ReplicaPassword = os.Getenv("MYSQL_PWD")
+
+	// Parse time filters
+	if startTimeStr != "" {
+		if t, err := parseTimeString(startTimeStr); err == nil {
+			cfg.StartTime = t
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: Invalid start-time format: %v\n", err)
+		}
+	}
+	if endTimeStr != "" {
+		if t, err := parseTimeString(endTimeStr); err == nil {
+			cfg.EndTime = t
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: Invalid end-time format: %v\n", err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// configFileArg returns the -config value of the command line, if any.
+// Like the flag package, it stops at the first non-flag argument or "--".
+func configFileArg(args []string) string {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || !strings.HasPrefix(arg, "-") {
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if name != "config" {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// loadConfigFile sets the flags named by the keys of a YAML or JSON file (by its
+// .json extension) to their values. Lists are comma-joined, as -fields and -allowed-uuids take them.
+func loadConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	options := make(map[string]any)
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber() // A float64 would print 2000000 as 2e+06
+		err = decoder.Decode(&options)
+	} else {
+		err = yaml.Unmarshal(data, &options)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name == "config" || hiddenFlags[name] || flag.Lookup(name) == nil {
+			return fmt.Errorf("config file %s: unknown option %q", path, name)
+		}
+		if err := flag.Set(name, configValue(options[name])); err != nil {
+			return fmt.Errorf("config file %s: invalid value for %q: %w", path, name, err)
+		}
+	}
+	return nil
+}
+
+// configValue renders a decoded config file value as a flag value
+func configValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case time.Time:
+		return v.Format(time.RFC3339) // Unquoted YAML timestamp
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = configValue(item)
+		}
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// gnoWindow formats the -min-gno/-max-gno window
+func gnoWindow(cfg *models.Config) string {
+	switch {
+	case cfg.MaxGNO == 0:
+		return fmt.Sprintf("%d and above", cfg.MinGNO)
+	case cfg.MinGNO == 0:
+		return fmt.Sprintf("up to %d", cfg.MaxGNO)
+	default:
+		return fmt.Sprintf("%d-%d", cfg.MinGNO, cfg.MaxGNO)
+	}
+}
+
+// showSkippedFlag is -show-skipped: a boolean flag that also takes =full
+type showSkippedFlag struct {
+	mode *string
+}
+
+func (f showSkippedFlag) String() string {
+	if f.mode == nil {
+		return ""
+	}
+	return *f.mode
+}
+
+func (f showSkippedFlag) Set(value string) error {
+	switch value {
+	case "true":
+		*f.mode = models.ShowSkippedProbed
+	case "false":
+		*f.mode = ""
+	case models.ShowSkippedProbed, models.ShowSkippedFull:
+		*f.mode = value
+	default:
+		return fmt.Errorf("must be a boolean, probed or full")
+	}
+	return nil
+}
+
+// IsBoolFlag lets -show-skipped be given without a value
+func (f showSkippedFlag) IsBoolFlag() bool {
+	return true
+}
+
+func validateConfig(cfg *models.Config) error {
+	// A template is its own output format, checked by the mode rules like the others
+	if cfg.OutputTemplate != "" {
+		if cfg.OutputFormat != models.FormatConsole {
+			return fmt.Errorf("-output-template replaces -format, they cannot be combined")
+		}
+		if _, err := exporter.NewTemplateExporter(cfg.OutputTemplate); err != nil {
+			return fmt.Errorf("invalid -output-template: %v", err)
+		}
+		cfg.OutputFormat = models.FormatTemplate
+	} else if cfg.OutputFormat == models.FormatTemplate {
+		return fmt.Errorf("-format template requires -output-template")
+	}
+	// A set file is one -gtid set, all its rules apply from here on
+	if cfg.GTIDSetFile != "" {
+		if cfg.TargetGTID != "" || cfg.GTIDFile != "" {
+			return fmt.Errorf("-gtid-set-file cannot be combined with -gtid or -gtid-file")
+		}
+		set, err := parser.ReadGTIDSetFile(cfg.GTIDSetFile)
+		if err != nil {
+			return fmt.Errorf("-gtid-set-file %s: %w", cfg.GTIDSetFile, err)
+		}
+		cfg.TargetGTID = set
+	}
+	if len(cfg.CompareDirs) > 0 {
+		if cfg.CompareDirs[0] == "" {
+			return fmt.Errorf("-compare needs the first directory in -dir: -dir dirA -compare dirB")
+		}
+		if cfg.S3Source != "" || cfg.FilesFrom != "" || cfg.TargetGTID != "" || cfg.GTIDFile != "" ||
+			cfg.ExecutedGTID != "" || cfg.FromReplica != "" || cfg.BeforeGTID != "" || cfg.AfterGTID != "" || cfg.Serve != "" ||
+			cfg.CountOnly || cfg.Contains || cfg.Range || cfg.Last || cfg.TUI {
+			return fmt.Errorf("-compare only takes -dir and the file selection flags (-pattern, -pattern-regex, -index-file)")
+		}
+		if cfg.OutputFormat != models.FormatConsole {
+			return fmt.Errorf("-compare only supports console output")
+		}
+		for _, dir := range cfg.CompareDirs {
+			if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+				return fmt.Errorf("binlog directory does not exist: %s", dir)
+			}
+		}
+	}
+	if cfg.PosToGTID != "" {
+		if cfg.S3Source != "" || cfg.FilesFrom != "" || cfg.TargetGTID != "" || cfg.GTIDFile != "" || cfg.ExecutedGTID != "" ||
+			cfg.FromReplica != "" || cfg.BeforeGTID != "" || cfg.AfterGTID != "" || cfg.Serve != "" || len(cfg.CompareDirs) > 0 ||
+			cfg.CountOnly || cfg.Contains || cfg.Range || cfg.Stats || cfg.Last || cfg.TUI {
+			return fmt.Errorf("-pos-to-gtid only takes -dir for a bare file name")
+		}
+		if cfg.OutputFormat != models.FormatConsole {
+			return fmt.Errorf("-pos-to-gtid only supports console output")
+		}
+		if _, _, err := parseFilePosition(cfg.PosToGTID); err != nil {
+			return err
+		}
+	}
+	if cfg.FromReplica != "" {
+		if cfg.ExecutedGTID != "" || batchMode(cfg) || cfg.BeforeGTID != "" || cfg.AfterGTID != "" || cfg.Serve != "" ||
+			cfg.CountOnly || cfg.Contains || cfg.Range || cfg.TUI {
+			return fmt.Errorf("-from-replica cannot be combined with -executed, -gtid-file, several -gtid sets, -before-gtid, -after-gtid, -serve, -count-only, -contains, -range or -tui")
+		}
+		if _, _, err := net.SplitHostPort(cfg.FromReplica); err != nil {
+			return fmt.Errorf("invalid -from-replica (must be host:port): %v", err)
+		}
+	}
+	if cfg.Last && (cfg.TargetGTID != "" || cfg.GTIDFile != "" || cfg.ExecutedGTID != "" || cfg.FromReplica != "" ||
+		cfg.BeforeGTID != "" || cfg.AfterGTID != "" || cfg.Serve != "" || cfg.CountOnly || cfg.Contains || cfg.Range || cfg.TUI) {
+		return fmt.Errorf("-last cannot be combined with -gtid, -gtid-file, -executed, -from-replica, -before-gtid, -after-gtid, -serve, -count-only, -contains, -range or -tui")
+	}
+	if cfg.Expr != "" {
+		if cfg.TargetGTID != "" || cfg.GTIDFile != "" || cfg.ExecutedGTID != "" || cfg.FromReplica != "" || cfg.BeforeGTID != "" || cfg.AfterGTID != "" ||
+			cfg.Serve != "" || cfg.CountOnly || cfg.Contains || cfg.Range || cfg.Stats || cfg.Last || cfg.DetectDuplicates || cfg.ListDatabases ||
+			cfg.ExecutedSet || cfg.TUI || len(cfg.CompareDirs) > 0 || cfg.MinGNO > 0 || cfg.MaxGNO > 0 {
+			return fmt.Errorf("-expr replaces -gtid, it cannot be combined with -gtid, -gtid-file, -executed, -from-replica, -before-gtid, -after-gtid, -min-gno, -max-gno, -serve or another mode")
+		}
+		if _, err := parser.ParseGTIDExpr(cfg.Expr); err != nil {
+			return err
+		}
+	}
+	if cfg.CountOnly && (cfg.TargetGTID == "" || batchMode(cfg) || cfg.ExecutedGTID != "" || cfg.Serve != "") {
+		return fmt.Errorf("-count-only requires a single -gtid set and cannot be combined with -executed or -serve")
+	}
+	if cfg.Contains && (cfg.TargetGTID == "" || batchMode(cfg) || cfg.ExecutedGTID != "" || cfg.Serve != "" || cfg.CountOnly) {
+		return fmt.Errorf("-contains requires a single -gtid set and cannot be combined with -executed, -serve or -count-only")
+	}
+	if cfg.TUI {
+		if cfg.ExecutedGTID != "" || cfg.Serve != "" || cfg.CountOnly || cfg.Contains || cfg.Range {
+			return fmt.Errorf("-tui cannot be combined with -executed, -serve, -count-only, -contains or -range")
+		}
+		if cfg.OutputFormat != models.FormatConsole || cfg.OutputFile != "" || cfg.OutputDir != "" || cfg.Stream || cfg.Checkpoint != "" {
+			return fmt.Errorf("-tui replaces the output, it cannot be combined with -format, -output, -output-dir, -stream or -checkpoint")
+		}
+	}
+	if cfg.Range {
+		if cfg.TargetGTID == "" || batchMode(cfg) || cfg.ExecutedGTID != "" || cfg.Serve != "" || cfg.CountOnly || cfg.Contains {
+			return fmt.Errorf("-range requires a single -gtid set and cannot be combined with -executed, -serve, -count-only or -contains")
+		}
+		if (cfg.OutputFormat != models.FormatConsole && cfg.OutputFormat != models.FormatJSON) || len(cfg.Fields) > 0 || cfg.Stream {
+			return fmt.Errorf("-range only supports console and json output, without -fields or -stream")
+		}
+	}
+	if cfg.Stats {
+		if cfg.TargetGTID == "" || batchMode(cfg) || cfg.ExecutedGTID != "" || cfg.Serve != "" || cfg.CountOnly || cfg.Contains || cfg.Range || cfg.TUI {
+			return fmt.Errorf("-stats requires a single -gtid set and cannot be combined with -executed, -serve, -count-only, -contains, -range or -tui")
+		}
+		if (cfg.OutputFormat != models.FormatConsole && cfg.OutputFormat != models.FormatJSON) || len(cfg.Fields) > 0 || cfg.Stream {
+			return fmt.Errorf("-stats only supports console and json output, without -fields or -stream")
+		}
+	}
+	if cfg.ListDatabases {
+		if cfg.TargetGTID != "" || cfg.GTIDFile != "" || cfg.ExecutedGTID != "" || cfg.FromReplica != "" || cfg.BeforeGTID != "" || cfg.AfterGTID != "" ||
+			cfg.Serve != "" || cfg.CountOnly || cfg.Contains || cfg.Range || cfg.Stats || cfg.Last || cfg.DetectDuplicates || cfg.ExecutedSet || cfg.TUI || len(cfg.CompareDirs) > 0 {
+			return fmt.Errorf("-list-databases scans every transaction, it cannot be combined with -gtid, -gtid-file, -executed, -from-replica, -before-gtid, -after-gtid, -serve or another mode")
+		}
+		if (cfg.OutputFormat != models.FormatConsole && cfg.OutputFormat != models.FormatJSON) || len(cfg.Fields) > 0 || cfg.Stream {
+			return fmt.Errorf("-list-databases only supports console and json output, without -fields or -stream")
+		}
+	}
+	if cfg.ExecutedSet {
+		if cfg.TargetGTID != "" || cfg.GTIDFile != "" || cfg.ExecutedGTID != "" || cfg.FromReplica != "" || cfg.BeforeGTID != "" || cfg.AfterGTID != "" ||
+			cfg.Serve != "" || cfg.CountOnly || cfg.Contains || cfg.Range || cfg.Stats || cfg.Last || cfg.DetectDuplicates || cfg.ListDatabases || cfg.TUI || len(cfg.CompareDirs) > 0 {
+			return fmt.Errorf("-executed-set reads every GTID, it cannot be combined with -gtid, -gtid-file, -executed, -from-replica, -before-gtid, -after-gtid, -serve or another mode")
+		}
+		if cfg.OutputFormat != models.FormatConsole || cfg.OutputFile != "" || len(cfg.Fields) > 0 || cfg.Stream {
+			return fmt.Errorf("-executed-set only prints the set to stdout, without -format, -output, -fields or -stream")
+		}
+	}
+	if cfg.DetectDuplicates {
+		if cfg.TargetGTID != "" || cfg.GTIDFile != "" || cfg.ExecutedGTID != "" || cfg.FromReplica != "" || cfg.BeforeGTID != "" || cfg.AfterGTID != "" ||
+			cfg.Serve != "" || cfg.CountOnly || cfg.Contains || cfg.Range || cfg.Stats || cfg.Last || cfg.ListDatabases || cfg.ExecutedSet || cfg.TUI || len(cfg.CompareDirs) > 0 {
+			return fmt.Errorf("-detect-duplicates scans every GTID, it cannot be combined with -gtid, -gtid-file, -executed, -from-replica, -before-gtid, -after-gtid, -serve or another mode")
+		}
+		if (cfg.OutputFormat != models.FormatConsole && cfg.OutputFormat != models.FormatJSON) || len(cfg.Fields) > 0 || cfg.Stream {
+			return fmt.Errorf("-detect-duplicates only supports console and json output, without -fields or -stream")
+		}
+	}
+	if cfg.MinGNO > 0 || cfg.MaxGNO > 0 {
+		if batchMode(cfg) || cfg.ExecutedGTID != "" || cfg.FromReplica != "" || cfg.BeforeGTID != "" || cfg.AfterGTID != "" || cfg.Serve != "" ||
+			cfg.CountOnly || cfg.Contains || cfg.Range || cfg.Stats || cfg.Last || cfg.TUI || len(cfg.CompareDirs) > 0 {
+			return fmt.Errorf("-min-gno and -max-gno only apply to a single search, with an optional -gtid set")
+		}
+		if cfg.MaxGNO > 0 && cfg.MinGNO > cfg.MaxGNO {
+			return fmt.Errorf("-min-gno %d is greater than -max-gno %d", cfg.MinGNO, cfg.MaxGNO)
+		}
+		if cfg.TargetGTID == "" && cfg.FindActiveMaster {
+			return fmt.Errorf("-find-active-master requires -gtid")
+		}
+		// The window of a single server is a plain GTID set, there is no set to resolve a prefix in
+		if cfg.TargetGTID == "" && cfg.FilterUUID != "" {
+			if _, err := parser.ParseUUIDList(cfg.FilterUUID); err != nil || strings.Contains(cfg.FilterUUID, ",") {
+				return fmt.Errorf("-uuid must be a full server UUID with -min-gno/-max-gno and no -gtid: %s", cfg.FilterUUID)
+			}
+			maxGNO := uint64(math.MaxInt64 - 1)
+			if cfg.MaxGNO > 0 {
+				maxGNO = cfg.MaxGNO
+			}
+			cfg.TargetGTID = fmt.Sprintf("%s:%d-%d", cfg.FilterUUID, max(cfg.MinGNO, 1), maxGNO)
+		}
+	}
+	if cfg.Raw && (batchMode(cfg) || cfg.Serve != "" || cfg.CountOnly || cfg.Contains || cfg.Range || cfg.Stats || cfg.TUI || len(cfg.CompareDirs) > 0) {
+		return fmt.Errorf("-raw cannot be combined with -gtid-file, several -gtid sets, -serve, -count-only, -contains, -range, -stats, -tui or -compare")
+	}
+	if cfg.Verify && (batchMode(cfg) || cfg.Serve != "" || cfg.CountOnly || cfg.Contains || cfg.Range || cfg.Stats || cfg.TUI) {
+		return fmt.Errorf("-verify cannot be combined with -gtid-file, several -gtid sets, -serve, -count-only, -contains, -range, -stats or -tui")
+	}
+	if cfg.CountOnly && (cfg.OutputFormat != models.FormatConsole || len(cfg.Fields) > 0) {
+		return fmt.Errorf("-count-only only supports console output")
+	}
+	if cfg.Serve != "" && (batchMode(cfg) || cfg.ExecutedGTID != "") {
+		return fmt.Errorf("-serve cannot be combined with -gtid-file, several -gtid sets or -executed")
+	}
+	if cfg.BinlogDir == "" && cfg.S3Source == "" && cfg.SSHSource == "" && cfg.FilesFrom == "" && cfg.Serve == "" && len(cfg.CompareDirs) == 0 && cfg.PosToGTID == "" {
+		return fmt.Errorf("binlog directory is required")
+	}
+	if cfg.FilesFrom != "" && (cfg.BinlogDir != "" || cfg.IndexFile != "" || cfg.S3Source != "" || cfg.Serve != "") {
+		return fmt.Errorf("-files-from cannot be combined with -dir, -index-file, -s3 or -serve")
+	}
+	if cfg.FilePatternRegex != "" {
+		if (cfg.BinlogDir == "" && len(cfg.CompareDirs) == 0) || cfg.IndexFile != "" {
+			return fmt.Errorf("-pattern-regex requires -dir or -compare and cannot be combined with -index-file")
+		}
+		if _, err := regexp.Compile(cfg.FilePatternRegex); err != nil {
+			return fmt.Errorf("invalid -pattern-regex: %v", err)
+		}
+	}
+	if cfg.S3Source != "" {
+		if cfg.BinlogDir != "" || cfg.IndexFile != "" {
+			return fmt.Errorf("-s3 cannot be combined with -dir or -index-file")
+		}
+		if _, _, _, err := searcher.ParseS3URL(cfg.S3Source); err != nil {
+			return err
+		}
+	}
+	if cfg.SSHSource != "" {
+		if cfg.BinlogDir != "" || cfg.IndexFile != "" || cfg.S3Source != "" || cfg.FilesFrom != "" || cfg.Serve != "" {
+			return fmt.Errorf("-ssh cannot be combined with -dir, -index-file, -s3, -files-from or -serve")
+		}
+		if _, _, _, err := searcher.ParseSSHURL(cfg.SSHSource); err != nil {
+			return err
+		}
+	}
+	if cfg.BeforeGTID != "" || cfg.AfterGTID != "" {
+		if (cfg.BeforeGTID != "") == (cfg.AfterGTID != "") {
+			return fmt.Errorf("cannot specify both -before-gtid and -after-gtid")
+		}
+		if cfg.TargetGTID != "" || cfg.GTIDFile != "" || cfg.ExecutedGTID != "" || cfg.Serve != "" ||
+			cfg.CountOnly || cfg.Contains || cfg.Range || cfg.TUI {
+			return fmt.Errorf("-before-gtid and -after-gtid cannot be combined with -gtid, -gtid-file, -executed, -serve, -count-only, -contains, -range or -tui")
+		}
+		gtid := parser.NormalizeGTIDSet(cfg.BeforeGTID + cfg.AfterGTID)
+		if uuidStr, gno, err := parser.ExtractGTIDInfo(gtid); err != nil || !strings.EqualFold(gtid, fmt.Sprintf("%s:%d", uuidStr, gno)) {
+			return fmt.Errorf("-before-gtid and -after-gtid take a single GTID (UUID:N): %s", gtid)
+		}
+	} else if cfg.TargetGTID == "" && cfg.GTIDFile == "" && cfg.ExecutedGTID == "" && cfg.FromReplica == "" && cfg.Serve == "" && !cfg.Last && len(cfg.CompareDirs) == 0 &&
+		cfg.MinGNO == 0 && cfg.MaxGNO == 0 && cfg.PosToGTID == "" && !cfg.DetectDuplicates && !cfg.ListDatabases && !cfg.ExecutedSet && cfg.Expr == "" {
+		return fmt.Errorf("either -gtid, -gtid-file, -executed, -from-replica, -last, -expr, -pos-to-gtid, -detect-duplicates, -list-databases, -executed-set or -min-gno/-max-gno is required")
+	}
+	if cfg.EndGTID != "" {
+		if (cfg.TargetGTID == "" && cfg.GTIDFile == "") || cfg.ExecutedGTID != "" || cfg.FromReplica != "" || cfg.Serve != "" || cfg.Contains {
+			return fmt.Errorf("-end-gtid requires -gtid or -gtid-file and cannot be combined with -executed, -from-replica, -serve or -contains")
+		}
+		gtid := parser.NormalizeGTIDSet(cfg.EndGTID)
+		if uuidStr, gno, err := parser.ExtractGTIDInfo(gtid); err != nil || !strings.EqualFold(gtid, fmt.Sprintf("%s:%d", uuidStr, gno)) {
+			return fmt.Errorf("-end-gtid takes a single GTID (UUID:N): %s", gtid)
+		}
+		cfg.EndGTID = strings.ToLower(gtid)
+	}
+	if cfg.ExecutedGTID != "" && batchMode(cfg) {
+		return fmt.Errorf("cannot specify both -executed and -gtid-file or several -gtid sets")
+	}
+	if cfg.TargetGTID != "" && cfg.GTIDFile != "" {
+		return fmt.Errorf("cannot specify both -gtid and -gtid-file")
+	}
+	if cfg.OutputFormat == models.FormatCanal && (batchMode(cfg) || cfg.Serve != "" || cfg.Stream) {
+		return fmt.Errorf("-format canal cannot be combined with -gtid-file, several -gtid sets, -serve or -stream")
+	}
+	if cfg.OutputFormat == models.FormatCloneSQL && (batchMode(cfg) || cfg.Serve != "" || cfg.Stream) {
+		return fmt.Errorf("-format clone-sql cannot be combined with -gtid-file, several -gtid sets, -serve or -stream")
+	}
+	if cfg.Checkpoint != "" && !batchMode(cfg) {
+		return fmt.Errorf("-checkpoint requires -gtid-file or several -gtid sets")
+	}
+	if cfg.ContinueOnError && !batchMode(cfg) {
+		return fmt.Errorf("-continue-on-error requires -gtid-file or several -gtid sets")
+	}
+	if cfg.Precheck && (cfg.Serve != "" || cfg.TUI) {
+		return fmt.Errorf("-precheck cannot be combined with -serve or -tui")
+	}
+	if cfg.Strict && !cfg.Precheck {
+		return fmt.Errorf("-strict requires -precheck")
+	}
+	if cfg.Dedupe && !batchMode(cfg) {
+		return fmt.Errorf("-dedupe requires -gtid-file or several -gtid sets")
+	}
+	if cfg.SummaryOnly {
+		if !batchMode(cfg) || cfg.TUI {
+			return fmt.Errorf("-summary-only requires -gtid-file or several -gtid sets and cannot be combined with -tui")
+		}
+		if cfg.OutputFormat != models.FormatConsole || cfg.OutputFile != "" || cfg.OutputDir != "" || cfg.Stream || len(cfg.Fields) > 0 {
+			return fmt.Errorf("-summary-only replaces the output, it cannot be combined with -format, -output, -output-dir, -stream or -fields")
+		}
+	}
+	if cfg.OutputDir != "" {
+		if !batchMode(cfg) || cfg.OutputFile != "" {
+			return fmt.Errorf("-output-dir requires -gtid-file or several -gtid sets and cannot be combined with -output")
+		}
+		if cfg.OutputFormat != models.FormatCSV && cfg.OutputFormat != models.FormatJSON {
+			return fmt.Errorf("-output-dir requires -format csv or json")
+		}
+	}
+	if info, err := os.Stat(cfg.BinlogDir); cfg.BinlogDir != "" && os.IsNotExist(err) {
+		return fmt.Errorf("binlog directory does not exist: %s", cfg.BinlogDir)
+	} else if err == nil && !info.IsDir() && (cfg.IndexFile != "" || cfg.FilePatternRegex != "" || cfg.StartFile != "" || cfg.BeforeFile != "") {
+		// A single binlog file is scanned as is, there is nothing to select files from
+		return fmt.Errorf("-dir %s is a file, it cannot be combined with -index-file, -pattern-regex, -start-file or -before-file", cfg.BinlogDir)
+	}
+	if cfg.Recent < 0 {
+		return fmt.Errorf("invalid -recent (must be a positive number of files)")
+	}
+	if cfg.Parallel < 0 {
+		return fmt.Errorf("invalid -parallel (must be a positive number or auto)")
+	}
+	if cfg.MaxGlobalParallel < 0 {
+		return fmt.Errorf("invalid -max-global-parallel (must be a positive number)")
+	}
+	if cfg.MaxGlobalParallel > 0 && cfg.BinlogDir == "" {
+		return fmt.Errorf("-max-global-parallel requires -dir, the lock file is kept in the binlog directory")
+	}
+	switch cfg.Storage {
+	case searcher.StorageAuto, searcher.StorageSSD, searcher.StorageHDD, searcher.StorageNFS:
+	default:
+		return fmt.Errorf("invalid storage: %s (must be auto, ssd, hdd or nfs)", cfg.Storage)
+	}
+	if cfg.Timeout < 0 {
+		return fmt.Errorf("timeout must not be negative: %s", cfg.Timeout)
+	}
+	if cfg.LogFormat != models.LogFormatText && cfg.LogFormat != models.LogFormatJSON {
+		return fmt.Errorf("invalid log format: %s (must be text or json)", cfg.LogFormat)
+	}
+	if !cfg.Selection.IsValid() {
+		return fmt.Errorf("invalid selection: %s (must be highest-gno, first, or last)", cfg.Selection)
+	}
+	if !cfg.Sort.IsValid() {
+		return fmt.Errorf("invalid sort order: %s (must be position, timestamp or gno)", cfg.Sort)
+	}
+	if cfg.Sort != "" && (cfg.Stream || cfg.OutputDir != "" || cfg.SummaryOnly) {
+		return fmt.Errorf("-sort orders the combined output at the end, it cannot be combined with -stream, -output-dir or -summary-only")
+	}
+	if cfg.FilterForDisplayOnly && cfg.FilterDatabase == "" && cfg.FilterTable == "" {
+		return fmt.Errorf("-filter-for-display-only requires -database or -table")
+	}
+	if cfg.Bisect && (cfg.Selection.IsOrdered() || cfg.OutputFormat == models.FormatCloneSQL) {
+		return fmt.Errorf("-bisect only supports -select highest-gno and cannot be combined with -format clone-sql (bisected matches have no executed set)")
+	}
+	if !cfg.OutputFormat.IsValid() {
+		return fmt.Errorf("invalid output format: %s (must be console, csv, json, json-array, canal, clone-sql or template)", cfg.OutputFormat)
+	}
+	if len(cfg.AllowedUUIDs) > 0 {
+		uuids, err := parser.ParseUUIDList(strings.Join(cfg.AllowedUUIDs, ","))
+		if err != nil {
+			return fmt.Errorf("invalid -allowed-uuids: %v", err)
+		}
+		cfg.AllowedUUIDs = uuids
+	}
+	if len(cfg.Fields) > 0 {
+		if cfg.OutputFormat != models.FormatJSON && cfg.OutputFormat != models.FormatJSONArray && cfg.OutputFormat != models.FormatCSV {
+			return fmt.Errorf("-fields requires -format json, json-array or csv")
+		}
+		fields, err := exporter.ParseFields(strings.Join(cfg.Fields, ","))
+		if err != nil {
+			return fmt.Errorf("invalid -fields: %v", err)
+		}
+		cfg.Fields = fields
+	}
+	return nil
+}
+
+// listBinlogFiles discovers binlog files and applies the -start-file and -before-file filters
+func listBinlogFiles(s *searcher.Searcher, cfg *models.Config) ([]string, error) {
+	binlogFiles, err := s.ListBinlogFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.StartFile != "" && cfg.Verbose {
+		fmt.Fprintf(status, "%s Starting from file: %s (%d files to scan)\n", emoji.Directory, cfg.StartFile, len(binlogFiles))
+	}
+	if cfg.BeforeFile != "" && cfg.Verbose {
+		fmt.Fprintf(status, "%s Stopping before file: %s (%d files to scan)\n", emoji.Directory, cfg.BeforeFile, len(binlogFiles))
+	}
+	if cfg.Recent > 0 && cfg.Verbose {
+		fmt.Fprintf(status, "%s Searching the newest %d files (%d files to scan)\n", emoji.Directory, cfg.Recent, len(binlogFiles))
+	}
+
+	fmt.Fprintf(status, "%s Found %d binlog files\n", emoji.List, len(binlogFiles))
+
+	if cfg.Precheck {
+		issues := s.Precheck(binlogFiles)
+		printPrecheck(issues, len(binlogFiles))
+		if len(issues) > 0 && cfg.Strict {
+			return nil, fmt.Errorf("precheck found %d issues (-strict)", len(issues))
+		}
+	}
+
+	return binlogFiles, nil
+}
+
+// printPrecheck prints the precheck issues as a table
+func printPrecheck(issues []searcher.FileIssue, files int) {
+	if len(issues) == 0 {
+		fmt.Fprintf(status, "%s Precheck: %d files OK\n", emoji.Precheck, files)
+		return
+	}
+
+	fmt.Fprintf(status, "%s Precheck: %d issues in %d files\n", emoji.Precheck, len(issues), files)
+	table := tabwriter.NewWriter(status, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "  FILE\tISSUE\tDETAIL")
+	for _, issue := range issues {
+		fmt.Fprintf(table, "  %s\t%s\t%s\n", filepath.Base(issue.File), issue.Kind, issue.Detail)
+	}
+	table.Flush()
+}
+
+// newSearchResult wraps the position found by s over files with the scan metadata
+func newSearchResult(s *searcher.Searcher, files []string, position *models.GTIDPosition) *models.SearchResult {
+	result := &models.SearchResult{
+		TotalFiles:    len(files),
+		ScannedFiles:  s.ScannedFiles(),
+		ServerVersion: s.ServerVersion(),
+	}
+	if position != nil {
+		result.Positions = []*models.GTIDPosition{position}
+	}
+	return result
+}
+
+func findGTIDPosition(cfg *models.Config) (*models.SearchResult, error) {
+	// Create searcher
+	s, err := searcher.NewSearcherForSource(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	binlogFiles, err := listBinlogFiles(s, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse target GTID; a GNO window alone matches any GTID in it
+	var targetGTID mysql.GTIDSet = &mysql.MysqlGTIDSet{Sets: make(map[string]*mysql.UUIDSet)}
+	if cfg.TargetGTID != "" {
+		targetGTID, err = parser.ParseGTID(cfg.TargetGTID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GTID format: %v", err)
+		}
+	}
+
+	// Handle active master detection
+	if cfg.FindActiveMaster {
+		activeMasterUUID, err := parser.FindActiveMasterUUID(&targetGTID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find active master: %v", err)
+		}
+		fmt.Fprintf(status, "%s Active master UUID detected: %s\n", emoji.Target, activeMasterUUID)
+		cfg.FilterUUID = activeMasterUUID
+	}
+
+	// Filter by UUID if specified
+	if cfg.FilterUUID != "" {
+		fmt.Fprintf(status, "%s Filtering by UUID: %s\n", emoji.Search, cfg.FilterUUID)
+		targetGTID, err = parser.FilterByUUID(&targetGTID, cfg.FilterUUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter by UUID: %v", err)
+		}
+	}
+
+	// Show GTID info if verbose
+	if cfg.Verbose {
+		uuidInfos, _ := parser.ExtractUUIDs(&targetGTID)
+		fmt.Fprintf(status, "\n%s GTID Set Information:\n", emoji.Info)
+		for _, info := range uuidInfos {
+			fmt.Fprintf(status, "  UUID: %s\n", info.UUID)
+			fmt.Fprintf(status, "    Transactions: %d-%d (total: %d)\n", 
+				info.MinTransaction, info.MaxTransaction, info.TotalCount)
+		}
+		fmt.Fprintln(status)
+	}
+
+	// No point scanning when every target GTID was purged before the earliest binlog
+	if err := s.CheckPurged(binlogFiles, &targetGTID); err != nil {
+		return nil, err
+	}
+
+	// Search in parallel
+	position, err := s.SearchParallel(context.Background(), binlogFiles, &targetGTID)
+	search := newSearchResult(s, binlogFiles, position)
+
+	// Target UUIDs that never showed up cannot match, likely a typo or the wrong cluster
+	if err == nil {
+		search.UnseenUUIDs = s.UnseenUUIDs(&targetGTID)
+		if len(search.UnseenUUIDs) > 0 {
+			fmt.Fprintf(os.Stderr, "%s Target UUIDs not in any scanned binlog: %s\n", emoji.Warning, strings.Join(search.UnseenUUIDs, ", "))
+		}
+		if position == nil {
+			search.NotFoundReason = s.NotFoundReason(&targetGTID)
+		}
+	}
+	return search, err
+}
+
+// notFoundHint explains a not-found reason and what to try next
+func notFoundHint(reason models.NotFoundReason) string {
+	switch reason {
+	case models.NotFoundPurged:
+		return "The GTID was purged, it is only in the headers of the scanned binlogs"
+	case models.NotFoundUnseenUUID:
+		return "No scanned binlog has a transaction of this server UUID, check the UUID and the binlog directory"
+	case models.NotFoundFuture:
+		return "The GTID is past the last transaction of its server, it has not been executed yet"
+	case models.NotFoundFiltered:
+		return "The GTID was executed but excluded by the filters (time, database, table, UUID or GNO window)"
+	case models.NotFoundAbsent:
+		return "The GTID falls in a gap of the executed set, no scanned binlog has it"
+	default:
+		return ""
+	}
+}
+
+// verifyPosition checks the position the replica would be pointed at: the start of
+// the first missing transaction with -executed, the resume position otherwise
+func verifyPosition(cfg *models.Config, position *models.GTIDPosition) error {
+	s, err := searcher.NewSearcherForSource(context.Background(), cfg)
+	if err != nil {
+		return err
+	}
+
+	file, pos := position.ResumeBinlogFile(), position.ResumePosition
+	if cfg.ExecutedGTID != "" {
+		file, pos = position.BinlogFile, position.Position
+	}
+	if err := s.VerifyPosition(file, pos); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(status, "%s Verified: %s:%d is an event boundary\n", emoji.Verified, filepath.Base(file), pos)
+	return nil
+}
+
+// rawDumpBytes is how much of each event -raw prints
+const rawDumpBytes = 64
+
+// dumpRawEvents prints the GTID and commit events of a found transaction in hex
+// to stderr, with their offset and length
+func dumpRawEvents(cfg *models.Config, position *models.GTIDPosition) error {
+	s, err := searcher.NewSearcherForSource(context.Background(), cfg)
+	if err != nil {
+		return err
+	}
+
+	events, err := s.RawEvents(position)
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		fmt.Fprintf(os.Stderr, "%s %s at %s:%d, %d bytes\n", emoji.Raw, event.Type, filepath.Base(position.BinlogFile), event.Offset, len(event.Data))
+		fmt.Fprint(os.Stderr, hex.Dump(event.Data[:min(len(event.Data), rawDumpBytes)]))
+	}
+	return nil
+}
+
+// browsePositions finds the position of -gtid, or of each set of a batch, and shows
+// them in the interactive table
+func browsePositions(cfg *models.Config) error {
+	targets := []string{cfg.TargetGTID}
+	if batchMode(cfg) {
+		gtidSets, _, err := batchGTIDSets(cfg)
+		if err != nil {
+			return err
+		}
+		targets = targets[:0]
+		for _, gtidSet := range gtidSets {
+			targets = append(targets, gtidSet.String())
+		}
+	}
+
+	var positions []*models.GTIDPosition
+	for i, target := range targets {
+		entry := *cfg
+		entry.TargetGTID = target
+
+		position, err := searcher.Find(context.Background(), &entry)
+
+		var timeoutErr *searcher.TimeoutError
+		if errors.As(err, &timeoutErr) {
+			fmt.Fprintf(os.Stderr, "%s [%d/%d] %s: search %v\n", emoji.Timeout, i+1, len(targets), target, timeoutErr)
+		} else if err != nil {
+			return fmt.Errorf("entry %d (%s): %v", i+1, target, err)
+		}
+
+		if position == nil {
+			fmt.Fprintf(os.Stderr, "%s [%d/%d] %s: not found\n", emoji.NotFound, i+1, len(targets), target)
+			continue
+		}
+		positions = append(positions, position)
+	}
+
+	if len(positions) == 0 {
+		return fmt.Errorf("GTID not found in binlog files")
+	}
+	cfg.Sort.Sort(positions, nil)
+	return tui.Run(positions, cfg.TimeFormat)
+}
+
+// findNeighborPosition locates the committed transaction just before -before-gtid
+// or just after -after-gtid
+func findNeighborPosition(cfg *models.Config) (*models.SearchResult, error) {
+	s, err := searcher.NewSearcherForSource(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	binlogFiles, err := listBinlogFiles(s, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	gtid, after := cfg.BeforeGTID, false
+	if cfg.AfterGTID != "" {
+		gtid, after = cfg.AfterGTID, true
+	}
+
+	position, err := s.FindNeighbor(context.Background(), binlogFiles, parser.NormalizeGTIDSet(gtid), after)
+	return newSearchResult(s, binlogFiles, position), err
+}
+
+// findLastPosition locates the newest transaction of the binlogs
+func findLastPosition(cfg *models.Config) (*models.SearchResult, error) {
+	s, err := searcher.NewSearcherForSource(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	binlogFiles, err := listBinlogFiles(s, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	position, err := s.LastGTID(context.Background(), binlogFiles)
+	return newSearchResult(s, binlogFiles, position), err
+}
+
+// findExprPosition locates the first transaction after which the -expr expression holds
+func findExprPosition(cfg *models.Config) (*models.SearchResult, error) {
+	expr, err := parser.ParseGTIDExpr(cfg.Expr)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := searcher.NewSearcherForSource(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	binlogFiles, err := listBinlogFiles(s, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	position, err := s.SearchExpr(context.Background(), binlogFiles, expr)
+	return newSearchResult(s, binlogFiles, position), err
+}
+
+// parseFilePosition splits a -pos-to-gtid value, file:pos, at its last colon
+func parseFilePosition(value string) (string, uint32, error) {
+	i := strings.LastIndex(value, ":")
+	if i <= 0 {
+		return "", 0, fmt.Errorf("invalid -pos-to-gtid (must be file:pos): %s", value)
+	}
+	pos, err := strconv.ParseUint(value[i+1:], 10, 32)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid -pos-to-gtid position %q: %v", value[i+1:], err)
+	}
+	return value[:i], uint32(pos), nil
+}
+
+// gtidAtPosition reports the GTID set executed up to the -pos-to-gtid position
+func gtidAtPosition(cfg *models.Config) error {
+	file, pos, err := parseFilePosition(cfg.PosToGTID)
+	if err != nil {
+		return err
+	}
+	if cfg.BinlogDir != "" && !strings.ContainsRune(file, filepath.Separator) {
+		file = filepath.Join(cfg.BinlogDir, file)
+	}
+	fmt.Fprintf(status, "%s Binlog position: %s:%d\n", emoji.Position, file, pos)
+	fmt.Fprintln(status, strings.Repeat("-", 60))
+
+	executed, lastGTID, err := searcher.NewSearcher(cfg).GTIDAtPosition(context.Background(), file, pos)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Executed GTID set: %s\n", executed)
+	if lastGTID != "" {
+		fmt.Printf("Last GTID:         %s\n", lastGTID)
+	} else {
+		fmt.Println("Last GTID:         (none, no transaction of the file committed by this position)")
+	}
+	return nil
+}
+
+// compareDirectories reports the transactions each -compare directory has executed
+// that the other has not, and whether both cover the same GTIDs
+func compareDirectories(cfg *models.Config) (bool, error) {
+	fmt.Fprintf(status, "%s Comparing binlog directories: %s %s %s\n", emoji.Compare, cfg.CompareDirs[0], emoji.BothWays, cfg.CompareDirs[1])
+	fmt.Fprintln(status, strings.Repeat("-", 60))
+
+	// One deadline for both directories
+	ctx := context.Background()
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+
+	executed := make([]mysql.GTIDSet, len(cfg.CompareDirs))
+	for i, dir := range cfg.CompareDirs {
+		dirCfg := *cfg
+		dirCfg.BinlogDir = dir
+
+		s, err := searcher.NewSearcherForSource(context.Background(), &dirCfg)
+		if err != nil {
+			return false, err
+		}
+		binlogFiles, err := listBinlogFiles(s, &dirCfg)
+		if err != nil {
+			return false, err
+		}
+		set, err := s.ExecutedSet(ctx, binlogFiles)
+		if err != nil {
+			return false, err
+		}
+		fmt.Fprintf(status, "%s %s: %s\n", emoji.GTIDSet, dir, set.String())
+		executed[i] = set
+	}
+
+	extra := make([]mysql.GTIDSet, len(executed))
+	for i := range executed {
+		diff, err := parser.SubtractGTIDSet(&executed[i], &executed[1-i])
+		if err != nil {
+			return false, err
+		}
+		extra[i] = diff
+	}
+
+	fmt.Println(strings.Repeat("-", 60))
+	for i, dir := range cfg.CompareDirs {
+		if extra[i].String() != "" {
+			fmt.Printf("%s %s only: %s\n", emoji.Extra, dir, extra[i].String())
+		}
+	}
+	fmt.Printf("%s has %d extra, %s has %d extra.\n",
+		cfg.CompareDirs[0], transactionCount(extra[0]), cfg.CompareDirs[1], transactionCount(extra[1]))
+
+	return extra[0].String() == "" && extra[1].String() == "", nil
+}
+
+// transactionCount returns the number of transactions in a GTID set
+func transactionCount(set mysql.GTIDSet) uint64 {
+	infos, _ := parser.ExtractUUIDs(&set)
+
+	var total uint64
+	for _, info := range infos {
+		total += info.TotalCount
+	}
+	return total
+}
+
+// findPositionRange locates the range of binlog positions holding the target set
+func findPositionRange(cfg *models.Config) (*models.PositionRange, error) {
+	s, err := searcher.NewSearcherForSource(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	binlogFiles, err := listBinlogFiles(s, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	targetGTID, err := parser.ParseGTID(cfg.TargetGTID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GTID format: %v", err)
+	}
+
+	if cfg.FindActiveMaster {
+		activeMasterUUID, err := parser.FindActiveMasterUUID(&targetGTID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find active master: %v", err)
+		}
+		fmt.Fprintf(status, "%s Active master UUID detected: %s\n", emoji.Target, activeMasterUUID)
+		cfg.FilterUUID = activeMasterUUID
+	}
+
+	if cfg.FilterUUID != "" {
+		fmt.Fprintf(status, "%s Filtering by UUID: %s\n", emoji.Search, cfg.FilterUUID)
+		targetGTID, err = parser.FilterByUUID(&targetGTID, cfg.FilterUUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter by UUID: %v", err)
+		}
+	}
+
+	if err := s.CheckPurged(binlogFiles, &targetGTID); err != nil {
+		return nil, err
+	}
+
+	return s.FindRange(context.Background(), binlogFiles, &targetGTID)
+}
+
+// exportRange writes a position range in the console or json format
+func exportRange(positionRange *models.PositionRange, cfg *models.Config, elapsed time.Duration) error {
+	fmt.Fprintln(status, strings.Repeat("-", 60))
+	fmt.Fprintf(status, "%s Found GTID range in %.2f seconds\n", emoji.OK, elapsed.Seconds())
+
+	if cfg.OutputFormat == models.FormatJSON {
+		fmt.Fprintln(status, strings.Repeat("-", 60))
+		return exporter.NewJSONExporter(true).ExportRange(positionRange, cfg.OutputFile)
+	}
+	return exporter.NewConsoleExporter().ExportRange(positionRange)
+}
+
+// errNothingMissing is returned by findFirstMissingPosition when the replica has executed
+// every GTID of the master, so there is no position to look for
+var errNothingMissing = errors.New("replica has executed all GTIDs of the master")
+
+// findFirstMissingPosition locates the first transaction in the binlogs that the
+// replica (executed set) has not applied yet
+func findFirstMissingPosition(cfg *models.Config) (*models.SearchResult, error) {
+	s, err := searcher.NewSearcherForSource(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	binlogFiles, err := listBinlogFiles(s, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	executed, err := parser.ParseGTID(cfg.ExecutedGTID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid executed GTID set: %v", err)
+	}
+
+	// Compute missing = master \ replica when the master's set is known
+	var missing *mysql.GTIDSet
+	if cfg.TargetGTID != "" {
+		master, err := parser.ParseGTID(cfg.TargetGTID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GTID format: %v", err)
+		}
+
+		diff, err := parser.SubtractGTIDSet(&master, &executed)
+		if err != nil {
+			return nil, err
+		}
+		if diff.(*mysql.MysqlGTIDSet).IsEmpty() {
+			return nil, errNothingMissing
+		}
+
+		fmt.Fprintf(status, "%s Missing GTIDs: %s\n", emoji.Missing, diff.String())
+		missing = &diff
+	}
 
-	if err := validateConfig(cfg); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+	position, err := s.FindFirstMissing(context.Background(), binlogFiles, &executed, missing)
+	return newSearchResult(s, binlogFiles, position), err
+}
+
+// newStreamWriter returns the exporter stream for cfg's output format. JSON output is only
+// streamed (as NDJSON) with -stream; otherwise nil is returned and the caller exports
+// the enveloped JSON once all positions are known.
+func newStreamWriter(cfg *models.Config) (exporter.StreamWriter, error) {
+	switch cfg.OutputFormat {
+	case models.FormatCSV:
+		exp := exporter.NewCSVExporter()
+		exp.Fields = cfg.Fields
+		exp.TimeFormat = cfg.TimeFormat
+		if cfg.Stream {
+			exp.FlushEvery = 1
+		}
+		return exp.Stream(cfg.OutputFile)
+
+	case models.FormatJSON, models.FormatJSONArray:
+		if !cfg.Stream {
+			return nil, nil
+		}
+		exp := exporter.NewJSONExporter(false)
+		exp.Fields = cfg.Fields
+		exp.Bare = cfg.OutputFormat == models.FormatJSONArray
+		return exp.Stream(cfg.OutputFile)
+
+	case models.FormatTemplate:
+		exp, err := exporter.NewTemplateExporter(cfg.OutputTemplate)
+		if err != nil {
+			return nil, err
+		}
+		return exp.Stream(cfg.OutputFile)
+
+	default:
+		exp := exporter.NewConsoleExporter()
+		exp.TimeFormat = cfg.TimeFormat
+		return exp.Stream(), nil
 	}
+}
 
-	start := time.Now()
-	fmt.Printf("🔍 Searching for GTID: %s\n", cfg.TargetGTID)
-	fmt.Printf("📂 Binlog directory: %s\n", cfg.BinlogDir)
-	fmt.Printf("📊 Output format: %s\n", cfg.OutputFormat)
-	fmt.Println(strings.Repeat("-", 60))
+// batchMode reports whether several GTID sets are searched one by one: those of
+// -gtid-file, or of a -gtid holding ";"-separated sets
+func batchMode(cfg *models.Config) bool {
+	return cfg.GTIDFile != "" || strings.Contains(cfg.TargetGTID, parser.GTIDListSeparator)
+}
+
+// batchGTIDSets returns the GTID sets of a batch, in order, and how many
+// duplicates -dedupe dropped
+func batchGTIDSets(cfg *models.Config) ([]mysql.GTIDSet, int, error) {
+	var gtidSets []mysql.GTIDSet
+	var err error
+	if cfg.GTIDFile != "" {
+		gtidSets, err = parser.ParseGTIDFile(cfg.GTIDFile)
+	} else {
+		gtidSets, err = parser.ParseGTIDList(cfg.TargetGTID)
+	}
+	if err != nil || !cfg.Dedupe {
+		return gtidSets, 0, err
+	}
+
+	gtidSets, duplicates := parser.DedupeGTIDSets(gtidSets)
+	fmt.Fprintf(status, "%s Skipped %d duplicate GTID sets\n", emoji.Dedupe, duplicates)
+	return gtidSets, duplicates, nil
+}
+
+// runBatch resolves every GTID set of a batch in turn, writing each position
+// as soon as it is found. It returns how many sets were found.
+func runBatch(cfg *models.Config, start time.Time) (int, error) {
+	// One deadline for the whole batch, -timeout does not restart for each entry
+	ctx := context.Background()
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
 
-	result, err := findGTIDPosition(cfg)
+	s, err := searcher.NewSearcherForSource(context.Background(), cfg)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
-		os.Exit(1)
+		return 0, err
 	}
 
-	if result == nil {
-		fmt.Println("❌ GTID not found in binlog files")
-		os.Exit(1)
+	binlogFiles, err := listBinlogFiles(s, cfg)
+	if err != nil {
+		return 0, err
 	}
 
-	elapsed := time.Since(start)
-	
-	// Export result based on format
-	if err := exportResult(result, cfg, elapsed); err != nil {
-		fmt.Fprintf(os.Stderr, "❌ Export error: %v\n", err)
-		os.Exit(1)
+	gtidSets, duplicates, err := batchGTIDSets(cfg)
+	if err != nil {
+		return 0, err
 	}
-}
+	fmt.Fprintf(status, "%s Batch of %d GTID sets\n", emoji.List, len(gtidSets))
+	printFilterNotice(cfg)
 
-func parseFlags() *models.Config {
-	cfg := &models.Config{}
+	var cp *checkpoint
+	if cfg.Checkpoint != "" {
+		cp, err = openCheckpoint(cfg.Checkpoint)
+		if err != nil {
+			return 0, err
+		}
+		defer cp.Close()
+		fmt.Fprintf(status, "%s Checkpoint: %s (%d entries resolved)\n", emoji.Checkpoint, cfg.Checkpoint, len(cp.resolved))
+	}
 
-	var formatStr string
-	var startTimeStr, endTimeStr string
+	// Combined output, unless every position gets its own file
+	var stream exporter.StreamWriter
+	if cfg.OutputDir != "" {
+		if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
+			return 0, fmt.Errorf("failed to create output directory: %w", err)
+		}
+	} else if !cfg.SummaryOnly {
+		stream, err = newStreamWriter(cfg)
+		if err != nil {
+			return 0, err
+		}
+	}
 
-	flag.StringVar(&cfg.BinlogDir, "dir", "", "Binlog directory path (required)")
-	flag.StringVar(&cfg.TargetGTID, "gtid", "", "Target GTID to find (required)")
-	flag.StringVar(&cfg.GTIDFile, "gtid-file", "", "File containing multiple GTIDs (one per line)")
-	flag.StringVar(&cfg.FilePattern, "pattern", "mysql-bin.*", "Binlog file pattern")
-	flag.StringVar(&cfg.StartFile, "start-file", "", "Start searching from this binlog file (e.g., mysql-bin.000100)")
-	flag.IntVar(&cfg.Parallel, "parallel", 4, "Number of parallel workers")
-	flag.BoolVar(&cfg.Verbose, "verbose", false, "Verbose output")
-	flag.StringVar(&formatStr, "format", "console", "Output format: console, csv, json")
-	flag.StringVar(&cfg.OutputFile, "output", "", "Output file (default: stdout)")
-	flag.BoolVar(&cfg.FindActiveMaster, "find-active-master", false, "Auto-detect and search for active master UUID (highest GNO)")
-	flag.StringVar(&cfg.FilterUUID, "uuid", "", "Filter search by specific server UUID")
-	flag.StringVar(&cfg.FilterDatabase, "database", "", "Filter search by database name")
-	flag.StringVar(&startTimeStr, "start-time", "", "Filter events after this time (format: 2006-01-02 15:04:05 or RFC3339)")
-	flag.StringVar(&endTimeStr, "end-time", "", "Filter events before this time (format: 2006-01-02 15:04:05 or RFC3339)")
-	flag.BoolVar(&cfg.FindAll, "find-all", false, "Find all GTIDs in range (not just first match)")
+	var positions []*models.GTIDPosition // Enveloped JSON output, written at the end
+	var failures []*models.EntryFailure
 
-	flag.Parse()
+	// Per-entry progress, left out of a summary-only run
+	var entries io.Writer = os.Stderr
+	if cfg.SummaryOnly {
+		entries = io.Discard
+	}
 
-	// Parse format
-	cfg.OutputFormat = models.ExportFormat(formatStr)
+	summary := &models.BatchSummary{Total: len(gtidSets), Duplicates: duplicates}
+	fileOrder := make(map[string]int, len(binlogFiles))
+	for i, file := range binlogFiles {
+		fileOrder[file] = i
+	}
+	firstFile, lastFile := len(binlogFiles), -1
 
-	// Parse time filters
-	if startTimeStr != "" {
-		if t, err := parseTimeString(startTimeStr); err == nil {
-			cfg.StartTime = t
-		} else {
-			fmt.Fprintf(os.Stderr, "Warning: Invalid start-time format: %v\n", err)
+	// fail stops the batch on an entry error, or with -continue-on-error reports the
+	// entry as not found and records the failure
+	fail := func(i int, gtid string, err error) error {
+		if !cfg.ContinueOnError {
+			return fmt.Errorf("entry %d (%s): %v", i+1, gtid, err)
+		}
+		fmt.Fprintf(entries, "%s [%d/%d] %s: not found, %v\n", emoji.NotFound, i+1, len(gtidSets), gtid, err)
+		failures = append(failures, &models.EntryFailure{Entry: i + 1, GTID: gtid, Error: err.Error()})
+		summary.Failed++
+		if cp != nil {
+			return cp.RecordFailure(gtid, err)
 		}
+		return nil
 	}
-	if endTimeStr != "" {
-		if t, err := parseTimeString(endTimeStr); err == nil {
-			cfg.EndTime = t
-		} else {
-			fmt.Fprintf(os.Stderr, "Warning: Invalid end-time format: %v\n", err)
+
+	// emit outputs a found position of the input set gtid
+	emit := func(gtid string, position *models.GTIDPosition) error {
+		positions = append(positions, position)
+		if idx, ok := fileOrder[position.BinlogFile]; ok {
+			firstFile, lastFile = min(firstFile, idx), max(lastFile, idx)
+		}
+		if cfg.SummaryOnly {
+			return nil
+		}
+		if cfg.OutputDir != "" {
+			search := &models.SearchResult{TotalFiles: len(binlogFiles), Positions: []*models.GTIDPosition{position}}
+			return exportToOutputDir(cfg, gtid, search)
+		}
+		if stream != nil && cfg.Sort == "" {
+			return stream.Write(position)
+		}
+		return nil
+	}
+
+	for i, gtidSet := range gtidSets {
+		// Resolved by a previous run: output the recorded result without scanning
+		if cp != nil {
+			if entry, ok := cp.Lookup(gtidSet.String()); ok {
+				if entry.Error != "" {
+					fmt.Fprintf(entries, "%s [%d/%d] %s: not found, %s (checkpoint)\n", emoji.Resolved, i+1, len(gtidSets), gtidSet, entry.Error)
+					failures = append(failures, &models.EntryFailure{Entry: i + 1, GTID: entry.GTID, Error: entry.Error})
+					summary.Failed++
+					continue
+				}
+				if entry.Position == nil {
+					fmt.Fprintf(entries, "%s [%d/%d] %s: not found (checkpoint)\n", emoji.Resolved, i+1, len(gtidSets), gtidSet)
+					summary.NotFound++
+					continue
+				}
+				summary.Found++
+				fmt.Fprintf(entries, "%s [%d/%d] %s: %s:%d (checkpoint)\n", emoji.Resolved, i+1, len(gtidSets), gtidSet, filepath.Base(entry.Position.ResumeBinlogFile()), entry.Position.ResumePosition)
+				if err := emit(gtidSet.String(), entry.Position); err != nil {
+					return len(positions), fmt.Errorf("export error: %v", err)
+				}
+				continue
+			}
+		}
+
+		target := gtidSet
+		filterUUID := cfg.FilterUUID
+		if cfg.FindActiveMaster {
+			filterUUID, err = parser.FindActiveMasterUUID(&target)
+			if err != nil {
+				if err := fail(i, gtidSet.String(), fmt.Errorf("failed to find active master: %v", err)); err != nil {
+					return len(positions), err
+				}
+				continue
+			}
+		}
+		if filterUUID != "" {
+			target, err = parser.FilterByUUID(&target, filterUUID)
+			if err != nil {
+				if err := fail(i, gtidSet.String(), fmt.Errorf("failed to filter by UUID: %v", err)); err != nil {
+					return len(positions), err
+				}
+				continue
+			}
+		}
+
+		// Progress goes to stderr so streamed stdout output stays parseable
+		position, err := s.SearchParallel(ctx, binlogFiles, &target)
+
+		var timeoutErr *searcher.TimeoutError
+		if errors.As(err, &timeoutErr) {
+			fmt.Fprintf(entries, "%s [%d/%d] %s: search %v\n", emoji.Timeout, i+1, len(gtidSets), gtidSet, timeoutErr)
+			summary.TimedOut++
+		} else if err != nil {
+			if err := fail(i, gtidSet.String(), err); err != nil {
+				return len(positions), err
+			}
+			continue
+		} else if cp != nil {
+			// Timed out entries are not resolved, a restarted run searches them again
+			if err := cp.Record(gtidSet.String(), position); err != nil {
+				return len(positions), err
+			}
+		}
+
+		if unseen := s.UnseenUUIDs(&target); err == nil && len(unseen) > 0 {
+			fmt.Fprintf(entries, "%s [%d/%d] %s: UUIDs not in any scanned binlog: %s\n", emoji.Warning, i+1, len(gtidSets), gtidSet, strings.Join(unseen, ", "))
+		}
+		if position == nil {
+			if err == nil {
+				fmt.Fprintf(entries, "%s [%d/%d] %s: not found\n", emoji.NotFound, i+1, len(gtidSets), gtidSet)
+
+				// Only told apart in the summary, it costs a header read per entry
+				var purgedErr *searcher.PurgedError
+				if cfg.SummaryOnly && errors.As(s.CheckPurged(binlogFiles, &target), &purgedErr) {
+					summary.Purged++
+				} else {
+					summary.NotFound++
+				}
+			}
+			continue
+		}
+		if err == nil {
+			summary.Found++
+		}
+		fmt.Fprintf(entries, "%s [%d/%d] %s: %s:%d\n", emoji.OK, i+1, len(gtidSets), gtidSet, filepath.Base(position.ResumeBinlogFile()), position.ResumePosition)
+
+		if err := emit(gtidSet.String(), position); err != nil {
+			return len(positions), fmt.Errorf("export error: %v", err)
+		}
+	}
+
+	if cfg.SummaryOnly {
+		summary.Duration = time.Since(start)
+		if lastFile >= 0 {
+			summary.FirstFile, summary.LastFile = binlogFiles[firstFile], binlogFiles[lastFile]
+		}
+		printBatchSummary(summary)
+		return len(positions), batchTimeoutError(summary)
+	}
+
+	cfg.Sort.Sort(positions, binlogFiles)
+	if cfg.OutputDir != "" {
+		fmt.Fprintf(os.Stderr, "%s Results written to %s\n", emoji.Output, cfg.OutputDir)
+	} else if stream != nil {
+		// Held back by emit until every position is known
+		if cfg.Sort != "" {
+			for _, position := range positions {
+				if err := stream.Write(position); err != nil {
+					stream.Close()
+					return len(positions), fmt.Errorf("export error: %v", err)
+				}
+			}
+		}
+		if err := stream.Close(); err != nil {
+			return len(positions), fmt.Errorf("export error: %v", err)
+		}
+	} else {
+		exp := exporter.NewJSONExporter(true)
+		exp.Fields = cfg.Fields
+		exp.Bare = cfg.OutputFormat == models.FormatJSONArray
+		search := newSearchResult(s, binlogFiles, nil)
+		search.Positions = positions
+		search.Failures = failures
+		search.Duration = time.Since(start)
+		if err := exp.ExportResult(search, cfg.OutputFile); err != nil {
+			return len(positions), fmt.Errorf("export error: %v", err)
 		}
 	}
 
-	return cfg
+	fmt.Fprintf(os.Stderr, "%s Batch done: %d/%d found in %.2f seconds\n", emoji.Info, len(positions), len(gtidSets), time.Since(start).Seconds())
+	if len(failures) > 0 {
+		fmt.Fprintf(os.Stderr, "%s %d entries failed and were skipped\n", emoji.Warning, len(failures))
+	}
+	return len(positions), batchTimeoutError(summary)
 }
 
-func validateConfig(cfg *models.Config) error {
-	if cfg.BinlogDir == "" {
-		return fmt.Errorf("binlog directory is required")
+// errBatchTimeout is returned by runBatch, once every result is exported, when -timeout
+// stopped the search of some entries
+var errBatchTimeout = errors.New("timed out")
+
+// batchTimeoutError returns errBatchTimeout with the number of timed out entries, or nil
+func batchTimeoutError(summary *models.BatchSummary) error {
+	if summary.TimedOut == 0 {
+		return nil
 	}
-	if cfg.TargetGTID == "" && cfg.GTIDFile == "" {
-		return fmt.Errorf("either -gtid or -gtid-file is required")
+	return fmt.Errorf("%w, %d/%d entries not searched to the end", errBatchTimeout, summary.TimedOut, summary.Total)
+}
+
+// printBatchSummary prints the outcome counts of a summary-only batch run
+func printBatchSummary(summary *models.BatchSummary) {
+	fmt.Fprintln(status, strings.Repeat("-", 60))
+	fmt.Println(emoji.Info, "Batch Summary")
+	fmt.Printf("  Found:      %d\n", summary.Found)
+	fmt.Printf("  Not found:  %d\n", summary.NotFound)
+	fmt.Printf("  Purged:     %d\n", summary.Purged)
+	fmt.Printf("  Timed out:  %d\n", summary.TimedOut)
+	fmt.Printf("  Failed:     %d\n", summary.Failed)
+	fmt.Printf("  Total:      %d\n", summary.Total)
+	if summary.Duplicates > 0 {
+		fmt.Printf("  Duplicates: %d (skipped)\n", summary.Duplicates)
 	}
-	if cfg.TargetGTID != "" && cfg.GTIDFile != "" {
-		return fmt.Errorf("cannot specify both -gtid and -gtid-file")
+	if summary.LastFile != "" {
+		fmt.Printf("%s Files: %s .. %s\n", emoji.Directory, filepath.Base(summary.FirstFile), filepath.Base(summary.LastFile))
 	}
-	if _, err := os.Stat(cfg.BinlogDir); os.IsNotExist(err) {
-		return fmt.Errorf("binlog directory does not exist: %s", cfg.BinlogDir)
+	fmt.Printf("%s Scan time: %.2f seconds\n", emoji.Elapsed, summary.Duration.Seconds())
+}
+
+// unsafeFileNameChars matches characters replaced in output file names
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// outputFileName returns a filesystem-safe file name for an input GTID set,
+// e.g. "uuid:1-100" becomes "uuid_1-100". Long sets are cut and suffixed with a hash.
+func outputFileName(gtid, ext string) string {
+	name := unsafeFileNameChars.ReplaceAllString(gtid, "_")
+	if len(name) > 200 {
+		name = fmt.Sprintf("%s_%x", name[:180], sha256.Sum256([]byte(gtid)))[:180+1+16]
 	}
-	if !cfg.OutputFormat.IsValid() {
-		return fmt.Errorf("invalid output format: %s (must be console, csv, or json)", cfg.OutputFormat)
+	return name + "." + ext
+}
+
+// exportToOutputDir writes the result of a batch entry to its own -output-dir file, in the selected format
+func exportToOutputDir(cfg *models.Config, gtid string, search *models.SearchResult) error {
+	path := filepath.Join(cfg.OutputDir, outputFileName(gtid, string(cfg.OutputFormat)))
+
+	switch cfg.OutputFormat {
+	case models.FormatCSV:
+		exp := exporter.NewCSVExporter()
+		exp.Fields = cfg.Fields
+		exp.TimeFormat = cfg.TimeFormat
+		return exp.Export(search.Positions, path)
+
+	default:
+		exp := exporter.NewJSONExporter(true)
+		exp.Fields = cfg.Fields
+		exp.Bare = cfg.OutputFormat == models.FormatJSONArray
+		return exp.ExportResult(search, path)
 	}
-	return nil
 }
 
-func findGTIDPosition(cfg *models.Config) (*models.GTIDPosition, error) {
-	// Create searcher
-	s := searcher.NewSearcher(cfg)
+// countMatches counts the transactions in the binlogs that are contained in the target set
+func countMatches(cfg *models.Config) (*models.MatchCount, error) {
+	s, err := searcher.NewSearcherForSource(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	// Get all binlog files
-	binlogFiles, err := s.GetBinlogFiles(cfg.BinlogDir, cfg.FilePattern)
+	binlogFiles, err := listBinlogFiles(s, cfg)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(binlogFiles) == 0 {
-		return nil, fmt.Errorf("no binlog files found")
+	targetGTID, err := parser.ParseGTID(cfg.TargetGTID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GTID format: %v", err)
 	}
 
-	// Filter binlog files if start-file is specified
-	if cfg.StartFile != "" {
-		var filteredFiles []string
-		startFound := false
-		for _, file := range binlogFiles {
-			// Check if this is the start file or we've already found it
-			if !startFound {
-				if strings.HasSuffix(file, cfg.StartFile) || filepath.Base(file) == cfg.StartFile {
-					startFound = true
-				} else {
-					continue // Skip files before start-file
-				}
-			}
-			filteredFiles = append(filteredFiles, file)
-		}
-		
-		if !startFound {
-			return nil, fmt.Errorf("start file '%s' not found in binlog files", cfg.StartFile)
-		}
-		
-		binlogFiles = filteredFiles
-		if cfg.Verbose {
-			fmt.Printf("📂 Starting from file: %s (%d files to scan)\n", cfg.StartFile, len(binlogFiles))
+	if cfg.FilterUUID != "" {
+		fmt.Fprintf(status, "%s Filtering by UUID: %s\n", emoji.Search, cfg.FilterUUID)
+		targetGTID, err = parser.FilterByUUID(&targetGTID, cfg.FilterUUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter by UUID: %v", err)
 		}
 	}
 
-	fmt.Printf("📋 Found %d binlog files\n", len(binlogFiles))
+	return s.CountParallel(context.Background(), binlogFiles, &targetGTID)
+}
+
+// transactionStats computes the sizes of the transactions contained in the target set
+func transactionStats(cfg *models.Config) (*models.TransactionStats, error) {
+	s, err := searcher.NewSearcherForSource(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	binlogFiles, err := listBinlogFiles(s, cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	// Parse target GTID
 	targetGTID, err := parser.ParseGTID(cfg.TargetGTID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid GTID format: %v", err)
 	}
 
-	// Handle active master detection
-	if cfg.FindActiveMaster {
-		activeMasterUUID, err := parser.FindActiveMasterUUID(&targetGTID)
+	if cfg.FilterUUID != "" {
+		fmt.Fprintf(status, "%s Filtering by UUID: %s\n", emoji.Search, cfg.FilterUUID)
+		targetGTID, err = parser.FilterByUUID(&targetGTID, cfg.FilterUUID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to find active master: %v", err)
+			return nil, fmt.Errorf("failed to filter by UUID: %v", err)
 		}
-		fmt.Printf("🎯 Active master UUID detected: %s\n", activeMasterUUID)
-		cfg.FilterUUID = activeMasterUUID
 	}
 
-	// Filter by UUID if specified
+	return s.TransactionStats(context.Background(), binlogFiles, &targetGTID)
+}
+
+// exportStats writes transaction size statistics in the console or json format
+func exportStats(stats *models.TransactionStats, cfg *models.Config, elapsed time.Duration) error {
+	fmt.Fprintln(status, strings.Repeat("-", 60))
+	fmt.Fprintf(status, "%s Computed transaction sizes in %.2f seconds\n", emoji.OK, elapsed.Seconds())
+
+	if cfg.OutputFormat == models.FormatJSON {
+		fmt.Fprintln(status, strings.Repeat("-", 60))
+		return exporter.NewJSONExporter(true).ExportStats(stats, cfg.OutputFile)
+	}
+	return exporter.NewConsoleExporter().ExportStats(stats)
+}
+
+// listDatabases counts the transactions of the binlogs per database written
+func listDatabases(cfg *models.Config) ([]models.DatabaseCount, error) {
+	s, err := searcher.NewSearcherForSource(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	binlogFiles, err := listBinlogFiles(s, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ListDatabases(context.Background(), binlogFiles)
+}
+
+// unionExecutedSet computes the GTID set executed by the binlogs, first PREVIOUS_GTIDS included
+func unionExecutedSet(cfg *models.Config) (*mysql.MysqlGTIDSet, error) {
+	s, err := searcher.NewSearcherForSource(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	binlogFiles, err := listBinlogFiles(s, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.UnionExecutedSet(context.Background(), binlogFiles)
+}
+
+// exportDatabases writes the databases written by the binlogs in the console or json format
+func exportDatabases(databases []models.DatabaseCount, cfg *models.Config, elapsed time.Duration) error {
+	fmt.Fprintln(status, strings.Repeat("-", 60))
+	fmt.Fprintf(status, "%s Listed databases in %.2f seconds\n", emoji.OK, elapsed.Seconds())
+
+	if cfg.OutputFormat == models.FormatJSON {
+		fmt.Fprintln(status, strings.Repeat("-", 60))
+		return exporter.NewJSONExporter(true).ExportDatabases(databases, cfg.OutputFile)
+	}
+	return exporter.NewConsoleExporter().ExportDatabases(databases)
+}
+
+// findDuplicates lists the GTIDs committed more than once in the binlogs
+func findDuplicates(cfg *models.Config) ([]*models.DuplicateGTID, error) {
+	s, err := searcher.NewSearcherForSource(context.Background(), cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	binlogFiles, err := listBinlogFiles(s, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.FindDuplicates(context.Background(), binlogFiles)
+}
+
+// exportDuplicates writes the GTIDs committed more than once in the console or json format
+func exportDuplicates(duplicates []*models.DuplicateGTID, cfg *models.Config, elapsed time.Duration) error {
+	fmt.Fprintln(status, strings.Repeat("-", 60))
+	fmt.Fprintf(status, "%s Scanned for duplicate GTIDs in %.2f seconds\n", emoji.OK, elapsed.Seconds())
+
+	if cfg.OutputFormat == models.FormatJSON {
+		fmt.Fprintln(status, strings.Repeat("-", 60))
+		return exporter.NewJSONExporter(true).ExportDuplicates(duplicates, cfg.OutputFile)
+	}
+	return exporter.NewConsoleExporter().ExportDuplicates(duplicates)
+}
+
+// containsGTID reports whether the target set is fully contained in the binlogs,
+// their PREVIOUS_GTIDS included, without locating a position
+func containsGTID(cfg *models.Config) (bool, error) {
+	s, err := searcher.NewSearcherForSource(context.Background(), cfg)
+	if err != nil {
+		return false, err
+	}
+
+	binlogFiles, err := listBinlogFiles(s, cfg)
+	if err != nil {
+		return false, err
+	}
+
+	targetGTID, err := parser.ParseGTID(cfg.TargetGTID)
+	if err != nil {
+		return false, fmt.Errorf("invalid GTID format: %v", err)
+	}
+
 	if cfg.FilterUUID != "" {
-		fmt.Printf("🔍 Filtering by UUID: %s\n", cfg.FilterUUID)
+		fmt.Fprintf(status, "%s Filtering by UUID: %s\n", emoji.Search, cfg.FilterUUID)
 		targetGTID, err = parser.FilterByUUID(&targetGTID, cfg.FilterUUID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to filter by UUID: %v", err)
+			return false, fmt.Errorf("failed to filter by UUID: %v", err)
 		}
 	}
 
-	// Show GTID info if verbose
-	if cfg.Verbose {
-		uuidInfos, _ := parser.ExtractUUIDs(&targetGTID)
-		fmt.Println("\n📊 GTID Set Information:")
-		for _, info := range uuidInfos {
-			fmt.Printf("  UUID: %s\n", info.UUID)
-			fmt.Printf("    Transactions: %d-%d (total: %d)\n", 
-				info.MinTransaction, info.MaxTransaction, info.TotalCount)
-		}
-		fmt.Println()
+	return s.Contains(context.Background(), binlogFiles, &targetGTID)
+}
+
+// printMatchCount prints the count-only result with its per-UUID breakdown
+func printMatchCount(count *models.MatchCount, elapsed time.Duration) {
+	fmt.Fprintln(status, strings.Repeat("-", 60))
+	fmt.Fprintf(status, "%s Counted in %.2f seconds\n\n", emoji.OK, elapsed.Seconds())
+	fmt.Printf("%s Matching transactions: %d\n", emoji.Count, count.Total)
+
+	uuids := make([]string, 0, len(count.ByUUID))
+	for uuid := range count.ByUUID {
+		uuids = append(uuids, uuid)
 	}
+	sort.Strings(uuids)
 
-	// Search in parallel
-	return s.SearchParallel(binlogFiles, &targetGTID)
+	for _, uuid := range uuids {
+		fmt.Printf("  %s: %d\n", uuid, count.ByUUID[uuid])
+	}
 }
 
 // parseTimeString parses time string in multiple formats
@@ -216,30 +2161,61 @@ func parseTimeString(timeStr string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("invalid time format, use: 2006-01-02 15:04:05 or RFC3339")
 }
 
-func exportResult(result *models.GTIDPosition, cfg *models.Config, elapsed time.Duration) error {
+func exportResult(search *models.SearchResult, cfg *models.Config) error {
+	elapsed := search.Duration
+
 	// Print search summary for non-console formats
 	if cfg.OutputFormat != models.FormatConsole {
-		fmt.Println(strings.Repeat("-", 60))
-		fmt.Printf("✅ Found GTID in %.2f seconds\n", elapsed.Seconds())
-		fmt.Println(strings.Repeat("-", 60))
+		fmt.Fprintln(status, strings.Repeat("-", 60))
+		fmt.Fprintf(status, "%s Found GTID in %.2f seconds\n", emoji.OK, elapsed.Seconds())
+		fmt.Fprintln(status, strings.Repeat("-", 60))
 	}
 
-	positions := []*models.GTIDPosition{result}
-
 	switch cfg.OutputFormat {
 	case models.FormatCSV:
 		exp := exporter.NewCSVExporter()
-		return exp.Export(positions, cfg.OutputFile)
+		exp.Fields = cfg.Fields
+		exp.TimeFormat = cfg.TimeFormat
+		return exp.Export(search.Positions, cfg.OutputFile)
 
-	case models.FormatJSON:
+	case models.FormatJSON, models.FormatJSONArray:
+		if cfg.Stream {
+			stream, err := newStreamWriter(cfg)
+			if err != nil {
+				return err
+			}
+			for _, pos := range search.Positions {
+				if err := stream.Write(pos); err != nil {
+					stream.Close()
+					return err
+				}
+			}
+			return stream.Close()
+		}
 		exp := exporter.NewJSONExporter(true)
-		return exp.Export(positions, cfg.OutputFile)
+		exp.Fields = cfg.Fields
+		exp.Bare = cfg.OutputFormat == models.FormatJSONArray
+		return exp.ExportResult(search, cfg.OutputFile)
+
+	case models.FormatCanal:
+		return exporter.NewCanalExporter().Export(search.Positions, cfg.OutputFile)
+
+	case models.FormatCloneSQL:
+		return exporter.NewCloneSQLExporter().Export(search.Positions, cfg.OutputFile)
+
+	case models.FormatTemplate:
+		exp, err := exporter.NewTemplateExporter(cfg.OutputTemplate)
+		if err != nil {
+			return err
+		}
+		return exp.Export(search.Positions, cfg.OutputFile)
 
 	case models.FormatConsole:
-		fmt.Println(strings.Repeat("-", 60))
-		fmt.Printf("✅ Found GTID in %.2f seconds\n\n", elapsed.Seconds())
+		fmt.Fprintln(status, strings.Repeat("-", 60))
+		fmt.Fprintf(status, "%s Found GTID in %.2f seconds\n\n", emoji.OK, elapsed.Seconds())
 		exp := exporter.NewConsoleExporter()
-		return exp.ExportSingle(result)
+		exp.TimeFormat = cfg.TimeFormat
+		return exp.ExportSingle(search.Positions[0])
 
 	default:
 		return fmt.Errorf("unsupported output format: %s", cfg.OutputFormat)
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
+	"github.com/quyetmv/mysql-gtid-position/models"
+)
+
+// TestRunBatch_JSONArray checks that a batch written as json-array is the bare array
+// of positions, as for a single search, not the envelope
+func TestRunBatch_JSONArray(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := testutil.WriteFixtures(dir); err != nil { // FixtureUUID:1-300 over 3 files
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+	status = io.Discard
+
+	output := filepath.Join(t.TempDir(), "positions.json")
+	cfg := &models.Config{
+		BinlogDir:    dir,
+		FilePattern:  "mysql-bin.*",
+		Parallel:     1,
+		TargetGTID:   testutil.FixtureUUID + ":50;" + testutil.FixtureUUID + ":250",
+		OutputFormat: models.FormatJSONArray,
+		OutputFile:   output,
+	}
+	found, err := runBatch(cfg, time.Now())
+	if err != nil {
+		t.Fatalf("runBatch() error = %v", err)
+	}
+	if found != 2 {
+		t.Fatalf("Expected 2 positions found, got %d", found)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("Failed to read output: %v", err)
+	}
+	var positions []models.GTIDPosition
+	if err := json.Unmarshal(data, &positions); err != nil {
+		t.Fatalf("Expected a bare JSON array, got %s: %v", data, err)
+	}
+	if len(positions) != 2 || positions[0].GNO != 50 || positions[1].GNO != 250 {
+		t.Errorf("Expected GNOs 50 and 250, got %s", data)
+	}
+}
+
+// TestFindFirstMissingPosition_NothingMissing checks that a replica holding every GTID of
+// the master is reported to the caller instead of exiting from the helper
+func TestFindFirstMissingPosition_NothingMissing(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := testutil.WriteFixtures(dir); err != nil { // FixtureUUID:1-300 over 3 files
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+	status = io.Discard
+
+	cfg := &models.Config{
+		BinlogDir:    dir,
+		FilePattern:  "mysql-bin.*",
+		Parallel:     1,
+		TargetGTID:   testutil.FixtureUUID + ":1-300",
+		ExecutedGTID: testutil.FixtureUUID + ":1-300",
+	}
+	search, err := findFirstMissingPosition(cfg)
+	if !errors.Is(err, errNothingMissing) {
+		t.Fatalf("Expected errNothingMissing, got %v", err)
+	}
+	if search != nil {
+		t.Errorf("Expected no result, got %+v", search)
+	}
+
+	cfg.ExecutedGTID = testutil.FixtureUUID + ":1-199"
+	search, err = findFirstMissingPosition(cfg)
+	if err != nil {
+		t.Fatalf("findFirstMissingPosition() error = %v", err)
+	}
+	if len(search.Positions) != 1 || search.Positions[0].GNO != 200 {
+		t.Errorf("Expected the first missing GNO 200, got %+v", search.Positions)
+	}
+}
+
+// TestRunBatch_Timeout checks that -timeout bounds the whole batch and is reported as
+// errBatchTimeout once the results are exported
+func TestRunBatch_Timeout(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := testutil.WriteFixtures(dir); err != nil { // FixtureUUID:1-300 over 3 files
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+	status = io.Discard
+
+	output := filepath.Join(t.TempDir(), "positions.json")
+	cfg := &models.Config{
+		BinlogDir:    dir,
+		FilePattern:  "mysql-bin.*",
+		Parallel:     1,
+		TargetGTID:   testutil.FixtureUUID + ":250;" + testutil.FixtureUUID + ":10",
+		OutputFormat: models.FormatJSON,
+		OutputFile:   output,
+		Timeout:      time.Nanosecond,
+	}
+	found, err := runBatch(cfg, time.Now())
+	if !errors.Is(err, errBatchTimeout) {
+		t.Fatalf("Expected errBatchTimeout, got %v", err)
+	}
+	if found != 0 || !strings.Contains(err.Error(), "2/2 entries") {
+		t.Errorf("Expected 0 found and 2/2 entries timed out, got %d, %v", found, err)
+	}
+	if _, err := os.Stat(output); err != nil {
+		t.Errorf("Expected the results exported before the timeout is reported: %v", err)
+	}
+}
+
+// parseArgs runs parseFlags on the command-line arguments args, with a fresh flag set
+func parseArgs(t *testing.T, args ...string) (*models.Config, error) {
+	t.Helper()
+	oldArgs, oldCommandLine := os.Args, flag.CommandLine
+	t.Cleanup(func() { os.Args, flag.CommandLine = oldArgs, oldCommandLine })
+
+	os.Args = append([]string{"mysql-gtid-position"}, args...)
+	flag.CommandLine = flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	flag.CommandLine.SetOutput(io.Discard)
+	return parseFlags()
+}
+
+func TestParseFlags_ConfigFile(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+		return path
+	}
+
+	t.Run("command line overrides", func(t *testing.T) {
+		path := writeConfig("override.yaml", "dir: /data/config\nparallel: 2\npattern: relay-bin.*\n")
+		cfg, err := parseArgs(t, "-config", path, "-dir", "/data/flag")
+		if err != nil {
+			t.Fatalf("parseFlags() error = %v", err)
+		}
+		if cfg.BinlogDir != "/data/flag" || cfg.Parallel != 2 || cfg.FilePattern != "relay-bin.*" {
+			t.Errorf("Expected -dir from the command line, the rest from the file, got dir %s, parallel %d, pattern %s", cfg.BinlogDir, cfg.Parallel, cfg.FilePattern)
+		}
+	})
+
+	t.Run("lists", func(t *testing.T) {
+		path := writeConfig("lists.yaml", "fields: [gtid, binlog_file]\nallowed-uuids:\n  - "+testutil.FixtureUUID+"\n  - 4e11fa47-71ca-11e1-9e33-c80aa9429562\n")
+		cfg, err := parseArgs(t, "-config", path)
+		if err != nil {
+			t.Fatalf("parseFlags() error = %v", err)
+		}
+		if strings.Join(cfg.Fields, ",") != "gtid,binlog_file" || len(cfg.AllowedUUIDs) != 2 {
+			t.Errorf("Expected the lists comma-joined, got fields %v, allowed UUIDs %v", cfg.Fields, cfg.AllowedUUIDs)
+		}
+	})
+
+	for _, name := range []string{"numbers.json", "numbers.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			content := `{"max-gno": 2000000, "min-gno": 10}`
+			if strings.HasSuffix(name, ".yaml") {
+				content = "max-gno: 2000000\nmin-gno: 10\n"
+			}
+			cfg, err := parseArgs(t, "-config", writeConfig(name, content))
+			if err != nil {
+				t.Fatalf("parseFlags() error = %v", err)
+			}
+			if cfg.MaxGNO != 2000000 || cfg.MinGNO != 10 {
+				t.Errorf("Expected GNOs 10 to 2000000, got %d to %d", cfg.MinGNO, cfg.MaxGNO)
+			}
+		})
+	}
+
+	t.Run("unknown key", func(t *testing.T) {
+		_, err := parseArgs(t, "-config", writeConfig("unknown.json", `{"dir": "/data", "no-such-flag": true}`))
+		if err == nil || !strings.Contains(err.Error(), `unknown option "no-such-flag"`) {
+			t.Errorf("Expected an unknown option error, got %v", err)
+		}
+	})
+}
+
+func TestParseFlags_Compare(t *testing.T) {
+	t.Run("dir and compare", func(t *testing.T) {
+		dirA, dirB := t.TempDir(), t.TempDir()
+		for _, dir := range []string{dirA, dirB} {
+			if _, err := testutil.WriteFixtures(dir); err != nil {
+				t.Fatalf("Failed to write fixtures: %v", err)
+			}
+		}
+		status = io.Discard
+
+		cfg, err := parseArgs(t, "-dir", dirA, "-compare", dirB)
+		if err != nil {
+			t.Fatalf("parseFlags() error = %v", err)
+		}
+		if len(cfg.CompareDirs) != 2 || cfg.CompareDirs[0] != dirA || cfg.CompareDirs[1] != dirB {
+			t.Fatalf("Expected -dir then -compare, got %v", cfg.CompareDirs)
+		}
+		if err := validateConfig(cfg); err != nil {
+			t.Fatalf("validateConfig() error = %v", err)
+		}
+		same, err := compareDirectories(cfg)
+		if err != nil || !same {
+			t.Errorf("Expected the same executed sets, got %v, %v", same, err)
+		}
+	})
+
+	t.Run("compare without dir", func(t *testing.T) {
+		cfg, err := parseArgs(t, "-compare", "/data/b")
+		if err != nil {
+			t.Fatalf("parseFlags() error = %v", err)
+		}
+		if err := validateConfig(cfg); err == nil || !strings.Contains(err.Error(), "-compare needs the first directory in -dir") {
+			t.Errorf("Expected the missing -dir error, got %v", err)
+		}
+	})
+
+	t.Run("positional argument", func(t *testing.T) {
+		_, err := parseArgs(t, "-compare", "/data/a", "/data/b")
+		if err == nil || !strings.Contains(err.Error(), `unexpected argument "/data/b"`) {
+			t.Errorf("Expected the positional argument rejected, got %v", err)
+		}
+	})
+}
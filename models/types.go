@@ -1,6 +1,16 @@
 package models
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
 
 // GTIDPosition represents the location of a GTID in a binlog file
 type GTIDPosition struct {
@@ -8,61 +18,417 @@ type GTIDPosition struct {
 	Position       uint32    `json:"start_position" csv:"start_position"`         // Start position (GTID event)
 	CommitPosition uint32    `json:"commit_position" csv:"commit_position"`       // Commit position (Xid END_LOG_POS)
 	ResumePosition uint32    `json:"resume_position" csv:"resume_position"`       // Resume position (END_LOG_POS of next GTID)
+	ResumeFile     string    `json:"resume_file,omitempty" csv:"resume_file"`     // File of ResumePosition when the next GTID is in a later file, else empty (BinlogFile)
 	Timestamp      uint32    `json:"timestamp" csv:"timestamp"`
 	GTID           string    `json:"gtid" csv:"gtid"`
 	ServerUUID     string    `json:"server_uuid" csv:"server_uuid"`
 	GNO            uint64    `json:"gno" csv:"gno"`
 	Database       string    `json:"database,omitempty" csv:"database"`
 	NextGTID       string    `json:"next_gtid,omitempty" csv:"next_gtid"` // Next GTID for debug
+	PrevGTID       string    `json:"prev_gtid,omitempty" csv:"prev_gtid"` // Previous GTID in the same file, if any
+	ExecutedSet    string    `json:"executed_set,omitempty" csv:"executed_set"` // GTID set executed up to and including this transaction
+	AgeSeconds     int64     `json:"age_seconds,omitempty" csv:"age_seconds"`   // Seconds between the commit timestamp and the search
+	LastCommitted  int64     `json:"last_committed" csv:"last_committed"`       // Group commit: sequence number of the transaction it depends on
+	SequenceNumber int64     `json:"sequence_number" csv:"sequence_number"`     // Group commit: commit order in the file, 0 before MySQL 5.7
+	MatchInterval  [2]uint64 `json:"match_interval" csv:"match_interval"`       // First and last GNO of the target set interval holding GTID, zero outside a target match
 	CreatedAt      time.Time `json:"created_at,omitempty" csv:"-"`
 }
 
 // TimestampReadable returns human-readable timestamp
 func (g *GTIDPosition) TimestampReadable() string {
-	return time.Unix(int64(g.Timestamp), 0).Format(time.RFC3339)
+	return FormatTimestamp(g.Timestamp, "")
+}
+
+// Timestamp formats of FormatTimestamp besides Go time layouts
+const (
+	TimeFormatUnix      = "unix"      // Seconds since the epoch
+	TimeFormatUnixMilli = "unixmilli" // Milliseconds since the epoch
+)
+
+// FormatTimestamp renders a binlog timestamp (seconds) in layout: a Go time layout,
+// TimeFormatUnix or TimeFormatUnixMilli. An empty layout means RFC3339.
+func FormatTimestamp(timestamp uint32, layout string) string {
+	switch layout {
+	case "":
+		layout = time.RFC3339
+	case TimeFormatUnix:
+		return strconv.FormatUint(uint64(timestamp), 10)
+	case TimeFormatUnixMilli:
+		return strconv.FormatUint(uint64(timestamp)*1000, 10)
+	}
+	return time.Unix(int64(timestamp), 0).Format(layout)
+}
+
+// ResumeBinlogFile returns the binlog file ResumePosition is in: ResumeFile when
+// the next GTID was found in a later file, BinlogFile otherwise
+func (g *GTIDPosition) ResumeBinlogFile() string {
+	if g.ResumeFile != "" {
+		return g.ResumeFile
+	}
+	return g.BinlogFile
+}
+
+// Validate checks the invariants of a position: a binlog file, a single GTID matching
+// ServerUUID and GNO when they are set, and Position <= CommitPosition <= ResumePosition,
+// the resume position only when it is in BinlogFile. The first violation is returned.
+func (g *GTIDPosition) Validate() error {
+	if g.BinlogFile == "" {
+		return errors.New("empty binlog file")
+	}
+	if g.GTID == "" {
+		return errors.New("empty GTID")
+	}
+
+	uuidSet, err := mysql.ParseUUIDSet(g.GTID)
+	if err != nil {
+		return fmt.Errorf("invalid GTID %q: %w", g.GTID, err)
+	}
+	if len(uuidSet.Intervals) != 1 || uuidSet.Intervals[0].Stop != uuidSet.Intervals[0].Start+1 {
+		return fmt.Errorf("GTID %q is not a single transaction", g.GTID)
+	}
+	if g.ServerUUID != "" && !strings.EqualFold(g.ServerUUID, uuidSet.SID.String()) {
+		return fmt.Errorf("server UUID %s does not match GTID %s", g.ServerUUID, g.GTID)
+	}
+	if g.GNO != 0 && g.GNO != uint64(uuidSet.Intervals[0].Start) {
+		return fmt.Errorf("GNO %d does not match GTID %s", g.GNO, g.GTID)
+	}
+
+	if g.Position > g.CommitPosition {
+		return fmt.Errorf("start position %d is after commit position %d", g.Position, g.CommitPosition)
+	}
+	if g.ResumeFile == "" && g.CommitPosition > g.ResumePosition {
+		return fmt.Errorf("commit position %d is after resume position %d", g.CommitPosition, g.ResumePosition)
+	}
+	return nil
+}
+
+// ToMySQLPosition returns the resume position as the mysql.Position a go-mysql canal
+// or syncer starts from: the base name of its binlog file and ResumePosition
+func (g *GTIDPosition) ToMySQLPosition() mysql.Position {
+	return mysql.Position{Name: filepath.Base(g.ResumeBinlogFile()), Pos: g.ResumePosition}
+}
+
+// SetAge sets AgeSeconds, how long before now the transaction was committed.
+// It is left unset without a commit timestamp.
+func (g *GTIDPosition) SetAge(now time.Time) {
+	if g.Timestamp > 0 {
+		g.AgeSeconds = int64(now.Sub(time.Unix(int64(g.Timestamp), 0)).Seconds())
+	}
 }
 
 // Config holds application configuration
 type Config struct {
 	BinlogDir        string
+	S3Source         string // s3://bucket/prefix/mysql-bin.* to stream binlogs from S3 instead of BinlogDir
+	SSHSource        string // ssh://user@host/path/mysql-bin.* to stream binlogs over SFTP instead of BinlogDir
 	TargetGTID       string
 	GTIDFile         string // File containing multiple GTIDs for batch mode
+	GTIDSetFile      string // File holding a single target GTID set, used as TargetGTID
+	ContinueOnError  bool   // Batch mode: report a failing entry as not found and go on
+	Dedupe           bool   // Batch mode: search identical GTID sets only once
+	Checkpoint       string // Batch checkpoint file (NDJSON) of resolved -gtid-file entries
+	ExecutedGTID     string // Replica's gtid_executed set for diff-and-locate mode
+	FromReplica      string // Live replica (host:port) whose Executed_Gtid_Set is used as ExecutedGTID
+	ReplicaUser      string // User for FromReplica
+	ReplicaPassword  string // Password for FromReplica, from MYSQL_PWD
+	BeforeGTID       string // Locate the committed transaction just before this GTID
+	AfterGTID        string // Locate the committed transaction just after this GTID
 	FilePattern      string
+	FilePatternRegex string    // Regular expression matched against whole file names in BinlogDir, instead of FilePattern
+	IndexFile        string    // Binlog index file (e.g., mysql-bin.index) giving the authoritative file order
+	FilesFrom        string    // File listing the binlogs to scan in order, "-" for stdin, instead of BinlogDir
 	StartFile        string    // Start searching from this binlog file (e.g., mysql-bin.000100)
-	Parallel         int
+	BeforeFile       string    // Stop searching before this binlog file, excluded (e.g., mysql-bin.000500)
+	Recent           int       // Only search the newest N binlog files (0 = all)
+	EndGTID          string    // Stop scanning once past this GTID (UUID:N) of its server
+	NoSmartStart     bool      // Ignore PREVIOUS_GTIDS headers when choosing where to start, scan from the first file
+	ShowSkipped      string    // Log the headers smart selection reads (ShowSkippedProbed) or of every file (ShowSkippedFull)
+	Precheck         bool      // Read every file header before scanning and report unreadable, foreign or mismatched files
+	Strict           bool      // With Precheck, fail instead of scanning when an issue is found
+	Parallel         int       // Worker count, 0 = auto (see searcher.AutoParallel)
+	Storage          string    // Storage hint for auto parallelism: auto, ssd, hdd, nfs
+	MaxGlobalParallel int      // Cap on files scanned at once by all instances sharing BinlogDir, via a lock file (0 = no cap)
+	Bisect           bool      // Bracket the target in large binlog files by sampling GTIDs at offsets, then scan only that window
+	Timeout          time.Duration // Stop the search after this long and report the best partial result (0 = no limit)
 	Verbose          bool
+	Quiet            bool      // Status lines go to stderr even with console output, stdout only has the result
+	NoEmoji          bool      // Status lines and console output use ASCII tags instead of emoji
+	LogFormat        string    // Log format for verbose messages: text or json
 	OutputFormat     ExportFormat
 	OutputFile       string
+	OutputTemplate   string    // text/template over GTIDPosition rendering each result line, sets OutputFormat to template
+	OutputDir        string    // Batch mode: one output file per input GTID set in this directory
+	Fields           []string  // Output only these JSON fields (json and csv formats)
+	Stream           bool      // Write results as they are found: NDJSON for json, flush every row for csv
+	TimeFormat       string    // Layout of rendered timestamps, see FormatTimestamp (RFC3339 if empty)
 	FindActiveMaster bool      // Auto-detect and search for active master UUID (highest GNO)
 	FilterUUID       string    // Filter search by specific server UUID
+	MinGNO           uint64    // Only match GTIDs with at least this GNO (0 = no bound)
+	MaxGNO           uint64    // Only match GTIDs with at most this GNO (0 = no bound)
+	AllowedUUIDs     []string  // Only transactions of these server UUIDs (lowercase) may match, all if empty
 	FilterDatabase   string    // Filter search by database name
+	FilterTable      string    // Filter search by table written (TABLE_MAP events): "table" or "db.table"
+	FilterForDisplayOnly bool  // With FilterDatabase/FilterTable, resume at the next transaction of the unfiltered stream
 	StartTime        time.Time // Filter events after this time
 	EndTime          time.Time // Filter events before this time
 	FindAll          bool      // Find all GTIDs in range (not just first match)
+	CountOnly        bool      // Count matching transactions instead of locating one
+	Stats            bool      // Report transaction size statistics instead of locating one
+	DetectDuplicates bool      // Report GTIDs committed more than once in the binlogs instead of locating one
+	ListDatabases    bool      // Report the databases written by the binlogs with their transaction counts instead of locating one
+	ExecutedSet      bool      // Report the union of the first PREVIOUS_GTIDS and every GTID of the binlogs (gtid_executed) instead of locating one
+	Contains         bool      // Only report whether the target set is contained in the binlogs
+	Range            bool      // Report the position range to replay for the target set (PITR)
+	Last             bool      // Report the newest transaction of the binlogs (head position)
+	Expr             string    // Boolean expression over GTID sets, the first transaction after which it holds is reported
+	CompareDirs      []string  // Two binlog directories whose executed GTID sets are compared
+	PosToGTID        string    // "file:pos" whose executed GTID set is reported, the inverse of a search
+	TUI              bool      // Browse the found positions in an interactive table
+	Verify           bool      // Check that the reported position is an event boundary before printing it
+	Raw              bool      // Debug: print the hex of the GTID and commit events of the found transaction
+	SummaryOnly      bool      // Batch mode: print only the aggregate outcome counts, no per-entry output
+	Serve            string    // Listen address for HTTP server mode (e.g., :8080)
+	GenFixtures      string    // Hidden: write sample binlogs to this directory and exit
+	Selection        Selection // Which matching transaction to return: first, last or highest-gno
+	Sort             SortOrder // Order of the positions of a batch before export, the order found if empty
 }
 
+// Selection decides which transaction is returned when several match the target set
+type Selection string
+
+const (
+	// SelectionHighestGNO returns the match with the highest GNO (default).
+	// For a contiguous executed set (UUID:1-N) this is the last executed transaction,
+	// which is the natural resume point for CDC tools.
+	SelectionHighestGNO Selection = "highest-gno"
+	// SelectionFirst returns the earliest matching transaction in binlog order ("start replication here")
+	SelectionFirst Selection = "first"
+	// SelectionLast returns the latest matching transaction in binlog order ("catch up to here")
+	SelectionLast Selection = "last"
+)
+
+// IsValid checks if selection mode is valid (empty means highest-gno)
+func (s Selection) IsValid() bool {
+	switch s {
+	case "", SelectionHighestGNO, SelectionFirst, SelectionLast:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsOrdered reports whether the selection depends on binlog order rather than GNO
+func (s Selection) IsOrdered() bool {
+	return s == SelectionFirst || s == SelectionLast
+}
+
+// SortOrder orders the positions of a batch before export, see SortOrder.Sort
+type SortOrder string
+
+const (
+	// SortPosition orders by binlog file, then position within the file
+	SortPosition SortOrder = "position"
+	// SortTimestamp orders by commit timestamp, for timelines; ties keep binlog order
+	SortTimestamp SortOrder = "timestamp"
+	// SortGNO orders by server UUID, then GNO
+	SortGNO SortOrder = "gno"
+)
+
+// IsValid checks if sort order is valid (empty keeps the order found)
+func (o SortOrder) IsValid() bool {
+	switch o {
+	case "", SortPosition, SortTimestamp, SortGNO:
+		return true
+	default:
+		return false
+	}
+}
+
+// Sort orders positions in place. Files gives the binlog order of file names, as
+// listed for the search; files not in it sort after those that are, by name.
+// The sort is stable, the empty order leaves positions untouched.
+func (o SortOrder) Sort(positions []*GTIDPosition, files []string) {
+	if o == "" {
+		return
+	}
+
+	fileOrder := make(map[string]int, len(files))
+	for i, file := range files {
+		fileOrder[file] = i
+	}
+	// before reports whether a is earlier than b in binlog order
+	before := func(a, b *GTIDPosition) bool {
+		if a.BinlogFile != b.BinlogFile {
+			ai, aok := fileOrder[a.BinlogFile]
+			bi, bok := fileOrder[b.BinlogFile]
+			if aok && bok {
+				return ai < bi
+			}
+			if aok != bok {
+				return aok
+			}
+			return a.BinlogFile < b.BinlogFile
+		}
+		return a.Position < b.Position
+	}
+
+	sort.SliceStable(positions, func(i, j int) bool {
+		a, b := positions[i], positions[j]
+		switch o {
+		case SortTimestamp:
+			if a.Timestamp != b.Timestamp {
+				return a.Timestamp < b.Timestamp
+			}
+		case SortGNO:
+			if a.ServerUUID != b.ServerUUID {
+				return a.ServerUUID < b.ServerUUID
+			}
+			if a.GNO != b.GNO {
+				return a.GNO < b.GNO
+			}
+		}
+		return before(a, b)
+	})
+}
+
+// Show-skipped modes, see Config.ShowSkipped
+const (
+	ShowSkippedProbed = "probed" // Headers read to take the decision
+	ShowSkippedFull   = "full"   // Every file's header, also those the decision did not need
+)
+
 // ExportFormat represents output format type
 type ExportFormat string
 
 const (
-	FormatConsole ExportFormat = "console"
-	FormatCSV     ExportFormat = "csv"
-	FormatJSON    ExportFormat = "json"
+	FormatConsole   ExportFormat = "console"
+	FormatCSV       ExportFormat = "csv"
+	FormatJSON      ExportFormat = "json"
+	FormatJSONArray ExportFormat = "json-array" // Bare JSON array of the positions, without the envelope
+	FormatCanal     ExportFormat = "canal"      // mysql.Position of the resume position, for go-mysql canal
+	FormatCloneSQL  ExportFormat = "clone-sql"  // SQL seeding a new replica at the resume position
+	FormatTemplate  ExportFormat = "template"   // Lines rendered from Config.OutputTemplate
+)
+
+// Log formats for verbose messages
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// MatchCount is the result of count-only mode: committed transactions in the
+// binlogs that are contained in the target set
+type MatchCount struct {
+	Total  uint64            `json:"total"`
+	ByUUID map[string]uint64 `json:"by_uuid"` // Per server UUID
+}
+
+// SizeStats summarizes the sizes in bytes of a group of transactions
+type SizeStats struct {
+	Count       uint64 `json:"count"`
+	Min         uint64 `json:"min"`
+	Avg         uint64 `json:"avg"`
+	Max         uint64 `json:"max"`
+	P95         uint64 `json:"p95"`          // Nearest-rank 95th percentile
+	LargestGTID string `json:"largest_gtid"` // GTID of the Max transaction
+}
+
+// TransactionStats is the result of stats mode: sizes of the committed transactions
+// in the binlogs that are contained in the target set, from the start of their GTID
+// event to their commit END_LOG_POS
+type TransactionStats struct {
+	Overall SizeStats            `json:"overall"`
+	ByUUID  map[string]SizeStats `json:"by_uuid"` // Per server UUID
+}
+
+// DatabaseCount is the number of committed transactions that wrote a database
+type DatabaseCount struct {
+	Database     string `json:"database"`
+	Transactions uint64 `json:"transactions"`
+}
+
+// GTIDOccurrence is one copy of a transaction in the binlogs
+type GTIDOccurrence struct {
+	BinlogFile     string `json:"binlog_file"`
+	Position       uint32 `json:"position"`        // Start of its GTID event
+	CommitPosition uint32 `json:"commit_position"` // END_LOG_POS of its commit event
+	Timestamp      uint32 `json:"timestamp"`
+}
+
+// DuplicateGTID is a GTID committed more than once in the binlogs: an errant transaction
+// of a split-brain or a botched restore. Occurrences are in binlog order.
+type DuplicateGTID struct {
+	GTID        string           `json:"gtid"`
+	Occurrences []GTIDOccurrence `json:"occurrences"`
+}
+
+// BatchSummary aggregates the outcomes of a batch run (-summary-only).
+// Every entry is counted under exactly one outcome.
+type BatchSummary struct {
+	Total      int           `json:"total"`
+	Found      int           `json:"found"`
+	NotFound   int           `json:"not_found"`
+	Purged     int           `json:"purged"`               // Not found, purged before the earliest binlog
+	TimedOut   int           `json:"timed_out"`            // Partial positions are still counted in the file range
+	Failed     int           `json:"failed"`               // Entries skipped with -continue-on-error
+	Duplicates int           `json:"duplicates,omitempty"` // Identical entries dropped by -dedupe, not in Total
+	FirstFile  string        `json:"first_file,omitempty"` // Earliest binlog holding a found position
+	LastFile   string        `json:"last_file,omitempty"`  // Latest binlog holding a found position
+	Duration   time.Duration `json:"duration"`
+}
+
+// PositionRange is the binlog range to replay for a GTID set (point-in-time recovery):
+// from the start of its first contained transaction to the resume position after the last
+type PositionRange struct {
+	StartFile     string   `json:"start_file"`
+	StartPosition uint32   `json:"start_position"` // GTID event of the first contained transaction
+	EndFile       string   `json:"end_file"`
+	EndPosition   uint32   `json:"end_position"` // Resume position after the last contained transaction
+	FirstGTID     string   `json:"first_gtid"`
+	LastGTID      string   `json:"last_gtid"`
+	Files         []string `json:"files"` // Binlog files from StartFile to EndFile, in order
+}
+
+// EntryFailure is a batch entry whose search failed and was skipped (-continue-on-error)
+type EntryFailure struct {
+	Entry int    `json:"entry"` // 1-based index in the GTID file
+	GTID  string `json:"gtid"`
+	Error string `json:"error"`
+}
+
+// NotFoundReason explains why a search found no match
+type NotFoundReason string
+
+const (
+	// NotFoundPurged: the GTID is only in PREVIOUS_GTIDS headers, purged before the scanned files
+	NotFoundPurged NotFoundReason = "purged"
+	// NotFoundUnseenUUID: no server UUID of the target appears in the scanned files
+	NotFoundUnseenUUID NotFoundReason = "unseen_uuid"
+	// NotFoundFuture: the GTID is past the highest GNO written by its server, not executed yet
+	NotFoundFuture NotFoundReason = "future"
+	// NotFoundFiltered: the GTID was executed in the scanned files but the filters dropped it
+	NotFoundFiltered NotFoundReason = "filtered"
+	// NotFoundAbsent: the GTID is in a gap of the executed set of the scanned files
+	NotFoundAbsent NotFoundReason = "absent"
 )
 
 // SearchResult contains search results with metadata
 type SearchResult struct {
-	Positions     []*GTIDPosition `json:"positions"`
-	TotalFiles    int             `json:"total_files"`
-	ScannedFiles  int             `json:"scanned_files"`
-	Duration      time.Duration   `json:"duration"`
-	Error         error           `json:"error,omitempty"`
+	Positions      []*GTIDPosition `json:"positions"`
+	TotalFiles     int             `json:"total_files"`
+	ScannedFiles   int             `json:"scanned_files"`
+	Duration       time.Duration   `json:"duration"`
+	UnseenUUIDs    []string        `json:"unseen_uuids,omitempty"`     // Target UUIDs in no GTID of the scanned files
+	NotFoundReason NotFoundReason  `json:"not_found_reason,omitempty"` // Why nothing matched, empty with a match
+	ServerVersion  string          `json:"server_version,omitempty"`   // MySQL version that wrote the scanned files
+	Failures       []*EntryFailure `json:"failures,omitempty"`         // Batch entries that failed with -continue-on-error
+	Error          error           `json:"error,omitempty"`
 }
 
 // IsValid checks if export format is valid
 func (f ExportFormat) IsValid() bool {
 	switch f {
-	case FormatConsole, FormatCSV, FormatJSON:
+	case FormatConsole, FormatCSV, FormatJSON, FormatJSONArray, FormatCanal, FormatCloneSQL, FormatTemplate:
 		return true
 	default:
 		return false
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGTIDPosition_Validate(t *testing.T) {
+	valid := func() *GTIDPosition {
+		return &GTIDPosition{
+			BinlogFile:     "/var/lib/mysql/mysql-bin.000002",
+			Position:       4900,
+			CommitPosition: 4996,
+			ResumePosition: 5061,
+			GTID:           "3e11fa47-71ca-11e1-9e33-c80aa9429562:150",
+			ServerUUID:     "3e11fa47-71ca-11e1-9e33-c80aa9429562",
+			GNO:            150,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(pos *GTIDPosition)
+		wantErr string // Empty when valid
+	}{
+		{name: "valid", modify: func(pos *GTIDPosition) {}},
+		{name: "resume at commit", modify: func(pos *GTIDPosition) { pos.ResumePosition = pos.CommitPosition }},
+		{name: "resume in a later file", modify: func(pos *GTIDPosition) { pos.ResumeFile, pos.ResumePosition = "mysql-bin.000003", 261 }},
+		{name: "uppercase server UUID", modify: func(pos *GTIDPosition) { pos.ServerUUID = strings.ToUpper(pos.ServerUUID) }},
+		{name: "empty binlog file", modify: func(pos *GTIDPosition) { pos.BinlogFile = "" }, wantErr: "empty binlog file"},
+		{name: "empty GTID", modify: func(pos *GTIDPosition) { pos.GTID = "" }, wantErr: "empty GTID"},
+		{name: "unparseable GTID", modify: func(pos *GTIDPosition) { pos.GTID = "not-a-gtid:1" }, wantErr: "invalid GTID"},
+		{name: "GTID range", modify: func(pos *GTIDPosition) { pos.GTID += "-151" }, wantErr: "not a single transaction"},
+		{name: "other server UUID", modify: func(pos *GTIDPosition) { pos.ServerUUID = "4e11fa47-71ca-11e1-9e33-c80aa9429562" }, wantErr: "server UUID"},
+		{name: "other GNO", modify: func(pos *GTIDPosition) { pos.GNO = 151 }, wantErr: "GNO 151"},
+		{name: "start after commit", modify: func(pos *GTIDPosition) { pos.Position = 4294967200 }, wantErr: "start position"},
+		{name: "commit after resume", modify: func(pos *GTIDPosition) { pos.ResumePosition = 4000 }, wantErr: "resume position"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos := valid()
+			tt.modify(pos)
+			err := pos.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSortOrder_Sort(t *testing.T) {
+	const uuidA, uuidB = "3e11fa47-71ca-11e1-9e33-c80aa9429562", "a1b2c3d4-71ca-11e1-9e33-c80aa9429562"
+	files := []string{"/data/log/binlog.999999", "/data/log/binlog.1000000"} // Listed order, not name order
+
+	newPositions := func() []*GTIDPosition {
+		return []*GTIDPosition{
+			{GTID: "a", BinlogFile: files[1], Position: 900, Timestamp: 100, ServerUUID: uuidA, GNO: 7},
+			{GTID: "b", BinlogFile: files[0], Position: 500, Timestamp: 300, ServerUUID: uuidB, GNO: 2},
+			{GTID: "c", BinlogFile: "/other/binlog.000001", Position: 4, Timestamp: 100, ServerUUID: uuidA, GNO: 3},
+			{GTID: "d", BinlogFile: files[0], Position: 200, Timestamp: 200, ServerUUID: uuidB, GNO: 1},
+		}
+	}
+
+	tests := []struct {
+		order SortOrder
+		want  string
+	}{
+		{order: "", want: "abcd"},
+		{order: SortPosition, want: "dbac"},
+		{order: SortTimestamp, want: "acdb"}, // a before c: same timestamp, binlog order
+		{order: SortGNO, want: "cadb"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.order), func(t *testing.T) {
+			positions := newPositions()
+			tt.order.Sort(positions, files)
+
+			var got strings.Builder
+			for _, pos := range positions {
+				got.WriteString(pos.GTID)
+			}
+			if got.String() != tt.want {
+				t.Errorf("Sort(%q) = %s, want %s", tt.order, got.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+// GTIDExpr is a boolean expression over GTID sets, evaluated against an executed set.
+// See ParseGTIDExpr for the syntax.
+type GTIDExpr interface {
+	// Eval reports whether the expression holds once the executed set has been applied
+	Eval(executed *mysql.MysqlGTIDSet) bool
+	// Sets returns the GTID sets the expression refers to, in order
+	Sets() []mysql.GTIDSet
+	String() string
+}
+
+// ParseGTIDExpr parses an expression such as "contains(A) and any(B)", where A and B
+// are GTID sets:
+//
+//	contains(set)  every transaction of set is executed (the -gtid set check)
+//	any(set)       at least one transaction of set is executed
+//	a and b, a or b, (a)
+//
+// "and" binds tighter than "or"; keywords are case-insensitive. There is no "not":
+// every expression can only become true as transactions are executed, never false
+// again, so the first transaction satisfying it is well defined.
+func ParseGTIDExpr(value string) (GTIDExpr, error) {
+	p := &exprParser{input: value}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", value, err)
+	}
+	if p.skipSpace(); p.pos < len(p.input) {
+		return nil, fmt.Errorf("invalid expression %q: unexpected %q at offset %d", value, p.input[p.pos:], p.pos)
+	}
+	return expr, nil
+}
+
+// setTerm is contains(set), or any(set) when any is true
+type setTerm struct {
+	any bool
+	set *mysql.MysqlGTIDSet
+}
+
+func (t *setTerm) Eval(executed *mysql.MysqlGTIDSet) bool {
+	if !t.any {
+		return executed.Contain(t.set)
+	}
+	for key, want := range t.set.Sets {
+		have, ok := executed.Sets[key]
+		if !ok {
+			continue
+		}
+		for _, a := range want.Intervals {
+			for _, b := range have.Intervals {
+				if a.Start < b.Stop && b.Start < a.Stop { // Half-open intervals overlap
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (t *setTerm) Sets() []mysql.GTIDSet {
+	return []mysql.GTIDSet{t.set}
+}
+
+func (t *setTerm) String() string {
+	if t.any {
+		return "any(" + t.set.String() + ")"
+	}
+	return "contains(" + t.set.String() + ")"
+}
+
+// binaryExpr is left and right, or left or right when or is true
+type binaryExpr struct {
+	or          bool
+	left, right GTIDExpr
+}
+
+func (e *binaryExpr) Eval(executed *mysql.MysqlGTIDSet) bool {
+	if e.or {
+		return e.left.Eval(executed) || e.right.Eval(executed)
+	}
+	return e.left.Eval(executed) && e.right.Eval(executed)
+}
+
+func (e *binaryExpr) Sets() []mysql.GTIDSet {
+	return append(e.left.Sets(), e.right.Sets()...)
+}
+
+func (e *binaryExpr) String() string {
+	if e.or {
+		return e.left.String() + " or " + e.right.String()
+	}
+	return operandString(e.left) + " and " + operandString(e.right)
+}
+
+// operandString parenthesizes an "or" operand of "and"
+func operandString(e GTIDExpr) string {
+	if b, ok := e.(*binaryExpr); ok && b.or {
+		return "(" + b.String() + ")"
+	}
+	return e.String()
+}
+
+// exprParser is a recursive descent parser over the expression text
+type exprParser struct {
+	input string
+	pos   int
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// word returns the identifier at the current offset without consuming it
+func (p *exprParser) word() string {
+	p.skipSpace()
+	end := p.pos
+	for end < len(p.input) && (unicode.IsLetter(rune(p.input[end])) || p.input[end] == '_') {
+		end++
+	}
+	return strings.ToLower(p.input[p.pos:end])
+}
+
+// accept consumes the keyword kw when it is next
+func (p *exprParser) accept(kw string) bool {
+	if p.word() != kw {
+		return false
+	}
+	p.pos += len(kw)
+	return true
+}
+
+func (p *exprParser) parseOr() (GTIDExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{or: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (GTIDExpr, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("and") {
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseTerm() (GTIDExpr, error) {
+	p.skipSpace()
+	if p.pos < len(p.input) && p.input[p.pos] == '(' {
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.skipSpace(); p.pos >= len(p.input) || p.input[p.pos] != ')' {
+			return nil, fmt.Errorf("missing ) at offset %d", p.pos)
+		}
+		p.pos++
+		return expr, nil
+	}
+
+	name := p.word()
+	if name != "contains" && name != "any" {
+		if name == "" {
+			return nil, fmt.Errorf("expected contains(set), any(set) or ( at offset %d", p.pos)
+		}
+		return nil, fmt.Errorf("unknown function %q at offset %d, expected contains or any", name, p.pos)
+	}
+	p.pos += len(name)
+	if p.skipSpace(); p.pos >= len(p.input) || p.input[p.pos] != '(' {
+		return nil, fmt.Errorf("missing ( after %s at offset %d", name, p.pos)
+	}
+
+	// GTID sets hold no parentheses, the argument runs to the next one
+	arg, _, ok := strings.Cut(p.input[p.pos+1:], ")")
+	if !ok {
+		return nil, fmt.Errorf("missing ) after %s( at offset %d", name, p.pos)
+	}
+	p.pos += len(arg) + 2
+
+	set, err := ParseGTID(arg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	mysqlSet, ok := set.(*mysql.MysqlGTIDSet)
+	if !ok || len(mysqlSet.Sets) == 0 {
+		return nil, fmt.Errorf("%s needs a non-empty MySQL GTID set: %q", name, arg)
+	}
+	return &setTerm{any: name == "any", set: mysqlSet}, nil
+}
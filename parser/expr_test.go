@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+const (
+	exprUUIDA = "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	exprUUIDB = "a1b2c3d4-71ca-11e1-9e33-c80aa9429562"
+)
+
+func TestParseGTIDExpr(t *testing.T) {
+	tests := []struct {
+		name    string
+		expr    string
+		want    string // Canonical form
+		wantErr bool
+	}{
+		{name: "contains", expr: "contains(" + exprUUIDA + ":1-10)", want: "contains(" + exprUUIDA + ":1-10)"},
+		{name: "and binds tighter than or", expr: "any(" + exprUUIDA + ":5) OR contains(" + exprUUIDA + ":1-3) and any(" + exprUUIDB + ":1)",
+			want: "any(" + exprUUIDA + ":5) or contains(" + exprUUIDA + ":1-3) and any(" + exprUUIDB + ":1)"},
+		{name: "parentheses kept under and", expr: "(any(" + exprUUIDA + ":5) or any(" + exprUUIDB + ":1)) and contains(" + exprUUIDA + ":1)",
+			want: "(any(" + exprUUIDA + ":5) or any(" + exprUUIDB + ":1)) and contains(" + exprUUIDA + ":1)"},
+		{name: "multi-UUID set with spaces", expr: "contains( " + exprUUIDB + ":1-2,\n " + exprUUIDA + ":7 )",
+			want: "contains(" + exprUUIDA + ":7," + exprUUIDB + ":1-2)"},
+		{name: "empty", expr: "", wantErr: true},
+		{name: "unknown function", expr: "missing(" + exprUUIDA + ":1)", wantErr: true},
+		{name: "not is not supported", expr: "not any(" + exprUUIDA + ":1)", wantErr: true},
+		{name: "invalid set", expr: "any(abc:1)", wantErr: true},
+		{name: "empty set", expr: "any()", wantErr: true},
+		{name: "unclosed", expr: "(any(" + exprUUIDA + ":1)", wantErr: true},
+		{name: "trailing text", expr: "any(" + exprUUIDA + ":1) and", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseGTIDExpr(tt.expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGTIDExpr() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && expr.String() != tt.want {
+				t.Errorf("ParseGTIDExpr() = %s, want %s", expr.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestGTIDExprEval(t *testing.T) {
+	expr, err := ParseGTIDExpr("contains(" + exprUUIDA + ":1-10) and any(" + exprUUIDB + ":5-8)")
+	if err != nil {
+		t.Fatalf("ParseGTIDExpr() error = %v", err)
+	}
+
+	tests := []struct {
+		executed string
+		want     bool
+	}{
+		{executed: exprUUIDA + ":1-10", want: false},
+		{executed: exprUUIDA + ":1-9," + exprUUIDB + ":5", want: false},
+		{executed: exprUUIDA + ":1-10," + exprUUIDB + ":1-4", want: false},
+		{executed: exprUUIDA + ":1-10," + exprUUIDB + ":1-4:9", want: false},
+		{executed: exprUUIDA + ":1-10," + exprUUIDB + ":8", want: true},
+		{executed: exprUUIDA + ":1-20," + exprUUIDB + ":1-100", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.executed, func(t *testing.T) {
+			executed, err := mysql.ParseMysqlGTIDSet(tt.executed)
+			if err != nil {
+				t.Fatalf("Invalid executed set: %v", err)
+			}
+			if got := expr.Eval(executed.(*mysql.MysqlGTIDSet)); got != tt.want {
+				t.Errorf("Eval(%s) = %v, want %v", tt.executed, got, tt.want)
+			}
+		})
+	}
+
+	if sets := expr.Sets(); len(sets) != 2 {
+		t.Errorf("Sets() returned %d sets, want 2", len(sets))
+	}
+}
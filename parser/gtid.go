@@ -4,20 +4,26 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"unicode"
 
 	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/google/uuid"
 )
 
 // ParseGTID parses a GTID string into GTIDSet
 // Supports MySQL GTID format: server_uuid:transaction_id
 // Example: 3E11FA47-71CA-11E1-9E33-C80AA9429562:23
+// Sets pasted from SHOW MASTER STATUS or SET gtid_purged may span lines and
+// start with "+" (see NormalizeGTIDSet).
 func ParseGTID(gtidStr string) (mysql.GTIDSet, error) {
 	if gtidStr == "" {
 		return nil, fmt.Errorf("GTID string cannot be empty")
 	}
 
-	gtidStr = strings.TrimSpace(gtidStr)
+	gtidStr = NormalizeGTIDSet(gtidStr)
 	
 	gtidSet, err := mysql.ParseMysqlGTIDSet(gtidStr)
 	if err != nil {
@@ -27,6 +33,13 @@ func ParseGTID(gtidStr string) (mysql.GTIDSet, error) {
 	return gtidSet, nil
 }
 
+// NormalizeGTIDSet removes the whitespace and newlines MySQL prints inside GTID sets
+// ("uuid1:1-5,\n uuid2:1-3") and the leading "+" of SET gtid_purged = '+...'
+func NormalizeGTIDSet(gtidStr string) string {
+	gtidStr = strings.Join(strings.Fields(gtidStr), "")
+	return strings.TrimPrefix(gtidStr, "+")
+}
+
 // ParseGTIDFile reads GTIDs from a file (one per line)
 // Returns a slice of GTIDSet for batch processing
 func ParseGTIDFile(filepath string) ([]mysql.GTIDSet, error) {
@@ -68,6 +81,111 @@ func ParseGTIDFile(filepath string) ([]mysql.GTIDSet, error) {
 	return gtidSets, nil
 }
 
+// ReadGTIDSetFile reads a single GTID set from a file, e.g. gtid_executed saved
+// from SHOW MASTER STATUS. It may span lines and UUIDs; "#" starts a comment.
+// The set is returned normalized, as a -gtid value.
+func ReadGTIDSetFile(filepath string) (string, error) {
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read GTID set file: %w", err)
+	}
+
+	var set strings.Builder
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		set.WriteString(line)
+	}
+
+	gtidStr := NormalizeGTIDSet(set.String())
+	if gtidStr == "" {
+		return "", fmt.Errorf("no GTID set found in file")
+	}
+	if strings.Contains(gtidStr, GTIDListSeparator) {
+		return "", fmt.Errorf("GTID set file holds one set, use a GTID file for several")
+	}
+	if _, err := ParseGTID(gtidStr); err != nil {
+		return "", err
+	}
+	return gtidStr, nil
+}
+
+// GTIDListSeparator separates independent GTID sets given on one line, since the
+// comma is already used inside a set
+const GTIDListSeparator = ";"
+
+// ParseGTIDList parses semicolon-separated GTID sets ("uuid:5;uuid:9;uuid2:3"),
+// each searched on its own as a line of a GTID file. Empty items are skipped.
+func ParseGTIDList(value string) ([]mysql.GTIDSet, error) {
+	var gtidSets []mysql.GTIDSet
+	for i, item := range strings.Split(value, GTIDListSeparator) {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+
+		gtidSet, err := ParseGTID(item)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i+1, err)
+		}
+		gtidSets = append(gtidSets, gtidSet)
+	}
+
+	if len(gtidSets) == 0 {
+		return nil, fmt.Errorf("no valid GTIDs found in list")
+	}
+	return gtidSets, nil
+}
+
+// DedupeGTIDSets drops the sets equal to an earlier one, keeping the first in order.
+// Sets are compared in canonical form, so "UUID:1-3,UUID:4" equals "uuid:1-4".
+func DedupeGTIDSets(gtidSets []mysql.GTIDSet) (unique []mysql.GTIDSet, duplicates int) {
+	seen := make(map[string]bool, len(gtidSets))
+	for _, gtidSet := range gtidSets {
+		key := gtidSet.String()
+		if seen[key] {
+			duplicates++
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, gtidSet)
+	}
+	return unique, duplicates
+}
+
+// ParseUUIDList parses a list of server UUIDs: comma or whitespace separated, or
+// the path of a file holding them (one or more per line, "#" comments allowed).
+// UUIDs are returned lowercase, in the format of GTID sets.
+func ParseUUIDList(value string) ([]string, error) {
+	content := value
+	if info, err := os.Stat(value); err == nil && info.Mode().IsRegular() {
+		data, err := os.ReadFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read UUID list: %w", err)
+		}
+		content = string(data)
+	}
+
+	var uuids []string
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		for _, field := range strings.FieldsFunc(line, func(r rune) bool { return r == ',' || unicode.IsSpace(r) }) {
+			parsed, err := uuid.Parse(field)
+			if err != nil {
+				return nil, fmt.Errorf("invalid server UUID %q: %w", field, err)
+			}
+			uuids = append(uuids, parsed.String())
+		}
+	}
+
+	if len(uuids) == 0 {
+		return nil, fmt.Errorf("no server UUIDs found")
+	}
+	return uuids, nil
+}
+
 // ValidateGTIDFormat checks if a string matches GTID format
 // without fully parsing it (lightweight validation)
 func ValidateGTIDFormat(gtidStr string) error {
@@ -100,7 +218,8 @@ type UUIDInfo struct {
 	TotalCount     uint64
 }
 
-// ExtractUUIDs extracts all UUIDs from a GTID set with their transaction info
+// ExtractUUIDs extracts all UUIDs from a GTID set with their transaction info,
+// sorted by UUID
 func ExtractUUIDs(gtidSet *mysql.GTIDSet) ([]UUIDInfo, error) {
 	if gtidSet == nil {
 		return nil, fmt.Errorf("GTID set cannot be nil")
@@ -136,11 +255,17 @@ func ExtractUUIDs(gtidSet *mysql.GTIDSet) ([]UUIDInfo, error) {
 		uuidInfos = append(uuidInfos, info)
 	}
 
+	// Map order is random, keep output and tie-breaking reproducible
+	sort.Slice(uuidInfos, func(i, j int) bool {
+		return uuidInfos[i].UUID < uuidInfos[j].UUID
+	})
+
 	return uuidInfos, nil
 }
 
 // FindActiveMasterUUID finds the UUID with the highest transaction number
 // This is typically the current/active master in a multi-master setup
+// On a tie the lowest UUID wins
 func FindActiveMasterUUID(gtidSet *mysql.GTIDSet) (string, error) {
 	uuidInfos, err := ExtractUUIDs(gtidSet)
 	if err != nil {
@@ -162,11 +287,16 @@ func FindActiveMasterUUID(gtidSet *mysql.GTIDSet) (string, error) {
 	return activeMaster.UUID, nil
 }
 
-// FilterByUUID creates a new GTID set containing only the specified UUID
+// FilterByUUID creates a new GTID set containing only the specified UUID.
+// targetUUID may be a prefix of the UUID (e.g. "3e11fa47"), case-insensitive;
+// a prefix matching several UUIDs of the set is an error.
 func FilterByUUID(gtidSet *mysql.GTIDSet, targetUUID string) (mysql.GTIDSet, error) {
 	if gtidSet == nil {
 		return nil, fmt.Errorf("GTID set cannot be nil")
 	}
+	if targetUUID == "" {
+		return nil, fmt.Errorf("UUID cannot be empty")
+	}
 
 	// Get the underlying MysqlGTIDSet
 	mysqlSet, ok := (*gtidSet).(*mysql.MysqlGTIDSet)
@@ -174,20 +304,29 @@ func FilterByUUID(gtidSet *mysql.GTIDSet, targetUUID string) (mysql.GTIDSet, err
 		return nil, fmt.Errorf("expected MysqlGTIDSet type")
 	}
 
-	// Find the target UUID in the set
-	for uuid, intervals := range mysqlSet.Sets {
-		if uuid == targetUUID {
-			// Create a new GTID set with only this UUID
-			newSet := &mysql.MysqlGTIDSet{
-				Sets: map[string]*mysql.UUIDSet{
-					uuid: intervals,
-				},
-			}
-			return newSet, nil
+	// Find the UUIDs of the set starting with the target
+	prefix := strings.ToLower(targetUUID)
+	var matches []string
+	for uuid := range mysqlSet.Sets {
+		if strings.HasPrefix(uuid, prefix) {
+			matches = append(matches, uuid)
 		}
 	}
 
-	return nil, fmt.Errorf("UUID %s not found in GTID set", targetUUID)
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("UUID %s not found in GTID set", targetUUID)
+	case 1:
+		// Create a new GTID set with only this UUID
+		return &mysql.MysqlGTIDSet{
+			Sets: map[string]*mysql.UUIDSet{
+				matches[0]: mysqlSet.Sets[matches[0]],
+			},
+		}, nil
+	default:
+		sort.Strings(matches)
+		return nil, fmt.Errorf("UUID prefix %s is ambiguous, it matches %s", targetUUID, strings.Join(matches, ", "))
+	}
 }
 
 // ExtractGTIDInfo extracts UUID and GNO from a GTID string
@@ -208,3 +347,57 @@ func ExtractGTIDInfo(gtidStr string) (uuid string, gno uint64, err error) {
 
 	return uuid, gno, nil
 }
+
+// MatchInterval returns the interval of set, first and last GNO, holding uuid:gno,
+// e.g. [100 150] for gno 120 in UUID:1-50:100-150. ok is false when set does not contain it.
+func MatchInterval(set mysql.GTIDSet, uuid string, gno uint64) (interval [2]uint64, ok bool) {
+	mysqlSet, isMysql := set.(*mysql.MysqlGTIDSet)
+	if !isMysql {
+		return interval, false
+	}
+
+	uuidSet, found := mysqlSet.Sets[strings.ToLower(uuid)]
+	if !found {
+		return interval, false
+	}
+
+	// Intervals are half-open [Start, Stop)
+	for _, iv := range uuidSet.Intervals {
+		if int64(gno) >= iv.Start && int64(gno) < iv.Stop {
+			return [2]uint64{uint64(iv.Start), uint64(iv.Stop - 1)}, true
+		}
+	}
+	return interval, false
+}
+
+// SubtractGTIDSet returns the transactions in a that are not in b (a \ b)
+// Neither input set is modified
+func SubtractGTIDSet(a, b *mysql.GTIDSet) (mysql.GTIDSet, error) {
+	if a == nil || b == nil {
+		return nil, fmt.Errorf("GTID set cannot be nil")
+	}
+
+	minuend, ok := (*a).(*mysql.MysqlGTIDSet)
+	if !ok {
+		return nil, fmt.Errorf("expected MysqlGTIDSet type")
+	}
+	subtrahend, ok := (*b).(*mysql.MysqlGTIDSet)
+	if !ok {
+		return nil, fmt.Errorf("expected MysqlGTIDSet type")
+	}
+
+	result := minuend.Clone().(*mysql.MysqlGTIDSet)
+	if err := result.Minus(*subtrahend); err != nil {
+		return nil, fmt.Errorf("failed to subtract GTID sets: %w", err)
+	}
+
+	return result, nil
+}
+
+// FormatGTIDFromEvent returns the server UUID of a GTID event and its UUID:GNO string
+func FormatGTIDFromEvent(gtidEvent *replication.GTIDEvent) (uuid, gtid string) {
+	uuid = fmt.Sprintf("%x-%x-%x-%x-%x",
+		gtidEvent.SID[0:4], gtidEvent.SID[4:6], gtidEvent.SID[6:8],
+		gtidEvent.SID[8:10], gtidEvent.SID[10:16])
+	return uuid, fmt.Sprintf("%s:%d", uuid, gtidEvent.GNO)
+}
@@ -3,15 +3,16 @@ package parser
 import (
 	"testing"
 
-
+	"github.com/go-mysql-org/go-mysql/mysql"
 )
 
 func TestExtractUUIDs(t *testing.T) {
 	tests := []struct {
-		name    string
-		gtidStr string
-		wantLen int
-		wantErr bool
+		name      string
+		gtidStr   string
+		wantLen   int
+		wantUUIDs []string // In order, checked when set
+		wantErr   bool
 	}{
 		{
 			name:    "single UUID",
@@ -25,6 +26,12 @@ func TestExtractUUIDs(t *testing.T) {
 			wantLen: 2,
 			wantErr: false,
 		},
+		{
+			name:      "sorted by UUID",
+			gtidStr:   "f1b2c3d4-71ca-11e1-9e33-c80aa9429562:1-10,3e11fa47-71ca-11e1-9e33-c80aa9429562:1-100,a1b2c3d4-71ca-11e1-9e33-c80aa9429562:1-50",
+			wantLen:   3,
+			wantUUIDs: []string{"3e11fa47-71ca-11e1-9e33-c80aa9429562", "a1b2c3d4-71ca-11e1-9e33-c80aa9429562", "f1b2c3d4-71ca-11e1-9e33-c80aa9429562"},
+		},
 		{
 			name:    "nil GTID set",
 			gtidStr: "",
@@ -58,6 +65,16 @@ func TestExtractUUIDs(t *testing.T) {
 				t.Errorf("ExtractUUIDs() got %d UUIDs, want %d", len(uuidInfos), tt.wantLen)
 			}
 
+			// Same order on every run, whatever the map iteration order
+			for run := 0; run < 20 && len(tt.wantUUIDs) > 0; run++ {
+				uuidInfos, _ := ExtractUUIDs(&gtidSet)
+				for i, info := range uuidInfos {
+					if info.UUID != tt.wantUUIDs[i] {
+						t.Fatalf("ExtractUUIDs() run %d: UUID %d = %s, want %s", run, i, info.UUID, tt.wantUUIDs[i])
+					}
+				}
+			}
+
 			// Verify UUID info fields
 			for _, info := range uuidInfos {
 				if info.UUID == "" {
@@ -94,6 +111,11 @@ func TestFindActiveMasterUUID(t *testing.T) {
 			checkMaxGNO: true,
 			wantErr:     false,
 		},
+		{
+			name:     "tie goes to the lowest UUID",
+			gtidStr:  "f1b2c3d4-71ca-11e1-9e33-c80aa9429562:1-50,a1b2c3d4-71ca-11e1-9e33-c80aa9429562:1-50",
+			wantUUID: "a1b2c3d4-71ca-11e1-9e33-c80aa9429562",
+		},
 		{
 			name:    "nil GTID set",
 			wantErr: true,
@@ -124,6 +146,9 @@ func TestFindActiveMasterUUID(t *testing.T) {
 			if !tt.wantErr && uuid == "" {
 				t.Error("FindActiveMasterUUID() returned empty UUID")
 			}
+			if tt.wantUUID != "" && uuid != tt.wantUUID {
+				t.Errorf("FindActiveMasterUUID() = %s, want %s", uuid, tt.wantUUID)
+			}
 
 			// Verify it's the UUID with highest transaction number
 			if tt.checkMaxGNO {
@@ -154,6 +179,7 @@ func TestFilterByUUID(t *testing.T) {
 		name       string
 		gtidStr    string
 		filterUUID string
+		wantUUID   string // Resolved UUID, the filter itself if empty
 		wantErr    bool
 	}{
 		{
@@ -168,6 +194,24 @@ func TestFilterByUUID(t *testing.T) {
 			filterUUID: "ffffffff-ffff-ffff-ffff-ffffffffffff",
 			wantErr:    true,
 		},
+		{
+			name:       "filter by prefix",
+			gtidStr:    "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-100,a1b2c3d4-71ca-11e1-9e33-c80aa9429562:1-50",
+			filterUUID: "3E11FA47",
+			wantUUID:   "3e11fa47-71ca-11e1-9e33-c80aa9429562",
+		},
+		{
+			name:       "filter by prefix with hyphen",
+			gtidStr:    "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-100,a1b2c3d4-71ca-11e1-9e33-c80aa9429562:1-50",
+			filterUUID: "a1b2c3d4-71ca",
+			wantUUID:   "a1b2c3d4-71ca-11e1-9e33-c80aa9429562",
+		},
+		{
+			name:       "ambiguous prefix",
+			gtidStr:    "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-100,3e11fa47-0000-11e1-9e33-c80aa9429562:1-50",
+			filterUUID: "3e11fa47",
+			wantErr:    true,
+		},
 		{
 			name:       "nil GTID set",
 			filterUUID: "3e11fa47-71ca-11e1-9e33-c80aa9429562",
@@ -202,6 +246,13 @@ func TestFilterByUUID(t *testing.T) {
 				if len(uuidInfos) != 1 {
 					t.Errorf("FilterByUUID() returned %d UUIDs, want 1", len(uuidInfos))
 				}
+				wantUUID := tt.wantUUID
+				if wantUUID == "" {
+					wantUUID = tt.filterUUID
+				}
+				if len(uuidInfos) == 1 && uuidInfos[0].UUID != wantUUID {
+					t.Errorf("FilterByUUID() kept %s, want %s", uuidInfos[0].UUID, wantUUID)
+				}
 			}
 		})
 	}
@@ -257,6 +308,36 @@ func TestExtractGTIDInfo(t *testing.T) {
 	}
 }
 
+func TestMatchInterval(t *testing.T) {
+	set, err := ParseGTID("3E11FA47-71CA-11E1-9E33-C80AA9429562:1-50:100-150,22f7ce9e-7f4c-11ef-8423-3a25d006dfee:7")
+	if err != nil {
+		t.Fatalf("ParseGTID() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		uuid   string
+		gno    uint64
+		want   [2]uint64
+		wantOK bool
+	}{
+		{name: "first interval", uuid: "3e11fa47-71ca-11e1-9e33-c80aa9429562", gno: 50, want: [2]uint64{1, 50}, wantOK: true},
+		{name: "second interval", uuid: "3E11FA47-71CA-11E1-9E33-C80AA9429562", gno: 100, want: [2]uint64{100, 150}, wantOK: true},
+		{name: "single GTID", uuid: "22f7ce9e-7f4c-11ef-8423-3a25d006dfee", gno: 7, want: [2]uint64{7, 7}, wantOK: true},
+		{name: "in the gap", uuid: "3e11fa47-71ca-11e1-9e33-c80aa9429562", gno: 75},
+		{name: "unknown UUID", uuid: "0e95f562-6c20-11ef-bec4-5eeba390a904", gno: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := MatchInterval(set, tt.uuid, tt.gno)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("MatchInterval() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
 func TestParseGTID_MultiUUID(t *testing.T) {
 	// Test với GTID example của user
 	gtidStr := "0e95f562-6c20-11ef-bec4-5eeba390a904:1-12771309078,22f7ce9e-7f4c-11ef-8423-3a25d006dfee:1-3"
@@ -297,3 +378,77 @@ func TestParseGTID_MultiUUID(t *testing.T) {
 
 	t.Logf("Active master UUID: %s (max GNO: %d)", activeMasterUUID, maxGNO)
 }
+
+func TestSubtractGTIDSet(t *testing.T) {
+	tests := []struct {
+		name      string
+		minuend   string
+		subtract  string
+		want      string
+		wantEmpty bool
+	}{
+		{
+			name:     "replica behind master",
+			minuend:  "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-100",
+			subtract: "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-40",
+			want:     "3e11fa47-71ca-11e1-9e33-c80aa9429562:41-100",
+		},
+		{
+			name:     "gap in the middle",
+			minuend:  "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-100",
+			subtract: "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-40:61-100",
+			want:     "3e11fa47-71ca-11e1-9e33-c80aa9429562:41-60",
+		},
+		{
+			name:     "UUID missing on replica",
+			minuend:  "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-100,a1b2c3d4-71ca-11e1-9e33-c80aa9429562:1-5",
+			subtract: "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-100",
+			want:     "a1b2c3d4-71ca-11e1-9e33-c80aa9429562:1-5",
+		},
+		{
+			name:      "replica up to date",
+			minuend:   "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-100",
+			subtract:  "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-200",
+			wantEmpty: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, err := ParseGTID(tt.minuend)
+			if err != nil {
+				t.Fatalf("ParseGTID() error = %v", err)
+			}
+			b, err := ParseGTID(tt.subtract)
+			if err != nil {
+				t.Fatalf("ParseGTID() error = %v", err)
+			}
+
+			diff, err := SubtractGTIDSet(&a, &b)
+			if err != nil {
+				t.Fatalf("SubtractGTIDSet() error = %v", err)
+			}
+
+			if tt.wantEmpty {
+				if !diff.(*mysql.MysqlGTIDSet).IsEmpty() {
+					t.Errorf("SubtractGTIDSet() = %s, want empty set", diff.String())
+				}
+				return
+			}
+			if diff.String() != tt.want {
+				t.Errorf("SubtractGTIDSet() = %s, want %s", diff.String(), tt.want)
+			}
+
+			// Inputs must not be modified
+			if a.String() != tt.minuend {
+				t.Errorf("SubtractGTIDSet() modified minuend: %s", a.String())
+			}
+		})
+	}
+
+	t.Run("nil GTID set", func(t *testing.T) {
+		if _, err := SubtractGTIDSet(nil, nil); err == nil {
+			t.Error("SubtractGTIDSet() expected error for nil set")
+		}
+	})
+}
@@ -3,7 +3,10 @@ package parser
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/go-mysql-org/go-mysql/replication"
 )
 
 func TestParseGTID(t *testing.T) {
@@ -54,6 +57,91 @@ func TestParseGTID(t *testing.T) {
 	}
 }
 
+func TestParseGTID_MultiLine(t *testing.T) {
+	// Executed_Gtid_Set as printed by SHOW MASTER STATUS\G for a multi-source replica
+	gtidStr := `0e95f562-6c20-11ef-bec4-5eeba390a904:1-12771309078,
+22f7ce9e-7f4c-11ef-8423-3a25d006dfee:1-3:5-9,
+	7396024d-8ec5-11f0-b6ea-fa163e91516e:1-5795043
+`
+	want := "0e95f562-6c20-11ef-bec4-5eeba390a904:1-12771309078," +
+		"22f7ce9e-7f4c-11ef-8423-3a25d006dfee:1-3:5-9," +
+		"7396024d-8ec5-11f0-b6ea-fa163e91516e:1-5795043"
+
+	gtidSet, err := ParseGTID(gtidStr)
+	if err != nil {
+		t.Fatalf("ParseGTID() error = %v", err)
+	}
+	if gtidSet.String() != want {
+		t.Errorf("ParseGTID() = %s, want %s", gtidSet.String(), want)
+	}
+
+	// SET GLOBAL gtid_purged = '+...' appends to the purged set
+	gtidSet, err = ParseGTID("+0e95f562-6c20-11ef-bec4-5eeba390a904:1-100")
+	if err != nil {
+		t.Fatalf("ParseGTID() with + prefix error = %v", err)
+	}
+	if want := "0e95f562-6c20-11ef-bec4-5eeba390a904:1-100"; gtidSet.String() != want {
+		t.Errorf("ParseGTID() = %s, want %s", gtidSet.String(), want)
+	}
+}
+
+func TestDedupeGTIDSets(t *testing.T) {
+	gtidSets, err := ParseGTIDList("3E11FA47-71CA-11E1-9E33-C80AA9429562:1-3,3E11FA47-71CA-11E1-9E33-C80AA9429562:4;" +
+		"3e11fa47-71ca-11e1-9e33-c80aa9429562:9;3e11fa47-71ca-11e1-9e33-c80aa9429562:1-4;3e11fa47-71ca-11e1-9e33-c80aa9429562:9")
+	if err != nil {
+		t.Fatalf("ParseGTIDList() error = %v", err)
+	}
+
+	unique, duplicates := DedupeGTIDSets(gtidSets)
+	if duplicates != 2 {
+		t.Errorf("DedupeGTIDSets() duplicates = %d, want 2", duplicates)
+	}
+	want := []string{"3e11fa47-71ca-11e1-9e33-c80aa9429562:1-4", "3e11fa47-71ca-11e1-9e33-c80aa9429562:9"}
+	if len(unique) != len(want) {
+		t.Fatalf("DedupeGTIDSets() kept %d sets, want %d", len(unique), len(want))
+	}
+	for i, gtidSet := range unique {
+		if gtidSet.String() != want[i] {
+			t.Errorf("DedupeGTIDSets()[%d] = %s, want %s", i, gtidSet, want[i])
+		}
+	}
+}
+
+func TestParseUUIDList(t *testing.T) {
+	const uuidA = "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	const uuidB = "22f7ce9e-7f4c-11ef-8423-3a25d006dfee"
+
+	listFile := filepath.Join(t.TempDir(), "allowed.txt")
+	content := "# Production masters\n" + uuidA + "\n\n" + strings.ToUpper(uuidB) + " # old master\n"
+	if err := os.WriteFile(listFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write list: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{name: "comma list", value: uuidA + ", " + uuidB, want: []string{uuidA, uuidB}},
+		{name: "file", value: listFile, want: []string{uuidA, uuidB}},
+		{name: "invalid UUID", value: uuidA + ",not-a-uuid", wantErr: true},
+		{name: "empty", value: " , ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseUUIDList(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseUUIDList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ParseUUIDList() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestValidateGTIDFormat(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -178,3 +266,97 @@ invalid-gtid
 		}
 	})
 }
+
+func TestReadGTIDSetFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "multi-line multi-UUID set",
+			content: `# gtid_executed of db1
+3E11FA47-71CA-11E1-9E33-C80AA9429562:1-300,
+  22f7ce9e-7f4c-11ef-8423-3a25d006dfee:1-3
+`,
+			want: "3E11FA47-71CA-11E1-9E33-C80AA9429562:1-300,22f7ce9e-7f4c-11ef-8423-3a25d006dfee:1-3",
+		},
+		{name: "single GTID", content: "3E11FA47-71CA-11E1-9E33-C80AA9429562:23\n", want: "3E11FA47-71CA-11E1-9E33-C80AA9429562:23"},
+		{name: "several sets", content: "3E11FA47-71CA-11E1-9E33-C80AA9429562:5;3E11FA47-71CA-11E1-9E33-C80AA9429562:9", wantErr: true},
+		{name: "invalid set", content: "invalid-gtid", wantErr: true},
+		{name: "only comments", content: "# nothing\n\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setFile := filepath.Join(t.TempDir(), "gtid_executed.txt")
+			if err := os.WriteFile(setFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			got, err := ReadGTIDSetFile(setFile)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadGTIDSetFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ReadGTIDSetFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ReadGTIDSetFile("/non/existent/file.txt"); err == nil {
+		t.Error("ReadGTIDSetFile() expected error for non-existent file")
+	}
+}
+
+func TestParseGTIDList(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:  "several sets",
+			value: "3E11FA47-71CA-11E1-9E33-C80AA9429562:5;3E11FA47-71CA-11E1-9E33-C80AA9429562:9;22f7ce9e-7f4c-11ef-8423-3a25d006dfee:3",
+			want:  []string{"3e11fa47-71ca-11e1-9e33-c80aa9429562:5", "3e11fa47-71ca-11e1-9e33-c80aa9429562:9", "22f7ce9e-7f4c-11ef-8423-3a25d006dfee:3"},
+		},
+		{
+			name:  "sets with commas and spaces",
+			value: "3E11FA47-71CA-11E1-9E33-C80AA9429562:1-5, 22f7ce9e-7f4c-11ef-8423-3a25d006dfee:1-3 ; 3E11FA47-71CA-11E1-9E33-C80AA9429562:9;",
+			want:  []string{"22f7ce9e-7f4c-11ef-8423-3a25d006dfee:1-3,3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5", "3e11fa47-71ca-11e1-9e33-c80aa9429562:9"},
+		},
+		{name: "invalid item", value: "3E11FA47-71CA-11E1-9E33-C80AA9429562:5;invalid-gtid", wantErr: true},
+		{name: "empty", value: " ; ", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gtids, err := ParseGTIDList(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseGTIDList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(gtids) != len(tt.want) {
+				t.Fatalf("ParseGTIDList() got %d GTID sets, want %d", len(gtids), len(tt.want))
+			}
+			for i, gtid := range gtids {
+				if gtid.String() != tt.want[i] {
+					t.Errorf("ParseGTIDList()[%d] = %s, want %s", i, gtid, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestFormatGTIDFromEvent(t *testing.T) {
+	sid := []byte{0x3e, 0x11, 0xfa, 0x47, 0x71, 0xca, 0x11, 0xe1, 0x9e, 0x33, 0xc8, 0x0a, 0xa9, 0x42, 0x95, 0x62}
+
+	uuid, gtid := FormatGTIDFromEvent(&replication.GTIDEvent{SID: sid, GNO: 42})
+	if uuid != "3e11fa47-71ca-11e1-9e33-c80aa9429562" {
+		t.Errorf("FormatGTIDFromEvent() uuid = %q", uuid)
+	}
+	if gtid != "3e11fa47-71ca-11e1-9e33-c80aa9429562:42" {
+		t.Errorf("FormatGTIDFromEvent() gtid = %q", gtid)
+	}
+}
@@ -0,0 +1,38 @@
+package searcher
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+// uuidAllowed reports whether transactions of a server UUID may match.
+// Every UUID is allowed without Config.AllowedUUIDs.
+func (s *Searcher) uuidAllowed(uuidStr string) bool {
+	return len(s.config.AllowedUUIDs) == 0 || slices.Contains(s.config.AllowedUUIDs, strings.ToLower(uuidStr))
+}
+
+// CheckAllowedUUIDs returns an error when the target set references a server UUID
+// outside Config.AllowedUUIDs, so a search cannot land on a rogue or retired server's
+// transactions
+func (s *Searcher) CheckAllowedUUIDs(targetGTID *mysql.GTIDSet) error {
+	target, ok := (*targetGTID).(*mysql.MysqlGTIDSet)
+	if !ok || len(s.config.AllowedUUIDs) == 0 {
+		return nil
+	}
+
+	var disallowed []string
+	for uuidStr := range target.Sets {
+		if !s.uuidAllowed(uuidStr) {
+			disallowed = append(disallowed, uuidStr)
+		}
+	}
+	if len(disallowed) > 0 {
+		sort.Strings(disallowed)
+		return fmt.Errorf("GTID set references server UUIDs not in the allowed list: %s", strings.Join(disallowed, ", "))
+	}
+	return nil
+}
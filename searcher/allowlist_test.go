@@ -0,0 +1,76 @@
+package searcher
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+const rogueUUID = "22f7ce9e-7f4c-11ef-8423-3a25d006dfee"
+
+func TestCheckAllowedUUIDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		gtid    string
+		wantErr bool
+	}{
+		{name: "no allowlist", gtid: rogueUUID + ":1", wantErr: false},
+		{name: "allowed", allowed: []string{testutil.FixtureUUID}, gtid: testutil.FixtureUUID + ":1-10", wantErr: false},
+		{name: "disallowed", allowed: []string{testutil.FixtureUUID}, gtid: testutil.FixtureUUID + ":1-10," + rogueUUID + ":1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targetGTID, _ := mysql.ParseMysqlGTIDSet(tt.gtid)
+			searcher := NewSearcher(&models.Config{AllowedUUIDs: tt.allowed})
+			if err := searcher.CheckAllowedUUIDs(&targetGTID); (err != nil) != tt.wantErr {
+				t.Errorf("CheckAllowedUUIDs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAllowedUUIDs_IgnoresOtherServers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mysql-bin.000001")
+	binlog := &testutil.Binlog{Transactions: []testutil.Transaction{
+		{UUID: testutil.FixtureUUID, GNO: 1},
+		{UUID: rogueUUID, GNO: 1},
+		{UUID: testutil.FixtureUUID, GNO: 2},
+	}}
+	if err := binlog.WriteFile(path); err != nil {
+		t.Fatalf("Failed to write binlog: %v", err)
+	}
+	files := []string{path}
+
+	config := &models.Config{AllowedUUIDs: []string{testutil.FixtureUUID}, Parallel: 1}
+	searcher := NewSearcher(config)
+
+	// The rogue transaction is the first one missing from the replica, unless ignored
+	executed, _ := mysql.ParseMysqlGTIDSet(testutil.FixtureUUID + ":1")
+	result, err := searcher.FindFirstMissing(context.Background(), files, &executed, nil)
+	if err != nil {
+		t.Fatalf("FindFirstMissing() error = %v", err)
+	}
+	if result == nil || result.GTID != testutil.FixtureUUID+":2" {
+		t.Errorf("Expected %s:2, got %+v", testutil.FixtureUUID, result)
+	}
+
+	neighbor, err := searcher.FindNeighbor(context.Background(), files, testutil.FixtureUUID+":1", true)
+	if err != nil {
+		t.Fatalf("FindNeighbor() error = %v", err)
+	}
+	if neighbor == nil || neighbor.GTID != testutil.FixtureUUID+":2" {
+		t.Errorf("Expected neighbor %s:2, got %+v", testutil.FixtureUUID, neighbor)
+	}
+
+	rogue, _ := mysql.ParseMysqlGTIDSet(rogueUUID + ":1")
+	if _, err := searcher.SearchParallel(context.Background(), files, &rogue); err == nil {
+		t.Error("Expected SearchParallel() error for a disallowed target UUID")
+	}
+}
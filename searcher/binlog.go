@@ -1,19 +1,27 @@
 package searcher
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/quyetmv/mysql-gtid-position/models"
+	gtidparser "github.com/quyetmv/mysql-gtid-position/parser"
 
 	"github.com/go-mysql-org/go-mysql/mysql"
 	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/google/uuid"
 )
 
 // BinlogParser interface matches replication.BinlogParser.ParseFile
@@ -21,31 +29,254 @@ type BinlogParser interface {
 	ParseFile(name string, offset int64, execution replication.OnEventFunc) error
 }
 
+// contextParser is a BinlogParser that can give up before parsing once ctx is done,
+// e.g. while waiting for a global parallelism slot
+type contextParser interface {
+	ParseFileContext(ctx context.Context, name string, offset int64, execution replication.OnEventFunc) error
+}
+
+// parseFile parses a file with parser, through ParseFileContext when it has one
+func parseFile(ctx context.Context, parser BinlogParser, name string, offset int64, execution replication.OnEventFunc) error {
+	if p, ok := parser.(contextParser); ok {
+		return p.ParseFileContext(ctx, name, offset, execution)
+	}
+	return parser.ParseFile(name, offset, execution)
+}
+
+// Returned from parser callbacks to stop a scan early, they are not failures
+var (
+	errFoundNextGTID      = errors.New("stop: next GTID found")
+	errFoundTarget        = errors.New("stop: target found")
+	errFoundNeighbor      = errors.New("stop: neighbor found")
+	errFoundPreviousGTIDs = errors.New("stop: previous GTIDs found")
+)
+
 // Searcher handles binlog file searching
 type Searcher struct {
-	config        *models.Config
-	verbose       bool
-	parserFactory func() BinlogParser
+	config         *models.Config
+	verbose        bool
+	logger         *slog.Logger   // Verbose progress messages, written to stderr
+	metrics        Metrics        // Optional scan instrumentation, nil for the CLI
+	scannedFiles   atomic.Int64   // Files scanned to the end by this searcher
+	store          ObjectStore    // Remote binlog source, nil for local files
+	resultHook     ResultHook     // Optional enrichment of every match, see SetResultHook
+	commitDetector CommitDetector // Transaction end detection, DefaultCommitDetector if nil
+	parserFactory  func() BinlogParser
+
+	seenMu       sync.Mutex
+	seenUUIDs    map[string]bool     // Server UUIDs in the GTIDs of scanned files, PREVIOUS_GTIDS included
+	seenGTIDs    *mysql.MysqlGTIDSet // GTIDs of scanned files, PREVIOUS_GTIDS included
+	seenVersions map[string]bool     // Server versions of the format descriptions of scanned files
+}
+
+// Metrics receives scan instrumentation from a Searcher.
+// It is only set in server mode; without it the searcher makes no metric calls.
+type Metrics interface {
+	FileScanned() // A binlog file was scanned to the end
+}
+
+// SetMetrics attaches scan instrumentation to the searcher
+func (s *Searcher) SetMetrics(m Metrics) {
+	s.metrics = m
+}
+
+// ResultHook is called with every finalized match before it is collected
+type ResultHook func(position *models.GTIDPosition) error
+
+// SetResultHook attaches a hook that may enrich each match by changing it; an error
+// from the hook aborts the search and is returned. In parallel searches the hook may
+// be called concurrently from several workers.
+func (s *Searcher) SetResultHook(hook ResultHook) {
+	s.resultHook = hook
+}
+
+// CommitDetector reports whether an event of a binary binlog ends the transaction
+// it belongs to
+type CommitDetector func(e *replication.BinlogEvent) bool
+
+// SetCommitDetector replaces DefaultCommitDetector for engines or configurations that
+// end transactions differently. It is called with every event inside a transaction;
+// ROLLBACK queries, and DDL statements logged without BEGIN, keep their own handling.
+// Text dumps are not affected. nil restores the default.
+func (s *Searcher) SetCommitDetector(detect CommitDetector) {
+	s.commitDetector = detect
+}
+
+// DefaultCommitDetector ends a transaction at its XID event (InnoDB) or at a COMMIT
+// query (non-transactional engines), see isCommitQuery
+func DefaultCommitDetector(e *replication.BinlogEvent) bool {
+	switch e.Header.EventType {
+	case replication.XID_EVENT:
+		return true
+	case replication.QUERY_EVENT:
+		return isCommitQuery(string(e.Event.(*replication.QueryEvent).Query))
+	default:
+		return false
+	}
+}
+
+// isCommit reports whether an event ends its transaction, with the commit detector
+func (s *Searcher) isCommit(e *replication.BinlogEvent) bool {
+	if s.commitDetector != nil {
+		return s.commitDetector(e)
+	}
+	return DefaultCommitDetector(e)
+}
+
+// warn logs a warning that matters even without verbose output
+func (s *Searcher) warn(msg string, args ...any) {
+	if s.logger != nil {
+		s.logger.Warn(msg, args...)
+	}
+}
+
+// runResultHook passes a match to the result hook, if any
+func (s *Searcher) runResultHook(position *models.GTIDPosition) error {
+	if s.resultHook == nil {
+		return nil
+	}
+	return s.resultHook(position)
+}
+
+// ScannedFiles returns the number of binlog files this searcher has scanned to the end
+func (s *Searcher) ScannedFiles() int {
+	return int(s.scannedFiles.Load())
+}
+
+// fileScanned records a binlog file scanned to the end
+func (s *Searcher) fileScanned() {
+	s.scannedFiles.Add(1)
+	if s.metrics != nil {
+		s.metrics.FileScanned()
+	}
+}
+
+// observeUUIDs records the GTIDs seen in a file and their server UUIDs
+func (s *Searcher) observeUUIDs(set *mysql.MysqlGTIDSet) {
+	s.seenMu.Lock()
+	defer s.seenMu.Unlock()
+
+	if s.seenUUIDs == nil {
+		s.seenUUIDs = make(map[string]bool)
+		s.seenGTIDs = &mysql.MysqlGTIDSet{Sets: make(map[string]*mysql.UUIDSet)}
+	}
+	for uuidStr := range set.Sets {
+		s.seenUUIDs[uuidStr] = true
+	}
+	s.seenGTIDs.Add(*set.Clone().(*mysql.MysqlGTIDSet)) // Add shares the UUID sets of its argument
+}
+
+// observeServerVersion records the server version that wrote a file
+func (s *Searcher) observeServerVersion(filepath, version string) {
+	if s.verbose {
+		s.logger.Info("binlog format description", "file", filepath, "server_version", version)
+	}
+
+	s.seenMu.Lock()
+	defer s.seenMu.Unlock()
+
+	if s.seenVersions == nil {
+		s.seenVersions = make(map[string]bool)
+	}
+	s.seenVersions[version] = true
+}
+
+// ServerVersion returns the MySQL server version that wrote the files scanned so far,
+// from their format descriptions. Versions of an archive spanning an upgrade are
+// listed sorted and comma-separated; it is empty before any file was scanned.
+func (s *Searcher) ServerVersion() string {
+	s.seenMu.Lock()
+	defer s.seenMu.Unlock()
+
+	versions := make([]string, 0, len(s.seenVersions))
+	for version := range s.seenVersions {
+		versions = append(versions, version)
+	}
+	sort.Strings(versions)
+	return strings.Join(versions, ", ")
+}
+
+// UnseenUUIDs returns the server UUIDs of the target set, sorted, that appear in no
+// GTID of the files scanned so far, headers included. Such a UUID cannot produce a
+// match: usually a typo or binlogs of another cluster. Files skipped once the search
+// stopped early are not looked at.
+func (s *Searcher) UnseenUUIDs(targetGTID *mysql.GTIDSet) []string {
+	target, ok := (*targetGTID).(*mysql.MysqlGTIDSet)
+	if !ok {
+		return nil
+	}
+
+	s.seenMu.Lock()
+	defer s.seenMu.Unlock()
+
+	var unseen []string
+	for uuidStr := range target.Sets {
+		if !s.seenUUIDs[uuidStr] {
+			unseen = append(unseen, uuidStr)
+		}
+	}
+	sort.Strings(unseen)
+	return unseen
 }
 
 // NewSearcher creates a new Searcher instance
 func NewSearcher(config *models.Config) *Searcher {
-	return &Searcher{
-		config:  config,
-		verbose: config.Verbose,
-		parserFactory: func() BinlogParser {
-			p := replication.NewBinlogParser()
-			p.SetVerifyChecksum(true)
-			return p
-		},
+	return NewSearcherWithParser(config, newLocalParser)
+}
+
+// NewSearcherWithParser creates a Searcher that reads binlogs with parsers from factory,
+// e.g. a caching parser or one streaming from object storage. The factory is called
+// once per file scan, so parsers need not be safe for concurrent use.
+// File paths are passed to the parser as-is; text dump detection and truncated tail
+// checks only apply to paths on the local filesystem. With Config.MaxGlobalParallel
+// each parse holds a slot of the lock file of BinlogDir, see OpenGlobalLock.
+func NewSearcherWithParser(config *models.Config, factory func() BinlogParser) *Searcher {
+	s := &Searcher{
+		config:        config,
+		verbose:       config.Verbose,
+		logger:        NewLogger(os.Stderr, config.LogFormat),
+		parserFactory: factory,
 	}
+
+	// Share the cap on files scanned at once with the other instances on BinlogDir
+	if config.MaxGlobalParallel > 0 && config.BinlogDir != "" {
+		slots, err := openGlobalSlots(config.BinlogDir, config.MaxGlobalParallel)
+		if err != nil {
+			s.warn("global parallelism cap disabled", "error", err)
+		} else {
+			s.parserFactory = func() BinlogParser {
+				return slotParser{BinlogParser: factory(), slots: slots}
+			}
+		}
+	}
+	return s
+}
+
+// NewLogger creates a structured logger for the given format (text or json)
+func NewLogger(w io.Writer, format string) *slog.Logger {
+	if format == models.LogFormatJSON {
+		return slog.New(slog.NewJSONHandler(w, nil))
+	}
+	return slog.New(slog.NewTextHandler(w, nil))
 }
 
-// GetBinlogFiles discovers binlog files in directory
+// GetBinlogFiles discovers binlog files in directory.
+// Besides filepath.Glob syntax the pattern may hold brace alternatives,
+// e.g. mysql-bin.{000100,000101}.
 func (s *Searcher) GetBinlogFiles(dir, pattern string) ([]string, error) {
-	files, err := filepath.Glob(filepath.Join(dir, pattern))
-	if err != nil {
-		return nil, fmt.Errorf("failed to glob files: %w", err)
+	seen := make(map[string]bool)
+	var files []string
+	for _, expanded := range expandBraces(pattern) {
+		matches, err := filepath.Glob(filepath.Join(dir, expanded))
+		if err != nil {
+			return nil, fmt.Errorf("failed to glob files: %w", err)
+		}
+		for _, f := range matches {
+			if !seen[f] {
+				seen[f] = true
+				files = append(files, f)
+			}
+		}
 	}
 
 	// Filter out index files and sort
@@ -60,82 +291,431 @@ func (s *Searcher) GetBinlogFiles(dir, pattern string) ([]string, error) {
 	return binlogs, nil
 }
 
-// SearchParallel searches for GTID in binlog files using parallel workers
-func (s *Searcher) SearchParallel(files []string, targetGTID *mysql.GTIDSet) (*models.GTIDPosition, error) {
-	ctx, cancel := context.WithCancel(context.Background())
+// GetBinlogFilesByRegex discovers the files in directory whose name fully matches
+// the regular expression pattern, e.g. mysql-bin\.0001[0-4][0-9]
+func (s *Searcher) GetBinlogFilesByRegex(dir, pattern string) ([]string, error) {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern regex: %w", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read binlog directory: %w", err)
+	}
+
+	// Filter out directories and index files, entries are sorted by name
+	var binlogs []string
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasSuffix(entry.Name(), ".index") {
+			continue
+		}
+		if re.MatchString(entry.Name()) {
+			binlogs = append(binlogs, filepath.Join(dir, entry.Name()))
+		}
+	}
+
+	return binlogs, nil
+}
+
+// expandBraces expands the {a,b} alternatives of a glob pattern, nested ones
+// included. A pattern without braces, or with unbalanced ones, is returned as is.
+func expandBraces(pattern string) []string {
+	open := strings.IndexByte(pattern, '{')
+	if open < 0 {
+		return []string{pattern}
+	}
+
+	// Find the matching close brace and the top-level commas between them
+	depth := 0
+	commas := []int{}
+	for i := open; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			depth++
+		case ',':
+			if depth == 1 {
+				commas = append(commas, i)
+			}
+		case '}':
+			depth--
+			if depth == 0 {
+				prefix, suffix := pattern[:open], pattern[i+1:]
+				bounds := append(append([]int{open}, commas...), i)
+
+				var expanded []string
+				for j := 0; j < len(bounds)-1; j++ {
+					alternative := pattern[bounds[j]+1 : bounds[j+1]]
+					expanded = append(expanded, expandBraces(prefix+alternative+suffix)...)
+				}
+				return expanded
+			}
+		}
+	}
+
+	return []string{pattern}
+}
+
+// GetBinlogFilesFromIndex reads binlog files in the order listed by a MySQL index file
+// (e.g. mysql-bin.index). Relative paths, including the index path itself, are resolved against dir.
+func (s *Searcher) GetBinlogFilesFromIndex(dir, indexFile string) ([]string, error) {
+	if !filepath.IsAbs(indexFile) {
+		indexFile = filepath.Join(dir, indexFile)
+	}
+
+	file, err := os.Open(indexFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index file: %w", err)
+	}
+	defer file.Close()
+
+	var binlogs []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(dir, line)
+		}
+		binlogs = append(binlogs, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read index file: %w", err)
+	}
+
+	return binlogs, nil
+}
+
+// GetBinlogFilesFromList reads binlog paths, one per line, from listFile or stdin for "-".
+// The list is used as given, in order, without globbing or sorting; every path must exist.
+func (s *Searcher) GetBinlogFilesFromList(listFile string) ([]string, error) {
+	input := os.Stdin
+	if listFile != "-" {
+		file, err := os.Open(listFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file list: %w", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	var binlogs []string
+	lineNum := 0
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if _, err := os.Stat(line); err != nil {
+			return nil, fmt.Errorf("file list line %d: %w", lineNum, err)
+		}
+		binlogs = append(binlogs, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read file list: %w", err)
+	}
+
+	return binlogs, nil
+}
+
+// fileResult is a per-file match tagged with the file's position in the scan order
+type fileResult struct {
+	index    int
+	position *models.GTIDPosition
+}
+
+// TimeoutError is returned with the best partial result when the search hits Config.Timeout
+type TimeoutError struct {
+	Timeout time.Duration
+	Scanned int // Files scanned to the end before the deadline
+	Total   int
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("timed out after %s, scanned %d/%d files", e.Timeout, e.Scanned, e.Total)
+}
+
+// timeoutError reports a failed scan, as a *TimeoutError when ctx hit its deadline
+func (s *Searcher) timeoutError(ctx context.Context, err error, scanned, total int) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &TimeoutError{Timeout: s.config.Timeout, Scanned: scanned, Total: total}
+	}
+	return err
+}
+
+// SearchParallel searches for GTID in binlog files using parallel workers.
+// When Config.Timeout is set, the search stops at the deadline and returns the best
+// result found so far together with a *TimeoutError.
+func (s *Searcher) SearchParallel(ctx context.Context, files []string, targetGTID *mysql.GTIDSet) (*models.GTIDPosition, error) {
+	if err := s.CheckAllowedUUIDs(targetGTID); err != nil {
+		return nil, err
+	}
+
+	scanCtx := ctx // Stops files mid-scan, only on deadline or caller cancellation
+	if s.config.Timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		scanCtx, cancelTimeout = context.WithTimeout(ctx, s.config.Timeout)
+		defer cancelTimeout()
+	}
+
+	ctx, cancel := context.WithCancel(scanCtx)
 	defer cancel()
 
-	resultChan := make(chan *models.GTIDPosition, len(files))
-	errorChan := make(chan error, len(files))
+	// A fixed pool of workers and channels bounded by it keep memory flat however
+	// many files are searched
+	workers := max(min(s.config.Parallel, len(files)), 1)
+	resultChan := make(chan fileResult, workers)
+	errorChan := make(chan error, workers)
+	jobs := make(chan int)
 
 	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, s.config.Parallel)
 
-	for i, file := range files {
-		wg.Add(1)
-		go func(idx int, filepath string) {
-			defer wg.Done()
+	// First/last selection only stops files that can no longer win, by file order
+	var matchMu sync.Mutex
+	firstMatch, lastMatch := len(files), -1
 
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
+	var scanned atomic.Int64
 
-			select {
-			case <-ctx.Done():
+	// collect hands a match of file idx to the collector, unless the result hook fails
+	var hookErr error
+	var hookOnce sync.Once
+	collect := func(idx int, position *models.GTIDPosition) bool {
+		if err := s.runResultHook(position); err != nil {
+			hookOnce.Do(func() { hookErr = fmt.Errorf("result hook: %w", err) })
+			cancel() // Abort the search
+			return false
+		}
+		resultChan <- fileResult{index: idx, position: position}
+		return true
+	}
+
+	scanFile := func(idx int) {
+		filepath := files[idx]
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		matchMu.Lock()
+		skip := (s.config.Selection == models.SelectionFirst && idx > firstMatch) ||
+			(s.config.Selection == models.SelectionLast && idx < lastMatch)
+		matchMu.Unlock()
+		if skip {
+			return
+		}
+
+		if s.verbose {
+			s.logger.Info("scanning binlog file", "index", idx+1, "total", len(files), "file", filepath)
+		}
+
+		result, err := s.searchBinlogFile(scanCtx, filepath, targetGTID)
+		if err != nil {
+			if scanCtx.Err() != nil {
+				// Stopped mid-file, keep what was found so far
+				if result != nil {
+					collect(idx, result)
+				}
 				return
-			default:
 			}
+			errorChan <- fmt.Errorf("error scanning %s: %w", filepath, err)
+			return
+		}
+		scanned.Add(1)
+		s.fileScanned()
 
-			if s.verbose {
-				fmt.Printf("🔎 Scanning [%d/%d]: %s\n", idx+1, len(files), filepath)
+		if result != nil {
+			if !collect(idx, result) {
+				return
 			}
 
-			result, err := s.searchBinlogFile(filepath, targetGTID)
-			if err != nil {
-				errorChan <- fmt.Errorf("error scanning %s: %w", filepath, err)
-				return
+			switch s.config.Selection {
+			case models.SelectionFirst, models.SelectionLast:
+				matchMu.Lock()
+				firstMatch = min(firstMatch, idx)
+				lastMatch = max(lastMatch, idx)
+				matchMu.Unlock()
+			default:
+				cancel() // Stop other workers
 			}
+		}
+	}
 
-			if result != nil {
-				resultChan <- result
-				cancel() // Stop other goroutines
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				scanFile(idx)
 			}
-		}(i, file)
+		}()
 	}
 
-	// Wait for all goroutines to complete
+	// Feed files to the workers, stopping once the search is cancelled
+	order := s.scanOrder(files, workers)
+	go func() {
+		defer close(jobs)
+		for _, i := range order {
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Wait for all workers to complete
 	go func() {
 		wg.Wait()
 		close(resultChan)
 		close(errorChan)
 	}()
 
-	// Collect best result (highest GNO)
+	// Collect best result according to the selection mode. Both channels are read
+	// together, a worker blocked on a full error channel would stall the results.
+	// Errors are logged in verbose mode. An encrypted binlog is reported even without
+	// it: its transactions cannot be searched, so "not found" would be misleading.
 	var bestResult *models.GTIDPosition
-	for result := range resultChan {
-		if result != nil {
-			if bestResult == nil || result.GNO > bestResult.GNO {
-				bestResult = result
+	bestIndex := -1
+	var encrypted *EncryptedBinlogError
+	for resultChan != nil || errorChan != nil {
+		select {
+		case result, ok := <-resultChan:
+			if !ok {
+				resultChan = nil
+				continue
+			}
+			if result.position == nil {
+				continue
+			}
+			if bestResult == nil || s.preferFileResult(result.index, bestIndex, result.position, bestResult) {
+				bestResult = result.position
+				bestIndex = result.index
+			}
+		case err, ok := <-errorChan:
+			if !ok {
+				errorChan = nil
+				continue
+			}
+			if s.verbose {
+				s.logger.Warn("scan failed", "error", err)
+			}
+			var encErr *EncryptedBinlogError
+			if encrypted == nil && errors.As(err, &encErr) {
+				encrypted = encErr
 			}
 		}
 	}
+	if hookErr != nil {
+		return nil, hookErr
+	}
+	if bestResult != nil && bestResult.NextGTID == "" && bestIndex+1 < len(files) && scanCtx.Err() == nil {
+		// The match is the last transaction of its file, its next GTID is in the next one
+		s.resumeInNextFile(bestResult, files[bestIndex+1])
+	}
+	if bestResult != nil {
+		bestResult.SetAge(time.Now())
+	}
 
-	// Log any errors in verbose mode
-	if s.verbose {
-		for err := range errorChan {
-			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
-		}
+	if encrypted != nil && bestResult == nil {
+		return nil, encrypted
+	}
+	if encrypted != nil {
+		s.logger.Warn("encrypted binlog skipped, a better match may be in it", "file", encrypted.File)
+	}
+
+	if errors.Is(scanCtx.Err(), context.DeadlineExceeded) {
+		return bestResult, &TimeoutError{Timeout: s.config.Timeout, Scanned: int(scanned.Load()), Total: len(files)}
 	}
 
 	return bestResult, nil
 }
 
+// preferMatch reports whether a finalized match should replace the current best
+// match of the same file, which were seen in file order
+func (s *Searcher) preferMatch(candidate, current *models.GTIDPosition) bool {
+	if current == nil {
+		return true
+	}
+
+	switch s.config.Selection {
+	case models.SelectionFirst:
+		return false // Earliest match was seen first
+	case models.SelectionLast:
+		return true // Latest match is seen last
+	default:
+		return candidate.GNO > current.GNO
+	}
+}
+
+// preferFileResult reports whether a match from file candidateIdx should replace
+// the current best match from file currentIdx
+func (s *Searcher) preferFileResult(candidateIdx, currentIdx int, candidate, current *models.GTIDPosition) bool {
+	switch s.config.Selection {
+	case models.SelectionFirst:
+		return candidateIdx < currentIdx
+	case models.SelectionLast:
+		return candidateIdx > currentIdx
+	default:
+		// Equal GNOs of different servers go to the earlier file, whatever finished first
+		return candidate.GNO > current.GNO || (candidate.GNO == current.GNO && candidateIdx < currentIdx)
+	}
+}
 
 // searchBinlogFile searches for GTID in a single binlog file
-func (s *Searcher) searchBinlogFile(filepath string, targetGTID *mysql.GTIDSet) (*models.GTIDPosition, error) {
+func (s *Searcher) searchBinlogFile(ctx context.Context, filepath string, targetGTID *mysql.GTIDSet) (*models.GTIDPosition, error) {
+	// A bisected window without a match says nothing about the rest of the file
+	if from, ok := s.bisectStart(ctx, filepath, targetGTID); ok {
+		result, err := s.scanBinlogFile(ctx, filepath, targetGTID, nil, from)
+		if result != nil || err != nil {
+			s.validateMatch(result)
+			return result, err
+		}
+		if s.verbose {
+			s.logger.Info("no match in the bisected window, scanning the whole file", "file", filepath)
+		}
+	}
+
+	result, err := s.scanBinlogFile(ctx, filepath, targetGTID, nil, 0)
+	s.validateMatch(result)
+	return result, err
+}
+
+// validateMatch warns about a finalized match breaking the invariants of a position
+// (see models.GTIDPosition.Validate), a parsing bug rather than a property of the binlog
+func (s *Searcher) validateMatch(position *models.GTIDPosition) {
+	if position == nil {
+		return
+	}
+	if err := position.Validate(); err != nil {
+		s.warn("inconsistent match", "file", position.BinlogFile, "gtid", position.GTID, "error", err)
+	}
+}
+
+// scanBinlogFile matches the transactions of a binlog file against the target set.
+// With counts nil it returns the best match; otherwise it scans the whole file and
+// tallies every committed match per server UUID into counts, returning no position.
+// A non-zero from starts the scan at that event boundary: the PREVIOUS_GTIDS header
+// and the transactions before it are not read, so matches carry no ExecutedSet.
+func (s *Searcher) scanBinlogFile(ctx context.Context, filepath string, targetGTID *mysql.GTIDSet, counts map[string]uint64, from uint32) (*models.GTIDPosition, error) {
+	// mysqlbinlog text dumps are parsed from their comments instead of binary events
+	if isTextDump(filepath) {
+		return s.searchTextDumpFile(ctx, filepath, targetGTID, counts)
+	}
+
 	parser := s.parserFactory()
 
 	var result *models.GTIDPosition
-	var currentDatabase string // Track current database context
+	var currentDatabase string                  // Track current database context
 	var currentTransaction *models.GTIDPosition // Track current transaction being processed
 
 	// Convert time filters to Unix timestamps for comparison
@@ -147,12 +727,85 @@ func (s *Searcher) searchBinlogFile(filepath string, targetGTID *mysql.GTIDSet)
 		endTimestamp = uint32(s.config.EndTime.Unix())
 	}
 
-	err := parser.ParseFile(filepath, 0, func(e *replication.BinlogEvent) error {
+	// GTIDs executed up to the current event, seeded from the PREVIOUS_GTIDS header
+	executedSet := &mysql.MysqlGTIDSet{Sets: make(map[string]*mysql.UUIDSet)}
+	defer s.observeUUIDs(executedSet)
+
+	var lastGoodPos uint32        // END_LOG_POS of the last fully parsed event
+	var prevGTID, lastGTID string // GTID before the current one and the current one, in file order
+	var txnTimestamp uint32       // Commit time of the current transaction from its GTID event, 0 before MySQL 8.0
+	var tableMatched bool         // Whether the current transaction wrote a FilterTable table
+	var begun bool                // Whether the current transaction opened with a BEGIN query
+	endUUID, endGNO, hasEnd := s.endGTID()
+
+	// finishTransaction records the current transaction as a match committed at logPos
+	finishTransaction := func(logPos, eventTime uint32) {
+		defer func() { currentTransaction = nil }()
+		if s.config.FilterTable != "" && !tableMatched {
+			return // Did not write the filtered table
+		}
+
+		currentTransaction.CommitPosition = logPos
+		currentTransaction.ResumePosition = logPos // Default resume = commit
+		currentTransaction.Timestamp = eventTime
+		if from == 0 {
+			currentTransaction.ExecutedSet = executedSet.String()
+		}
+
+		// Keep the best match for the selection mode (highest GNO by default),
+		// or only count it. Without a result no early return to the next GTID happens.
+		if counts != nil {
+			counts[currentTransaction.ServerUUID]++
+		} else if s.preferMatch(currentTransaction, result) {
+			result = currentTransaction
+		}
+	}
+
+	err := parseFile(ctx, parser, filepath, int64(from), func(e *replication.BinlogEvent) error {
+		if err := ctx.Err(); err != nil {
+			return err // Search deadline reached or cancelled
+		}
+		prevPos := lastGoodPos
+		lastGoodPos = e.Header.LogPos
+
+		// Accumulate executed GTIDs before any filtering, every transaction counts
+		switch e.Header.EventType {
+		case replication.FORMAT_DESCRIPTION_EVENT:
+			s.observeServerVersion(filepath, e.Event.(*replication.FormatDescriptionEvent).ServerVersion)
+		case replication.PREVIOUS_GTIDS_EVENT:
+			previousEvent := e.Event.(*replication.PreviousGTIDsEvent)
+			if previousSet, err := mysql.ParseMysqlGTIDSet(previousEvent.GTIDSets); err == nil {
+				executedSet.Add(*previousSet.(*mysql.MysqlGTIDSet))
+			}
+		case replication.GTID_EVENT:
+			gtidEvent := e.Event.(*replication.GTIDEvent)
+			if sid, err := uuid.FromBytes(gtidEvent.SID); err == nil {
+				executedSet.AddGTID(sid, gtidEvent.GNO)
+				prevGTID, lastGTID = lastGTID, fmt.Sprintf("%s:%d", sid, gtidEvent.GNO)
+
+				// Past the end GTID nothing more can match, the scan stops here
+				if hasEnd && sid.String() == endUUID && uint64(gtidEvent.GNO) > endGNO {
+					if result != nil && result.NextGTID == "" {
+						result.NextGTID = lastGTID
+						result.ResumePosition = e.Header.LogPos
+					}
+					return errFoundNextGTID
+				}
+			}
+			txnTimestamp = commitTimestamp(gtidEvent)
+		}
+
+		// Events of a transaction share its commit time when the server provides it
+		eventTime := e.Header.Timestamp
+		if txnTimestamp > 0 {
+			eventTime = txnTimestamp
+		}
+
 		// Filter by time range if specified
-		if startTimestamp > 0 && e.Header.Timestamp < startTimestamp {
+		if startTimestamp > 0 && eventTime < startTimestamp {
 			return nil // Skip events before start time
 		}
-		if endTimestamp > 0 && e.Header.Timestamp > endTimestamp {
+		if endTimestamp > 0 && eventTime > endTimestamp {
 			return nil // Skip events after end time
 		}
 
@@ -168,13 +821,7 @@ func (s *Searcher) searchBinlogFile(filepath string, targetGTID *mysql.GTIDSet)
 		if e.Header.EventType == replication.GTID_EVENT {
 			gtidEvent := e.Event.(*replication.GTIDEvent)
 
-			// Convert SID to UUID string
-			uuidStr := fmt.Sprintf("%x-%x-%x-%x-%x",
-				gtidEvent.SID[0:4], gtidEvent.SID[4:6], gtidEvent.SID[6:8],
-				gtidEvent.SID[8:10], gtidEvent.SID[10:16])
-
-			// Format GTID string
-			gtidStr := fmt.Sprintf("%s:%d", uuidStr, gtidEvent.GNO)
+			uuidStr, gtidStr := gtidparser.FormatGTIDFromEvent(gtidEvent)
 
 			// Parse current GTID to check if it's in the target set
 			currentGTID, err := mysql.ParseMysqlGTIDSet(gtidStr)
@@ -182,8 +829,18 @@ func (s *Searcher) searchBinlogFile(filepath string, targetGTID *mysql.GTIDSet)
 				return nil // Skip invalid GTIDs
 			}
 
-			// Check if current GTID is contained in target GTID set
-			if (*targetGTID).Contain(currentGTID) {
+			// For first/last selection, and with filters for display only, the resume position
+			// is the very next transaction, whether or not it is in the target set or filtered out
+			if result != nil && result.NextGTID == "" && (s.config.Selection.IsOrdered() || s.config.FilterForDisplayOnly) {
+				result.NextGTID = gtidStr
+				result.ResumePosition = e.Header.LogPos
+				if s.config.Selection == models.SelectionFirst {
+					return errFoundNextGTID
+				}
+			}
+
+			// Check if current GTID is contained in target GTID set, from an allowed server
+			if s.gtidMatches(*targetGTID, currentGTID, uuidStr, uint64(gtidEvent.GNO)) {
 				// Filter by database if specified
 				if s.config.FilterDatabase != "" && currentDatabase != s.config.FilterDatabase {
 					currentTransaction = nil
@@ -191,61 +848,68 @@ func (s *Searcher) searchBinlogFile(filepath string, targetGTID *mysql.GTIDSet)
 				}
 
 				// Start tracking this transaction
+				tableMatched, begun = false, false
 				currentTransaction = &models.GTIDPosition{
 					BinlogFile:     filepath,
-					Position:       e.Header.LogPos - e.Header.EventSize, // Start position (GTID event)
-					CommitPosition: e.Header.LogPos,                      // Will be updated at transaction end
-					ResumePosition: e.Header.LogPos,                      // Will be updated when next GTID found
-					Timestamp:      e.Header.Timestamp,
+					Position:       eventStartPosition(e.Header, prevPos), // Start position (GTID event)
+					CommitPosition: e.Header.LogPos,                       // Will be updated at transaction end
+					ResumePosition: e.Header.LogPos,                       // Will be updated when next GTID found
+					Timestamp:      eventTime,
 					GTID:           gtidStr,
 					ServerUUID:     uuidStr,
 					GNO:            uint64(gtidEvent.GNO),
 					Database:       currentDatabase,
+					PrevGTID:       prevGTID,
+					LastCommitted:  gtidEvent.LastCommitted,
+					SequenceNumber: gtidEvent.SequenceNumber,
 					CreatedAt:      time.Now(),
 				}
+				currentTransaction.MatchInterval, _ = gtidparser.MatchInterval(*targetGTID, uuidStr, uint64(gtidEvent.GNO))
 			} else {
 				// GTID outside target range
 				// If we have completed result, this is the next GTID
 				if result != nil && result.NextGTID == "" {
 					result.NextGTID = gtidStr
 					result.ResumePosition = e.Header.LogPos // END_LOG_POS of next GTID (same as Kafka Connect)
-					return fmt.Errorf("found_next_gtid")
+					return errFoundNextGTID
+				}
+				// Resuming at the next transaction, filtered out or not, nothing after it can match
+				if result != nil && s.config.FilterForDisplayOnly && !s.config.Selection.IsOrdered() {
+					return errFoundNextGTID
 				}
 				currentTransaction = nil
 			}
 		}
 
-		// Track transaction end (XID_EVENT or COMMIT)
+		// Track transaction end (XID_EVENT or COMMIT by default, see SetCommitDetector)
 		if currentTransaction != nil {
-			// XID_EVENT marks end of InnoDB transaction
-			if e.Header.EventType == replication.XID_EVENT {
-				// Update commit position (Xid END_LOG_POS) and timestamp
-				currentTransaction.CommitPosition = e.Header.LogPos
-				currentTransaction.ResumePosition = e.Header.LogPos // Default resume = commit
-				currentTransaction.Timestamp = e.Header.Timestamp
-
-				// Keep the match with highest GNO
-				if result == nil || currentTransaction.GNO > result.GNO {
-					result = currentTransaction
+			// Tables written by the transaction, for the table filter
+			switch e.Header.EventType {
+			case replication.TABLE_MAP_EVENT:
+				tableMap := e.Event.(*replication.TableMapEvent)
+				if s.matchesTable(string(tableMap.Schema), string(tableMap.Table)) {
+					tableMatched = true
+				}
+			case replication.QUERY_EVENT:
+				// Statement-based binlogs: the table written is only in the SQL text
+				queryEvent := e.Event.(*replication.QueryEvent)
+				if s.config.FilterTable != "" && s.statementMatchesTable(string(queryEvent.Schema), string(queryEvent.Query)) {
+					tableMatched = true
 				}
-				currentTransaction = nil
 			}
 
-			// QUERY_EVENT with COMMIT also marks transaction end
-			if e.Header.EventType == replication.QUERY_EVENT {
-				queryEvent := e.Event.(*replication.QueryEvent)
-				query := string(queryEvent.Query)
-				if query == "COMMIT" || query == "commit" {
-					// Update commit position and timestamp
-					currentTransaction.CommitPosition = e.Header.LogPos
-					currentTransaction.ResumePosition = e.Header.LogPos // Default resume = commit
-					currentTransaction.Timestamp = e.Header.Timestamp
-
-					// Keep the match with highest GNO
-					if result == nil || currentTransaction.GNO > result.GNO {
-						result = currentTransaction
-					}
+			if s.isCommit(e) {
+				finishTransaction(e.Header.LogPos, eventTime) // Commit position is the END_LOG_POS of the commit event
+			} else if e.Header.EventType == replication.QUERY_EVENT {
+				query := string(e.Event.(*replication.QueryEvent).Query)
+				if isRollbackQuery(query) {
+					// The GTID is consumed but nothing was committed: not a match
 					currentTransaction = nil
+				} else if isStatement(query, "BEGIN") {
+					begun = true
+				} else if !begun && isDDLStatement(query) {
+					// A DDL commits on its own, its query ends the transaction
+					finishTransaction(e.Header.LogPos, eventTime)
 				}
 			}
 		}
@@ -254,9 +918,240 @@ func (s *Searcher) searchBinlogFile(filepath string, targetGTID *mysql.GTIDSet)
 	})
 
 	// Return the result (highest GNO found)
-	if err != nil && err.Error() != "found_next_gtid" {
+	if ctx.Err() != nil {
+		return result, ctx.Err() // Partial result of an interrupted scan
+	}
+	if err != nil && !errors.Is(err, errFoundNextGTID) {
+		// Active binlog still being written: keep whatever was found before the partial event
+		if isTruncatedTail(filepath, lastGoodPos, err) {
+			if s.verbose {
+				s.logger.Warn("binlog ends with a partial event, stopping scan",
+					"file", filepath, "last_good_position", lastGoodPos, "error", err)
+			}
+			return result, nil
+		}
+		return nil, err
+	}
+
+	// A matched transaction still open at the end of the file has its commit in the
+	// next one (large transactions in relay logs); it cannot be finalized from here
+	if err == nil && currentTransaction != nil {
+		s.warn("binlog ends inside a matched transaction whose commit is in the next file, it is not reported",
+			"file", filepath, "gtid", currentTransaction.GTID, "start_position", currentTransaction.Position)
+	}
+
+	return result, nil
+}
+
+// FindFirstMissing scans binlog files in order and returns the earliest transaction
+// that is not contained in the executed GTID set (e.g. a replica's gtid_executed).
+// If missing is not nil, only transactions contained in it are considered.
+// On Config.Timeout a *TimeoutError is returned.
+func (s *Searcher) FindFirstMissing(ctx context.Context, files []string, executed, missing *mysql.GTIDSet) (*models.GTIDPosition, error) {
+	if missing != nil {
+		if err := s.CheckAllowedUUIDs(missing); err != nil {
+			return nil, err
+		}
+	}
+	if s.config.Timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, s.config.Timeout)
+		defer cancelTimeout()
+	}
+
+	for idx, file := range files {
+		if s.verbose {
+			s.logger.Info("scanning binlog file", "index", idx+1, "total", len(files), "file", file)
+		}
+
+		result, err := s.findFirstMissingInFile(ctx, file, executed, missing)
+		if err != nil {
+			return nil, s.timeoutError(ctx, fmt.Errorf("error scanning %s: %w", file, err), idx, len(files))
+		}
+		s.fileScanned()
+
+		// Files are scanned in order, so the first hit is the earliest
+		if result != nil {
+			s.validateMatch(result)
+			if err := s.runResultHook(result); err != nil {
+				return nil, fmt.Errorf("result hook: %w", err)
+			}
+			result.SetAge(time.Now())
+			return result, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// findFirstMissingInFile returns the first transaction in a binlog file
+// that is not contained in the executed GTID set
+func (s *Searcher) findFirstMissingInFile(ctx context.Context, filepath string, executed, missing *mysql.GTIDSet) (*models.GTIDPosition, error) {
+	parser := s.parserFactory()
+
+	var result *models.GTIDPosition
+	var committed bool     // Whether the missing transaction has reached its commit event
+	var lastGoodPos uint32 // END_LOG_POS of the last fully parsed event
+
+	err := parseFile(ctx, parser, filepath, 0, func(e *replication.BinlogEvent) error {
+		if err := ctx.Err(); err != nil {
+			return err // Deadline reached or cancelled
+		}
+		prevPos := lastGoodPos
+		lastGoodPos = e.Header.LogPos
+
+		if e.Header.EventType == replication.GTID_EVENT {
+			gtidEvent := e.Event.(*replication.GTIDEvent)
+
+			uuidStr, gtidStr := gtidparser.FormatGTIDFromEvent(gtidEvent)
+
+			// Transaction after the missing one gives the resume position
+			if result != nil {
+				result.NextGTID = gtidStr
+				result.ResumePosition = e.Header.LogPos
+				return errFoundNextGTID
+			}
+
+			currentGTID, err := mysql.ParseMysqlGTIDSet(gtidStr)
+			if err != nil {
+				return nil // Skip invalid GTIDs
+			}
+
+			if !s.uuidAllowed(uuidStr) {
+				return nil // Not from an allowed server
+			}
+			if (*executed).Contain(currentGTID) {
+				return nil // Already executed on the replica
+			}
+			if missing != nil && !(*missing).Contain(currentGTID) {
+				return nil // Not part of the missing set
+			}
+
+			result = &models.GTIDPosition{
+				BinlogFile:     filepath,
+				Position:       eventStartPosition(e.Header, prevPos), // Start position (GTID event)
+				CommitPosition: e.Header.LogPos,                       // Will be updated at transaction end
+				ResumePosition: e.Header.LogPos,                       // Will be updated when next GTID found
+				Timestamp:      e.Header.Timestamp,
+				GTID:           gtidStr,
+				ServerUUID:     uuidStr,
+				GNO:            uint64(gtidEvent.GNO),
+				LastCommitted:  gtidEvent.LastCommitted,
+				SequenceNumber: gtidEvent.SequenceNumber,
+				CreatedAt:      time.Now(),
+			}
+			if ts := commitTimestamp(gtidEvent); ts > 0 {
+				result.Timestamp = ts
+			}
+			return nil
+		}
+
+		if result == nil || committed {
+			return nil
+		}
+
+		// Track database context and transaction end of the missing transaction
+		if e.Header.EventType == replication.QUERY_EVENT {
+			queryEvent := e.Event.(*replication.QueryEvent)
+			if len(queryEvent.Schema) > 0 {
+				result.Database = string(queryEvent.Schema)
+			}
+		}
+		if s.isCommit(e) {
+			result.CommitPosition = e.Header.LogPos
+			result.ResumePosition = e.Header.LogPos
+			committed = true
+		}
+
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, errFoundNextGTID) && !isTruncatedTail(filepath, lastGoodPos, err) {
 		return nil, err
 	}
 
 	return result, nil
 }
+
+// commitTimestamp returns the commit time (Unix seconds) carried by a GTID event:
+// the immediate commit timestamp of MySQL 8.0+, else the original one, else 0 for
+// older servers, where callers fall back to the event header timestamp
+func commitTimestamp(gtidEvent *replication.GTIDEvent) uint32 {
+	switch {
+	case gtidEvent.ImmediateCommitTimestamp > 0:
+		return uint32(gtidEvent.ImmediateCommitTime().Unix())
+	case gtidEvent.OriginalCommitTimestamp > 0:
+		return uint32(gtidEvent.OriginalCommitTime().Unix())
+	default:
+		return 0
+	}
+}
+
+// matchesTable reports whether a table written by a transaction (from its TABLE_MAP
+// event, or its SQL in statement-based binlogs) is the FilterTable table: "db.table",
+// or "table" in FilterDatabase if set
+func (s *Searcher) matchesTable(schema, table string) bool {
+	filterSchema, filterTable, qualified := strings.Cut(s.config.FilterTable, ".")
+	if !qualified {
+		filterSchema, filterTable = s.config.FilterDatabase, s.config.FilterTable
+	}
+	return table == filterTable && (filterSchema == "" || schema == filterSchema)
+}
+
+// isCommitQuery reports whether a QUERY_EVENT ends a transaction (non-transactional
+// engines log COMMIT as a query rather than an XID event). Trailing whitespace, ";"
+// and comments such as "COMMIT /* xid=12 */" are accepted, "COMMITTED" is not.
+func isCommitQuery(query string) bool {
+	return isStatement(query, "COMMIT")
+}
+
+// isRollbackQuery reports whether a QUERY_EVENT rolls the transaction back,
+// with the same tolerance as isCommitQuery
+func isRollbackQuery(query string) bool {
+	return isStatement(query, "ROLLBACK")
+}
+
+// isStatement reports whether query is the statement keyword, case-insensitively,
+// optionally followed by whitespace, ";", a comment or further clauses
+func isStatement(query, keyword string) bool {
+	query = strings.ToUpper(strings.TrimSpace(query))
+	rest, ok := strings.CutPrefix(query, keyword)
+	if !ok {
+		return false
+	}
+	return rest == "" || strings.HasPrefix(rest, "/*") || strings.ContainsRune(" \t\r\n;", rune(rest[0]))
+}
+
+// eventStartPosition returns the offset an event starts at, LogPos - EventSize. When the
+// event is bigger than its END_LOG_POS (the first event after the magic header written
+// with LogPos 0, or relay log events carrying the source's positions) the subtraction
+// would wrap around, so the end of the previous event is used, or the magic header size.
+func eventStartPosition(header *replication.EventHeader, prevPos uint32) uint32 {
+	if header.EventSize <= header.LogPos {
+		return header.LogPos - header.EventSize
+	}
+	if prevPos > 0 {
+		return prevPos
+	}
+	return uint32(len(replication.BinLogFileHeader))
+}
+
+// shortReadRegex matches the parser's "need N but got M" errors for events cut off by EOF
+var shortReadRegex = regexp.MustCompile(`need \d+ but got \d+`)
+
+// isTruncatedTail reports whether a parse error comes from a partial event at the end of
+// a binlog that is still being written, rather than corruption. The parser must have run
+// out of bytes (a short read), and the last good event must end inside the file with only
+// the partial event's bytes left after it.
+func isTruncatedTail(filepath string, lastGoodPos uint32, err error) bool {
+	if !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) && !shortReadRegex.MatchString(err.Error()) {
+		return false
+	}
+
+	info, statErr := os.Stat(filepath)
+	if statErr != nil {
+		return false
+	}
+
+	return lastGoodPos > 0 && int64(lastGoodPos) < info.Size()
+}
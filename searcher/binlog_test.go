@@ -1,14 +1,23 @@
 package searcher
 
 import (
-
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil/binlogtest"
 	"github.com/quyetmv/mysql-gtid-position/models"
+	gtidparser "github.com/quyetmv/mysql-gtid-position/parser"
 
 	"github.com/go-mysql-org/go-mysql/mysql"
 	"github.com/go-mysql-org/go-mysql/replication"
@@ -89,6 +98,85 @@ func TestGetBinlogFiles(t *testing.T) {
 	}
 }
 
+func TestGetBinlogFiles_Patterns(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, f := range []string{
+		"mysql-bin.000099", "mysql-bin.000100", "mysql-bin.000101", "mysql-bin.000102",
+		"mysql-bin.index", "relay-bin.000100",
+	} {
+		if err := os.WriteFile(filepath.Join(tmpDir, f), []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(tmpDir, "mysql-bin.000103"), 0755); err != nil {
+		t.Fatalf("Failed to create test directory: %v", err)
+	}
+
+	searcher := NewSearcher(&models.Config{})
+
+	tests := []struct {
+		name    string
+		pattern string
+		regex   bool
+		want    []string
+	}{
+		{name: "braces", pattern: "mysql-bin.{000102,000100}", want: []string{"mysql-bin.000100", "mysql-bin.000102"}},
+		{name: "braces with glob", pattern: "{mysql,relay}-bin.00010[0-2]", want: []string{"mysql-bin.000100", "mysql-bin.000101", "mysql-bin.000102", "relay-bin.000100"}},
+		{name: "overlapping braces", pattern: "mysql-bin.{00010[0-1],000100}", want: []string{"mysql-bin.000100", "mysql-bin.000101"}},
+		{name: "regex range", pattern: `mysql-bin\.0001(0[0-1])`, regex: true, want: []string{"mysql-bin.000100", "mysql-bin.000101"}},
+		{name: "regex alternation", pattern: `(mysql|relay)-bin\.000100`, regex: true, want: []string{"mysql-bin.000100", "relay-bin.000100"}},
+		{name: "regex skips index and directories", pattern: `mysql-bin\..*`, regex: true, want: []string{"mysql-bin.000099", "mysql-bin.000100", "mysql-bin.000101", "mysql-bin.000102"}},
+		{name: "regex matches whole name", pattern: `bin\.000100`, regex: true, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var files []string
+			var err error
+			if tt.regex {
+				files, err = searcher.GetBinlogFilesByRegex(tmpDir, tt.pattern)
+			} else {
+				files, err = searcher.GetBinlogFiles(tmpDir, tt.pattern)
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			var names []string
+			for _, f := range files {
+				names = append(names, filepath.Base(f))
+			}
+			if strings.Join(names, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("Got files %v, want %v", names, tt.want)
+			}
+		})
+	}
+
+	if _, err := searcher.GetBinlogFilesByRegex(tmpDir, "mysql-bin.(0001"); err == nil {
+		t.Error("Expected error for invalid regex")
+	}
+}
+
+func TestExpandBraces(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{pattern: "mysql-bin.*", want: []string{"mysql-bin.*"}},
+		{pattern: "a{b,c}d", want: []string{"abd", "acd"}},
+		{pattern: "{a,b}{1,2}", want: []string{"a1", "a2", "b1", "b2"}},
+		{pattern: "x{a,b{1,2}}", want: []string{"xa", "xb1", "xb2"}},
+		{pattern: "x{a,b", want: []string{"x{a,b"}},
+	}
+
+	for _, tt := range tests {
+		got := expandBraces(tt.pattern)
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("expandBraces(%q) = %v, want %v", tt.pattern, got, tt.want)
+		}
+	}
+}
+
 func TestNewSearcher(t *testing.T) {
 	cfg := &models.Config{
 		BinlogDir:   "/test/dir",
@@ -111,6 +199,61 @@ func TestNewSearcher(t *testing.T) {
 	if searcher.verbose != cfg.Verbose {
 		t.Error("NewSearcher() verbose flag not set correctly")
 	}
+
+	if searcher.logger == nil {
+		t.Error("NewSearcher() logger not set")
+	}
+}
+
+func TestNewLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewLogger(&buf, models.LogFormatJSON)
+	logger.Info("scanning binlog file", "file", "mysql-bin.000001")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected JSON log line, got %q: %v", buf.String(), err)
+	}
+	if entry["msg"] != "scanning binlog file" || entry["file"] != "mysql-bin.000001" {
+		t.Errorf("Unexpected log entry: %v", entry)
+	}
+
+	buf.Reset()
+	NewLogger(&buf, models.LogFormatText).Info("scanning binlog file", "file", "mysql-bin.000001")
+	if !strings.Contains(buf.String(), "file=mysql-bin.000001") {
+		t.Errorf("Expected text log line, got %q", buf.String())
+	}
+}
+
+// TestSearchParallel_VerboseLines checks that the verbose lines of concurrent
+// workers do not interleave: the handler writes each record at once under its lock
+func TestSearchParallel_VerboseLines(t *testing.T) {
+	var binlogs []*testutil.Binlog
+	for i := 0; i < 8; i++ {
+		binlogs = append(binlogs, &testutil.Binlog{Transactions: testutil.Sequence(testutil.FixtureUUID, int64(i*10+1), int64(i*10+10))})
+	}
+	files := binlogtest.WriteBinlogs(t, t.TempDir(), binlogs)
+
+	var buf bytes.Buffer
+	searcher := NewSearcher(&models.Config{Verbose: true, Parallel: 4})
+	searcher.logger = NewLogger(&buf, models.LogFormatJSON)
+
+	// Not in any file, all of them are scanned
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(testutil.FixtureUUID + ":1000")
+	if _, err := searcher.SearchParallel(context.Background(), files, &targetGTID); err != nil {
+		t.Fatalf("SearchParallel() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) < len(files) {
+		t.Fatalf("Expected a verbose line per file at least, got %d", len(lines))
+	}
+	for _, line := range lines {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Errorf("Torn log line %q: %v", line, err)
+		}
+	}
 }
 
 func TestGetBinlogFiles_Sorting(t *testing.T) {
@@ -158,6 +301,157 @@ func TestGetBinlogFiles_Sorting(t *testing.T) {
 	}
 }
 
+func TestGetBinlogFilesFromIndex(t *testing.T) {
+	tmpDir := t.TempDir()
+	otherDir := t.TempDir()
+
+	// Index order differs from lexical order, and one file lives in another directory
+	index := strings.Join([]string{
+		"./mysql-bin.000009",
+		"",
+		"mysql-bin.000010",
+		filepath.Join(otherDir, "mysql-bin.000011"),
+	}, "\n")
+	if err := os.WriteFile(filepath.Join(tmpDir, "mysql-bin.index"), []byte(index), 0644); err != nil {
+		t.Fatalf("Failed to create index file: %v", err)
+	}
+
+	searcher := NewSearcher(&models.Config{BinlogDir: tmpDir})
+
+	files, err := searcher.GetBinlogFilesFromIndex(tmpDir, "mysql-bin.index")
+	if err != nil {
+		t.Fatalf("GetBinlogFilesFromIndex() error = %v", err)
+	}
+
+	expected := []string{
+		filepath.Join(tmpDir, "mysql-bin.000009"),
+		filepath.Join(tmpDir, "mysql-bin.000010"),
+		filepath.Join(otherDir, "mysql-bin.000011"),
+	}
+	if len(files) != len(expected) {
+		t.Fatalf("Expected %d files, got %d", len(expected), len(files))
+	}
+	for i, f := range files {
+		if f != expected[i] {
+			t.Errorf("File at index %d: got %s, want %s", i, f, expected[i])
+		}
+	}
+
+	if _, err := searcher.GetBinlogFilesFromIndex(tmpDir, "missing.index"); err == nil {
+		t.Error("GetBinlogFilesFromIndex() expected error for missing index file")
+	}
+}
+
+func TestGetBinlogFilesFromList(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Listed order is kept, even against lexical order
+	var binlogs []string
+	for _, name := range []string{"mysql-bin.000002", "other-bin.000007", "mysql-bin.000001"} {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		binlogs = append(binlogs, path)
+	}
+
+	listFile := filepath.Join(tmpDir, "files.txt")
+	if err := os.WriteFile(listFile, []byte(strings.Join(binlogs, "\n\n")+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file list: %v", err)
+	}
+
+	searcher := NewSearcher(&models.Config{})
+	files, err := searcher.GetBinlogFilesFromList(listFile)
+	if err != nil {
+		t.Fatalf("GetBinlogFilesFromList() error = %v", err)
+	}
+	if strings.Join(files, ",") != strings.Join(binlogs, ",") {
+		t.Errorf("GetBinlogFilesFromList() = %v, want %v", files, binlogs)
+	}
+
+	// Every listed path must exist
+	missingList := filepath.Join(tmpDir, "missing.txt")
+	os.WriteFile(missingList, []byte(binlogs[0]+"\n"+filepath.Join(tmpDir, "mysql-bin.000003")+"\n"), 0644)
+	if _, err := searcher.GetBinlogFilesFromList(missingList); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("GetBinlogFilesFromList() error = %v, want missing file on line 2", err)
+	}
+}
+
+func TestListBinlogFiles_FileWindow(t *testing.T) {
+	tmpDir := t.TempDir()
+	for i := 1; i <= 5; i++ {
+		path := filepath.Join(tmpDir, fmt.Sprintf("mysql-bin.%06d", i))
+		if err := os.WriteFile(path, []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		startFile  string
+		beforeFile string
+		recent     int
+		want       []string
+		wantErr    bool
+	}{
+		{name: "before file", beforeFile: "mysql-bin.000003", want: []string{"mysql-bin.000001", "mysql-bin.000002"}},
+		{name: "window", startFile: "mysql-bin.000002", beforeFile: "mysql-bin.000004", want: []string{"mysql-bin.000002", "mysql-bin.000003"}},
+		{name: "start file only", startFile: "mysql-bin.000004", want: []string{"mysql-bin.000004", "mysql-bin.000005"}},
+		{name: "empty window", startFile: "mysql-bin.000003", beforeFile: "mysql-bin.000003", wantErr: true},
+		{name: "first file", beforeFile: "mysql-bin.000001", wantErr: true},
+		{name: "unknown before file", beforeFile: "mysql-bin.000009", wantErr: true},
+		{name: "recent", recent: 2, want: []string{"mysql-bin.000004", "mysql-bin.000005"}},
+		{name: "recent in window", beforeFile: "mysql-bin.000004", recent: 2, want: []string{"mysql-bin.000002", "mysql-bin.000003"}},
+		{name: "recent over window", startFile: "mysql-bin.000004", recent: 3, want: []string{"mysql-bin.000004", "mysql-bin.000005"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searcher := NewSearcher(&models.Config{
+				BinlogDir:   tmpDir,
+				FilePattern: "mysql-bin.*",
+				StartFile:   tt.startFile,
+				BeforeFile:  tt.beforeFile,
+				Recent:      tt.recent,
+			})
+
+			files, err := searcher.ListBinlogFiles()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ListBinlogFiles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var names []string
+			for _, file := range files {
+				names = append(names, filepath.Base(file))
+			}
+			if strings.Join(names, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ListBinlogFiles() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestListBinlogFiles_SingleFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	for i := 1; i <= 2; i++ {
+		path := filepath.Join(tmpDir, fmt.Sprintf("mysql-bin.%06d", i))
+		if err := os.WriteFile(path, []byte("test"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+	}
+
+	// -dir naming a binlog file scans that file alone, whatever the pattern
+	path := filepath.Join(tmpDir, "mysql-bin.000002")
+	searcher := NewSearcher(&models.Config{BinlogDir: path, FilePattern: "mysql-bin.*"})
+	files, err := searcher.ListBinlogFiles()
+	if err != nil {
+		t.Fatalf("ListBinlogFiles() error = %v", err)
+	}
+	if len(files) != 1 || files[0] != path {
+		t.Errorf("ListBinlogFiles() = %v, want [%s]", files, path)
+	}
+}
+
 // MockBinlogParser for testing
 type MockBinlogParser struct {
 	events []interface{} // Can be specific events or errors
@@ -261,7 +555,7 @@ func TestSearchBinlogFile_Found(t *testing.T) {
 	}
 
 	// Test
-	result, err := searcher.searchBinlogFile("dummy-file", &targetGTID)
+	result, err := searcher.searchBinlogFile(context.Background(), "dummy-file", &targetGTID)
 
 	// Verify
 	if err != nil {
@@ -281,6 +575,10 @@ func TestSearchBinlogFile_Found(t *testing.T) {
 	if result.CommitPosition != 2000 {
 		t.Errorf("Expected commit position 2000 (XID end), got %d", result.CommitPosition)
 	}
+	// Group commit fields come from the GTID event
+	if result.SequenceNumber != 1 {
+		t.Errorf("Expected sequence number 1, got %d", result.SequenceNumber)
+	}
 }
 
 func TestSearchBinlogFile_NotFound(t *testing.T) {
@@ -303,7 +601,7 @@ func TestSearchBinlogFile_NotFound(t *testing.T) {
 	}
 
 	// Test
-	result, err := searcher.searchBinlogFile("dummy-file", &targetGTID)
+	result, err := searcher.searchBinlogFile(context.Background(), "dummy-file", &targetGTID)
 
 	// Verify
 	if err != nil {
@@ -331,7 +629,7 @@ func TestSearchBinlogFile_Error(t *testing.T) {
 	}
 
 	// Test
-	_, err := searcher.searchBinlogFile("dummy-file", &targetGTID)
+	_, err := searcher.searchBinlogFile(context.Background(), "dummy-file", &targetGTID)
 
 	// Verify
 	if err == nil {
@@ -401,7 +699,7 @@ func TestSearchParallel(t *testing.T) {
 	files := []string{"file1", "file2", "file3"}
 
 	// Test
-	result, err := searcher.SearchParallel(files, &targetGTID)
+	result, err := searcher.SearchParallel(context.Background(), files, &targetGTID)
 
 	// Verify
 	if err != nil {
@@ -417,13 +715,17 @@ func TestSearchParallel(t *testing.T) {
 
 // SmartMockParser dispatches to other mocks based on filename
 type SmartMockParser struct {
-	files map[string]*MockBinlogParser
+	files    map[string]*MockBinlogParser
+	fallback BinlogParser // Used for files not in the map, if set
 }
 
 func (m *SmartMockParser) ParseFile(name string, offset int64, execution replication.OnEventFunc) error {
 	if parser, ok := m.files[name]; ok {
 		return parser.ParseFile(name, offset, execution)
 	}
+	if m.fallback != nil {
+		return m.fallback.ParseFile(name, offset, execution)
+	}
 	return fmt.Errorf("file not found in mock: %s", name)
 }
 
@@ -465,7 +767,7 @@ func TestResumePosition_CommitEqualsResume(t *testing.T) {
 		},
 	}
 
-	result, err := searcher.searchBinlogFile("test-file", &targetGTID)
+	result, err := searcher.searchBinlogFile(context.Background(), "test-file", &targetGTID)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -529,7 +831,7 @@ func TestResumePosition_CommitNotEqualsResume(t *testing.T) {
 		},
 	}
 
-	result, err := searcher.searchBinlogFile("test-file", &targetGTID)
+	result, err := searcher.searchBinlogFile(context.Background(), "test-file", &targetGTID)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -605,7 +907,7 @@ func TestResumePosition_HighestGNOInRange(t *testing.T) {
 		},
 	}
 
-	result, err := searcher.searchBinlogFile("test-file", &targetGTID)
+	result, err := searcher.searchBinlogFile(context.Background(), "test-file", &targetGTID)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -675,7 +977,7 @@ func TestResumePosition_QueryEventCommit(t *testing.T) {
 		},
 	}
 
-	result, err := searcher.searchBinlogFile("test-file", &targetGTID)
+	result, err := searcher.searchBinlogFile(context.Background(), "test-file", &targetGTID)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -694,6 +996,322 @@ func TestResumePosition_QueryEventCommit(t *testing.T) {
 	}
 }
 
+// TestSetCommitDetector tests a custom transaction end for an engine that logs its
+// statements without XID or COMMIT
+func TestSetCommitDetector(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-100", targetUUID))
+
+	insertEvent := &replication.BinlogEvent{
+		Header: &replication.EventHeader{EventType: replication.QUERY_EVENT, LogPos: 1200, EventSize: 200},
+		Event:  &replication.QueryEvent{Schema: []byte("mydb"), Query: []byte("INSERT INTO t VALUES (1)")},
+	}
+	nextGTIDEvent := createGTIDEvent(targetUUID, 200)
+	nextGTIDEvent.Header.LogPos = 1300
+
+	// Every statement but BEGIN commits on its own
+	autocommit := func(e *replication.BinlogEvent) bool {
+		if e.Header.EventType != replication.QUERY_EVENT {
+			return DefaultCommitDetector(e)
+		}
+		return !isStatement(string(e.Event.(*replication.QueryEvent).Query), "BEGIN")
+	}
+
+	tests := []struct {
+		name       string
+		detector   CommitDetector
+		wantCommit uint32 // 0 = not found
+	}{
+		{name: "default", detector: nil},
+		{name: "autocommit statements", detector: autocommit, wantCommit: 1200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searcher := &Searcher{
+				config: &models.Config{},
+				parserFactory: func() BinlogParser {
+					return &MockBinlogParser{events: []interface{}{createGTIDEvent(targetUUID, 50), insertEvent, nextGTIDEvent}}
+				},
+			}
+			searcher.SetCommitDetector(tt.detector)
+
+			result, err := searcher.searchBinlogFile(context.Background(), "test-file", &targetGTID)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var commit uint32
+			if result != nil {
+				commit = result.CommitPosition
+			}
+			if commit != tt.wantCommit {
+				t.Fatalf("Expected commit position %d, got %d", tt.wantCommit, commit)
+			}
+			if result != nil && result.ResumePosition != 1300 {
+				t.Errorf("Expected resume position 1300, got %d", result.ResumePosition)
+			}
+		})
+	}
+}
+
+func TestIsCommitQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"COMMIT", true},
+		{"commit", true},
+		{"COMMIT ", true},
+		{"  COMMIT\n", true},
+		{"COMMIT;", true},
+		{"COMMIT /* xid=123 */", true},
+		{"COMMIT/*!*/;", true},
+		{"BEGIN", false},
+		{"COMMITTED", false},
+		{"SET TRANSACTION ISOLATION LEVEL READ COMMITTED", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := isCommitQuery(tt.query); got != tt.want {
+			t.Errorf("isCommitQuery(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+// TestResumePosition_QueryEventCommitVariants tests COMMIT queries with whitespace or comments
+func TestResumePosition_QueryEventCommitVariants(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-100", targetUUID))
+
+	for _, query := range []string{"COMMIT ", "COMMIT /* xid=123 */"} {
+		t.Run(query, func(t *testing.T) {
+			commitQueryEvent := &replication.BinlogEvent{
+				Header: &replication.EventHeader{
+					EventType: replication.QUERY_EVENT,
+					LogPos:    2000,
+					EventSize: 100,
+					Timestamp: uint32(time.Now().Unix()),
+				},
+				Event: &replication.QueryEvent{Query: []byte(query)},
+			}
+
+			mockParser := &MockBinlogParser{
+				events: []interface{}{createGTIDEvent(targetUUID, 50), commitQueryEvent},
+			}
+
+			searcher := &Searcher{
+				config: &models.Config{},
+				parserFactory: func() BinlogParser {
+					return mockParser
+				},
+			}
+
+			result, err := searcher.searchBinlogFile(context.Background(), "test-file", &targetGTID)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result == nil {
+				t.Fatal("Expected result, got nil")
+			}
+			if result.CommitPosition != 2000 {
+				t.Errorf("Expected commit position 2000, got %d", result.CommitPosition)
+			}
+		})
+	}
+}
+
+// TestSearchBinlogFile_Rollback tests that a rolled-back transaction is not a match
+// and does not take the commit of the next transaction
+func TestSearchBinlogFile_Rollback(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-100", targetUUID))
+
+	queryEvent := func(query string, logPos uint32) *replication.BinlogEvent {
+		return &replication.BinlogEvent{
+			Header: &replication.EventHeader{
+				EventType: replication.QUERY_EVENT,
+				LogPos:    logPos,
+				EventSize: 50,
+				Timestamp: uint32(time.Now().Unix()),
+			},
+			Event: &replication.QueryEvent{Query: []byte(query)},
+		}
+	}
+
+	rolledBack := createGTIDEvent(targetUUID, 60)
+	rolledBack.Header.LogPos = 1100
+
+	committed := createGTIDEvent(targetUUID, 50)
+	committed.Header.LogPos = 1300
+
+	xidEvent := &replication.BinlogEvent{
+		Header: &replication.EventHeader{
+			EventType: replication.XID_EVENT,
+			LogPos:    1400,
+			EventSize: 31,
+			Timestamp: uint32(time.Now().Unix()),
+		},
+		Event: &replication.XIDEvent{XID: 1},
+	}
+
+	searcher := &Searcher{
+		config: &models.Config{},
+		parserFactory: func() BinlogParser {
+			return &MockBinlogParser{events: []interface{}{
+				rolledBack,
+				queryEvent("BEGIN", 1150),
+				queryEvent("ROLLBACK", 1200),
+				committed,
+				xidEvent,
+			}}
+		},
+	}
+
+	result, err := searcher.searchBinlogFile(context.Background(), "test-file", &targetGTID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result == nil {
+		t.Fatal("Expected result, got nil")
+	}
+
+	// GNO 60 is higher but was rolled back
+	if result.GNO != 50 {
+		t.Errorf("Expected committed GNO 50, got %d", result.GNO)
+	}
+	if result.CommitPosition != 1400 {
+		t.Errorf("Expected commit position 1400, got %d", result.CommitPosition)
+	}
+}
+
+// TestSearchBinlogFile_TableFilter tests matching the TABLE_MAP events of a transaction,
+// which may map several tables
+func TestSearchBinlogFile_TableFilter(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-100", targetUUID))
+
+	tableMap := func(schema, table string) *replication.BinlogEvent {
+		return &replication.BinlogEvent{
+			Header: &replication.EventHeader{EventType: replication.TABLE_MAP_EVENT, LogPos: 1050, EventSize: 50},
+			Event:  &replication.TableMapEvent{Schema: []byte(schema), Table: []byte(table)},
+		}
+	}
+	xidEvent := func(logPos uint32) *replication.BinlogEvent {
+		return &replication.BinlogEvent{
+			Header: &replication.EventHeader{EventType: replication.XID_EVENT, LogPos: logPos, EventSize: 31},
+			Event:  &replication.XIDEvent{XID: 1},
+		}
+	}
+
+	// GNO 50 writes mydb.users then mydb.orders, GNO 60 only mydb.users
+	events := []interface{}{
+		createGTIDEvent(targetUUID, 50),
+		tableMap("mydb", "users"),
+		tableMap("mydb", "orders"),
+		xidEvent(1100),
+		createGTIDEvent(targetUUID, 60),
+		tableMap("mydb", "users"),
+		xidEvent(1200),
+	}
+
+	tests := []struct {
+		name        string
+		filterTable string
+		wantGNO     uint64 // 0 = not found
+	}{
+		{name: "no filter", wantGNO: 60},
+		{name: "second table of transaction", filterTable: "orders", wantGNO: 50},
+		{name: "qualified", filterTable: "mydb.users", wantGNO: 60},
+		{name: "other schema", filterTable: "other.orders", wantGNO: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searcher := &Searcher{
+				config: &models.Config{FilterTable: tt.filterTable},
+				parserFactory: func() BinlogParser {
+					return &MockBinlogParser{events: events}
+				},
+			}
+
+			result, err := searcher.searchBinlogFile(context.Background(), "test-file", &targetGTID)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var gno uint64
+			if result != nil {
+				gno = result.GNO
+			}
+			if gno != tt.wantGNO {
+				t.Errorf("Expected GNO %d, got %d", tt.wantGNO, gno)
+			}
+		})
+	}
+}
+
+// TestResumePosition_FilterForDisplayOnly tests that a filter for display only resumes
+// at the transaction right after the match, even when the filter drops it
+func TestResumePosition_FilterForDisplayOnly(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-60", targetUUID))
+
+	tableMap := func(table string) *replication.BinlogEvent {
+		return &replication.BinlogEvent{
+			Header: &replication.EventHeader{EventType: replication.TABLE_MAP_EVENT, LogPos: 1050, EventSize: 50},
+			Event:  &replication.TableMapEvent{Schema: []byte("mydb"), Table: []byte(table)},
+		}
+	}
+	xidEvent := func(logPos uint32) *replication.BinlogEvent {
+		return &replication.BinlogEvent{
+			Header: &replication.EventHeader{EventType: replication.XID_EVENT, LogPos: logPos, EventSize: 31},
+			Event:  &replication.XIDEvent{XID: 1},
+		}
+	}
+	gtidEvent := func(gno int64, logPos uint32) *replication.BinlogEvent {
+		e := createGTIDEvent(targetUUID, gno)
+		e.Header.LogPos = logPos
+		return e
+	}
+
+	// GNO 50 writes orders, GNO 60 users, GNO 70 is past the target set
+	events := []interface{}{
+		gtidEvent(50, 1000), tableMap("orders"), xidEvent(1100),
+		gtidEvent(60, 1200), tableMap("users"), xidEvent(1300),
+		gtidEvent(70, 1400), tableMap("users"), xidEvent(1500),
+	}
+
+	tests := []struct {
+		name        string
+		displayOnly bool
+		wantNext    string
+		wantResume  uint32
+	}{
+		{name: "filtered stream", wantNext: targetUUID + ":70", wantResume: 1400},
+		{name: "display only", displayOnly: true, wantNext: targetUUID + ":60", wantResume: 1200},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searcher := &Searcher{
+				config: &models.Config{FilterTable: "orders", FilterForDisplayOnly: tt.displayOnly},
+				parserFactory: func() BinlogParser {
+					return &MockBinlogParser{events: events}
+				},
+			}
+
+			result, err := searcher.searchBinlogFile(context.Background(), "test-file", &targetGTID)
+			if err != nil || result == nil {
+				t.Fatalf("searchBinlogFile() = %v, %v", result, err)
+			}
+			if result.GNO != 50 || result.NextGTID != tt.wantNext || result.ResumePosition != tt.wantResume {
+				t.Errorf("Expected GNO 50, next %s at %d, got GNO %d, next %s at %d",
+					tt.wantNext, tt.wantResume, result.GNO, result.NextGTID, result.ResumePosition)
+			}
+		})
+	}
+}
+
 // TestResumePosition_DatabaseFilter tests database filtering
 func TestResumePosition_DatabaseFilter(t *testing.T) {
 	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
@@ -769,7 +1387,7 @@ func TestResumePosition_DatabaseFilter(t *testing.T) {
 		},
 	}
 
-	result, err := searcher.searchBinlogFile("test-file", &targetGTID)
+	result, err := searcher.searchBinlogFile(context.Background(), "test-file", &targetGTID)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -822,7 +1440,7 @@ func TestResumePosition_StartPosition(t *testing.T) {
 		},
 	}
 
-	result, err := searcher.searchBinlogFile("test-file", &targetGTID)
+	result, err := searcher.searchBinlogFile(context.Background(), "test-file", &targetGTID)
 
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
@@ -837,3 +1455,1195 @@ func TestResumePosition_StartPosition(t *testing.T) {
 		t.Errorf("Expected start position %d (LogPos - EventSize), got %d", expectedStartPos, result.Position)
 	}
 }
+
+// TestResumePosition_StartPositionUnderflow tests a GTID event whose EventSize exceeds
+// its LogPos: the start position must not wrap around uint32
+func TestResumePosition_StartPositionUnderflow(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-100", targetUUID))
+
+	xidEvent := func(logPos uint32) *replication.BinlogEvent {
+		return &replication.BinlogEvent{
+			Header: &replication.EventHeader{
+				EventType: replication.XID_EVENT,
+				LogPos:    logPos,
+				EventSize: 31,
+				Timestamp: uint32(time.Now().Unix()),
+			},
+			Event: &replication.XIDEvent{XID: 123},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		previous  []interface{} // Events before the GTID event
+		wantStart uint32
+	}{
+		{name: "first event", wantStart: 4},
+		{name: "after previous event", previous: []interface{}{xidEvent(60)}, wantStart: 60},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gtidEvent := createGTIDEvent(targetUUID, 50)
+			gtidEvent.Header.LogPos = 50 // Smaller than the event
+			gtidEvent.Header.EventSize = 100
+
+			mockParser := &MockBinlogParser{
+				events: append(tt.previous, gtidEvent, xidEvent(2000)),
+			}
+
+			searcher := &Searcher{
+				config: &models.Config{},
+				parserFactory: func() BinlogParser {
+					return mockParser
+				},
+			}
+
+			result, err := searcher.searchBinlogFile(context.Background(), "test-file", &targetGTID)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result == nil {
+				t.Fatal("Expected result, got nil")
+			}
+			if result.Position != tt.wantStart {
+				t.Errorf("Expected start position %d, got %d", tt.wantStart, result.Position)
+			}
+		})
+	}
+}
+
+// TestFindFirstMissing tests locating the first transaction a replica has not executed
+func TestFindFirstMissing(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	executed, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-2", targetUUID))
+
+	newXIDEvent := func(logPos uint32) *replication.BinlogEvent {
+		return &replication.BinlogEvent{
+			Header: &replication.EventHeader{
+				EventType: replication.XID_EVENT,
+				LogPos:    logPos,
+				EventSize: 31,
+				Timestamp: uint32(time.Now().Unix()),
+			},
+			Event: &replication.XIDEvent{XID: 1},
+		}
+	}
+
+	gtid1 := createGTIDEvent(targetUUID, 1)
+	gtid1.Header.LogPos = 200
+	gtid2 := createGTIDEvent(targetUUID, 2)
+	gtid2.Header.LogPos = 400
+	gtid3 := createGTIDEvent(targetUUID, 3)
+	gtid3.Header.LogPos = 300
+	gtid4 := createGTIDEvent(targetUUID, 4)
+	gtid4.Header.LogPos = 500
+
+	smartMockParser := &SmartMockParser{
+		files: map[string]*MockBinlogParser{
+			"file1": {events: []interface{}{gtid1, newXIDEvent(300), gtid2, newXIDEvent(450)}},
+			"file2": {events: []interface{}{gtid3, newXIDEvent(400), gtid4, newXIDEvent(600)}},
+		},
+	}
+
+	searcher := &Searcher{
+		config: &models.Config{},
+		parserFactory: func() BinlogParser {
+			return smartMockParser
+		},
+	}
+
+	result, err := searcher.FindFirstMissing(context.Background(), []string{"file1", "file2"}, &executed, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result == nil {
+		t.Fatal("Expected result, got nil")
+	}
+
+	if result.BinlogFile != "file2" {
+		t.Errorf("Expected result from file2, got %s", result.BinlogFile)
+	}
+	if result.GNO != 3 {
+		t.Errorf("Expected GNO 3 (first missing), got %d", result.GNO)
+	}
+	if result.Position != 200 {
+		t.Errorf("Expected start position 200, got %d", result.Position)
+	}
+	if result.CommitPosition != 400 {
+		t.Errorf("Expected commit position 400, got %d", result.CommitPosition)
+	}
+	if result.ResumePosition != 500 {
+		t.Errorf("Expected resume position 500, got %d", result.ResumePosition)
+	}
+
+	// Replica that has executed everything has nothing missing
+	executedAll, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-4", targetUUID))
+	result, err = searcher.FindFirstMissing(context.Background(), []string{"file1", "file2"}, &executedAll, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result != nil {
+		t.Errorf("Expected nil result, got %s", result.GTID)
+	}
+}
+
+// TestSearchBinlogFile_ExecutedSet tests the executed GTID set accumulated up to the match
+func TestSearchBinlogFile_ExecutedSet(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	otherUUID := "a1b2c3d4-71ca-11e1-9e33-c80aa9429562"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-10", targetUUID))
+
+	previousGTIDsEvent := &replication.BinlogEvent{
+		Header: &replication.EventHeader{
+			EventType: replication.PREVIOUS_GTIDS_EVENT,
+			LogPos:    191,
+			EventSize: 71,
+		},
+		Event: &replication.PreviousGTIDsEvent{
+			GTIDSets: fmt.Sprintf("%s:1-8,%s:1-3", targetUUID, otherUUID),
+		},
+	}
+
+	newXIDEvent := func(logPos uint32) *replication.BinlogEvent {
+		return &replication.BinlogEvent{
+			Header: &replication.EventHeader{
+				EventType: replication.XID_EVENT,
+				LogPos:    logPos,
+				EventSize: 31,
+			},
+			Event: &replication.XIDEvent{XID: 1},
+		}
+	}
+
+	otherGTID := createGTIDEvent(otherUUID, 4)
+	otherGTID.Header.LogPos = 300
+	gtid9 := createGTIDEvent(targetUUID, 9)
+	gtid9.Header.LogPos = 500
+	gtid10 := createGTIDEvent(targetUUID, 10)
+	gtid10.Header.LogPos = 700
+	gtid11 := createGTIDEvent(targetUUID, 11)
+	gtid11.Header.LogPos = 900
+
+	mockParser := &MockBinlogParser{
+		events: []interface{}{
+			previousGTIDsEvent,
+			otherGTID, newXIDEvent(400),
+			gtid9, newXIDEvent(600),
+			gtid10, newXIDEvent(800),
+			gtid11, newXIDEvent(1000), // Outside target, must not be in executed set
+		},
+	}
+
+	searcher := &Searcher{
+		config: &models.Config{},
+		parserFactory: func() BinlogParser {
+			return mockParser
+		},
+	}
+
+	result, err := searcher.searchBinlogFile(context.Background(), "test-file", &targetGTID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result == nil {
+		t.Fatal("Expected result, got nil")
+	}
+
+	expected := fmt.Sprintf("%s:1-10,%s:1-4", targetUUID, otherUUID)
+	if result.ExecutedSet != expected {
+		t.Errorf("Expected executed set %s, got %s", expected, result.ExecutedSet)
+	}
+}
+
+// TestSearchBinlogFile_Selection tests first/last/highest-gno selection within a file
+func TestSearchBinlogFile_Selection(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-100", targetUUID))
+
+	newXIDEvent := func(logPos uint32) *replication.BinlogEvent {
+		return &replication.BinlogEvent{
+			Header: &replication.EventHeader{
+				EventType: replication.XID_EVENT,
+				LogPos:    logPos,
+				EventSize: 31,
+			},
+			Event: &replication.XIDEvent{XID: 1},
+		}
+	}
+
+	// GNOs out of order in binlog (e.g. multi-source), so first/last differ from highest GNO
+	gtid20 := createGTIDEvent(targetUUID, 20)
+	gtid20.Header.LogPos = 300
+	gtid50 := createGTIDEvent(targetUUID, 50)
+	gtid50.Header.LogPos = 500
+	gtid30 := createGTIDEvent(targetUUID, 30)
+	gtid30.Header.LogPos = 700
+	gtidOut := createGTIDEvent(targetUUID, 200)
+	gtidOut.Header.LogPos = 900
+
+	tests := []struct {
+		name       string
+		selection  models.Selection
+		wantGNO    uint64
+		wantResume uint32
+	}{
+		{name: "default highest gno", selection: "", wantGNO: 50, wantResume: 900},
+		{name: "highest gno", selection: models.SelectionHighestGNO, wantGNO: 50, wantResume: 900},
+		{name: "first", selection: models.SelectionFirst, wantGNO: 20, wantResume: 500},
+		{name: "last", selection: models.SelectionLast, wantGNO: 30, wantResume: 900},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockParser := &MockBinlogParser{
+				events: []interface{}{
+					gtid20, newXIDEvent(400),
+					gtid50, newXIDEvent(600),
+					gtid30, newXIDEvent(800),
+					gtidOut, newXIDEvent(1000),
+				},
+			}
+
+			searcher := &Searcher{
+				config: &models.Config{Selection: tt.selection},
+				parserFactory: func() BinlogParser {
+					return mockParser
+				},
+			}
+
+			result, err := searcher.searchBinlogFile(context.Background(), "test-file", &targetGTID)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result == nil {
+				t.Fatal("Expected result, got nil")
+			}
+			if result.GNO != tt.wantGNO {
+				t.Errorf("Expected GNO %d, got %d", tt.wantGNO, result.GNO)
+			}
+			if result.ResumePosition != tt.wantResume {
+				t.Errorf("Expected resume position %d, got %d", tt.wantResume, result.ResumePosition)
+			}
+		})
+	}
+}
+
+// annotatingParser wraps errors like go-mysql does, keeping them in the chain
+type annotatingParser struct {
+	BinlogParser
+}
+
+func (p annotatingParser) ParseFile(name string, offset int64, execution replication.OnEventFunc) error {
+	if err := p.BinlogParser.ParseFile(name, offset, execution); err != nil {
+		return fmt.Errorf("parse FormatDescriptionEvent: %w", err)
+	}
+	return nil
+}
+
+// TestSearchBinlogFile_StopSentinel tests that early stops are told apart from parse errors
+func TestSearchBinlogFile_StopSentinel(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-100", targetUUID))
+
+	xidEvent := &replication.BinlogEvent{
+		Header: &replication.EventHeader{
+			EventType: replication.XID_EVENT,
+			LogPos:    400,
+			EventSize: 31,
+		},
+		Event: &replication.XIDEvent{XID: 1},
+	}
+	nextEvent := createGTIDEvent(targetUUID, 21)
+	nextEvent.Header.LogPos = 500
+
+	tests := []struct {
+		name    string
+		parser  BinlogParser
+		wantErr bool
+	}{
+		{
+			name:   "wrapped stop",
+			parser: annotatingParser{&MockBinlogParser{events: []interface{}{createGTIDEvent(targetUUID, 20), xidEvent, nextEvent}}},
+		},
+		{
+			name:    "error mentioning the sentinel",
+			parser:  &MockBinlogParser{events: []interface{}{createGTIDEvent(targetUUID, 20), xidEvent, fmt.Errorf("found_next_gtid")}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searcher := &Searcher{
+				config:        &models.Config{Selection: models.SelectionFirst},
+				parserFactory: func() BinlogParser { return tt.parser },
+			}
+
+			result, err := searcher.searchBinlogFile(context.Background(), "test-file", &targetGTID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("searchBinlogFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (result == nil || result.GNO != 20 || result.NextGTID != targetUUID+":21") {
+				t.Errorf("Expected GNO 20 followed by %s:21, got %+v", targetUUID, result)
+			}
+		})
+	}
+}
+
+// TestSearchBinlogFile_UnterminatedTransaction tests the warning for a matched
+// transaction whose commit is in the next file
+func TestSearchBinlogFile_UnterminatedTransaction(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-100", targetUUID))
+
+	xidEvent := &replication.BinlogEvent{
+		Header: &replication.EventHeader{
+			EventType: replication.XID_EVENT,
+			LogPos:    400,
+			EventSize: 31,
+		},
+		Event: &replication.XIDEvent{XID: 1},
+	}
+	openEvent := createGTIDEvent(targetUUID, 21)
+	openEvent.Header.LogPos = 500
+
+	tests := []struct {
+		name     string
+		events   []interface{}
+		wantWarn bool
+	}{
+		{name: "committed", events: []interface{}{createGTIDEvent(targetUUID, 20), xidEvent}},
+		{name: "commit in next file", events: []interface{}{createGTIDEvent(targetUUID, 20), xidEvent, openEvent}, wantWarn: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			searcher := &Searcher{
+				config:        &models.Config{},
+				logger:        NewLogger(&logs, models.LogFormatText),
+				parserFactory: func() BinlogParser { return &MockBinlogParser{events: tt.events} },
+			}
+
+			result, err := searcher.searchBinlogFile(context.Background(), "test-file", &targetGTID)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result == nil || result.GNO != 20 {
+				t.Errorf("Expected the committed GNO 20, got %+v", result)
+			}
+
+			warned := strings.Contains(logs.String(), targetUUID+":21")
+			if warned != tt.wantWarn {
+				t.Errorf("Expected warning %v, got log %q", tt.wantWarn, logs.String())
+			}
+		})
+	}
+}
+
+// TestSearchParallel_Selection tests first/last selection across files
+func TestSearchParallel_Selection(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-100", targetUUID))
+
+	xidEvent := &replication.BinlogEvent{
+		Header: &replication.EventHeader{
+			EventType: replication.XID_EVENT,
+			LogPos:    2000,
+			EventSize: 100,
+		},
+		Event: &replication.XIDEvent{XID: 123},
+	}
+
+	smartMockParser := &SmartMockParser{
+		files: map[string]*MockBinlogParser{
+			"file1": {events: []interface{}{createGTIDEvent(targetUUID, 60), xidEvent}},
+			"file2": {events: []interface{}{createGTIDEvent(targetUUID, 90), xidEvent}},
+			"file3": {events: []interface{}{createGTIDEvent(targetUUID, 70), xidEvent}},
+		},
+	}
+
+	tests := []struct {
+		selection models.Selection
+		wantFile  string
+	}{
+		{selection: models.SelectionFirst, wantFile: "file1"},
+		{selection: models.SelectionLast, wantFile: "file3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.selection), func(t *testing.T) {
+			searcher := &Searcher{
+				config: &models.Config{Parallel: 3, Selection: tt.selection},
+				parserFactory: func() BinlogParser {
+					return smartMockParser
+				},
+			}
+
+			result, err := searcher.SearchParallel(context.Background(), []string{"file1", "file2", "file3"}, &targetGTID)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if result == nil {
+				t.Fatal("Expected result, got nil")
+			}
+			if result.BinlogFile != tt.wantFile {
+				t.Errorf("Expected result from %s, got %s", tt.wantFile, result.BinlogFile)
+			}
+		})
+	}
+}
+
+// TestSearchBinlogFile_TruncatedTail tests that a partial event at the end of an
+// active binlog keeps the match found earlier instead of failing the search
+func TestSearchBinlogFile_TruncatedTail(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-100", targetUUID))
+
+	// 2050 bytes on disk: last complete event ends at 2000, 50 bytes of a partial event follow
+	path := filepath.Join(t.TempDir(), "mysql-bin.000002")
+	if err := os.WriteFile(path, make([]byte, 2050), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	xidEvent := &replication.BinlogEvent{
+		Header: &replication.EventHeader{
+			EventType: replication.XID_EVENT,
+			LogPos:    2000,
+			EventSize: 31,
+		},
+		Event: &replication.XIDEvent{XID: 1},
+	}
+
+	tests := []struct {
+		name    string
+		file    string
+		readErr error
+		wantErr bool
+	}{
+		{
+			name:    "partial event at tail",
+			file:    path,
+			readErr: fmt.Errorf("get event err unexpected EOF, need 120 but got 50"),
+			wantErr: false,
+		},
+		{
+			name:    "unexpected EOF at tail",
+			file:    path,
+			readErr: io.ErrUnexpectedEOF,
+			wantErr: false,
+		},
+		{
+			name:    "checksum failure is corruption",
+			file:    path,
+			readErr: fmt.Errorf("checksum mismatch"),
+			wantErr: true,
+		},
+		{
+			name:    "short read past file size is corruption",
+			file:    filepath.Join(t.TempDir(), "missing"),
+			readErr: fmt.Errorf("get event err unexpected EOF, need 120 but got 50"),
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockParser := &MockBinlogParser{
+				events: []interface{}{createGTIDEvent(targetUUID, 50), xidEvent, tt.readErr},
+			}
+
+			searcher := &Searcher{
+				config: &models.Config{},
+				parserFactory: func() BinlogParser {
+					return mockParser
+				},
+			}
+
+			result, err := searcher.searchBinlogFile(context.Background(), tt.file, &targetGTID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("searchBinlogFile() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && (result == nil || result.GNO != 50) {
+				t.Errorf("Expected match with GNO 50 before the partial event, got %v", result)
+			}
+		})
+	}
+}
+
+// EndlessMockParser emits non-matching transactions until the callback stops it,
+// like a huge binlog that cannot be scanned before the deadline
+type EndlessMockParser struct{}
+
+func (m *EndlessMockParser) ParseFile(name string, offset int64, execution replication.OnEventFunc) error {
+	for gno := int64(1000); ; gno++ {
+		if err := execution(createGTIDEvent("4e11fa47-71ca-11e1-9e33-c80aa9429562", gno)); err != nil {
+			return err
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSearchParallel_Timeout(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-100", targetUUID))
+
+	xidEvent := &replication.BinlogEvent{
+		Header: &replication.EventHeader{
+			EventType: replication.XID_EVENT,
+			LogPos:    2000,
+			EventSize: 31,
+		},
+		Event: &replication.XIDEvent{XID: 1},
+	}
+
+	searcher := &Searcher{
+		config: &models.Config{Parallel: 2, Timeout: 50 * time.Millisecond, Selection: models.SelectionLast}, // No early cancel, file2 must hit the deadline
+		parserFactory: func() BinlogParser {
+			return &SmartMockParser{
+				files: map[string]*MockBinlogParser{
+					"file1": {events: []interface{}{createGTIDEvent(targetUUID, 50), xidEvent}},
+				},
+				fallback: &EndlessMockParser{},
+			}
+		},
+	}
+
+	start := time.Now()
+	result, err := searcher.SearchParallel(context.Background(), []string{"file1", "file2"}, &targetGTID)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Search did not stop at the deadline, took %v", elapsed)
+	}
+
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("Expected TimeoutError, got %v", err)
+	}
+	if timeoutErr.Scanned != 1 || timeoutErr.Total != 2 {
+		t.Errorf("Expected 1/2 files scanned, got %d/%d", timeoutErr.Scanned, timeoutErr.Total)
+	}
+	if result == nil || result.GNO != 50 {
+		t.Errorf("Expected partial result with GNO 50, got %v", result)
+	}
+}
+
+// TestSequentialModes_Timeout checks that the modes scanning files one by one stop at
+// Config.Timeout too, with a *TimeoutError and no result
+func TestSequentialModes_Timeout(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(targetUUID + ":1")
+	executed, _ := mysql.ParseMysqlGTIDSet("4e11fa47-71ca-11e1-9e33-c80aa9429562:1-1000000000") // Every GTID of EndlessMockParser
+	expr, err := gtidparser.ParseGTIDExpr("contains(" + targetUUID + ":1)")
+	if err != nil {
+		t.Fatalf("ParseGTIDExpr() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		search func(s *Searcher) (any, error)
+	}{
+		{name: "first missing", search: func(s *Searcher) (any, error) {
+			return s.FindFirstMissing(context.Background(), []string{"file1", "file2"}, &executed, nil)
+		}},
+		{name: "neighbor", search: func(s *Searcher) (any, error) {
+			return s.FindNeighbor(context.Background(), []string{"file1", "file2"}, targetUUID+":1", true)
+		}},
+		{name: "last", search: func(s *Searcher) (any, error) {
+			return s.LastGTID(context.Background(), []string{"file1", "file2"})
+		}},
+		{name: "expr", search: func(s *Searcher) (any, error) {
+			return s.SearchExpr(context.Background(), []string{"file1", "file2"}, expr)
+		}},
+		{name: "contains", search: func(s *Searcher) (any, error) {
+			return s.Contains(context.Background(), []string{"file1", "file2"}, &targetGTID)
+		}},
+		{name: "executed set", search: func(s *Searcher) (any, error) {
+			return s.ExecutedSet(context.Background(), []string{"file1", "file2"})
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searcher := &Searcher{
+				config: &models.Config{Timeout: 50 * time.Millisecond, NoSmartStart: true},
+				parserFactory: func() BinlogParser {
+					return &EndlessMockParser{}
+				},
+			}
+
+			start := time.Now()
+			_, err := tt.search(searcher)
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Fatalf("Search did not stop at the deadline, took %v", elapsed)
+			}
+
+			var timeoutErr *TimeoutError
+			if !errors.As(err, &timeoutErr) {
+				t.Fatalf("Expected TimeoutError, got %v", err)
+			}
+			if timeoutErr.Scanned != 0 || timeoutErr.Total != 2 {
+				t.Errorf("Expected 0/2 files scanned, got %d/%d", timeoutErr.Scanned, timeoutErr.Total)
+			}
+		})
+	}
+}
+
+func TestSearchParallel_BoundedWorkers(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-100", targetUUID))
+
+	xidEvent := &replication.BinlogEvent{
+		Header: &replication.EventHeader{
+			EventType: replication.XID_EVENT,
+			LogPos:    2000,
+			EventSize: 31,
+		},
+		Event: &replication.XIDEvent{XID: 1},
+	}
+
+	files := make([]string, 500)
+	for i := range files {
+		files[i] = fmt.Sprintf("file%d", i)
+	}
+
+	tests := []struct {
+		name     string
+		found    map[string]*MockBinlogParser
+		fallback BinlogParser
+		timeout  time.Duration
+	}{
+		{name: "every file fails"}, // More errors than the error channel holds
+		{
+			name:     "match cancels the rest",
+			found:    map[string]*MockBinlogParser{"file3": {events: []interface{}{createGTIDEvent(targetUUID, 50), xidEvent}}},
+			fallback: &EndlessMockParser{},
+			timeout:  5 * time.Second,
+		},
+		{name: "deadline", fallback: &EndlessMockParser{}, timeout: 50 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := runtime.NumGoroutine()
+
+			searcher := &Searcher{
+				config: &models.Config{Parallel: 4, Timeout: tt.timeout},
+				parserFactory: func() BinlogParser {
+					return &SmartMockParser{files: tt.found, fallback: tt.fallback}
+				},
+			}
+			searcher.SearchParallel(context.Background(), files, &targetGTID)
+
+			// Workers exit right after the search returns, give them a moment
+			deadline := time.Now().Add(2 * time.Second)
+			for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if after := runtime.NumGoroutine(); after > before {
+				t.Errorf("Goroutines leaked: %d before, %d after", before, after)
+			}
+		})
+	}
+}
+
+func TestSearchParallel_ResultHook(t *testing.T) {
+	files, err := testutil.WriteFixtures(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(testutil.FixtureUUID + ":150")
+
+	t.Run("enrich", func(t *testing.T) {
+		searcher := NewSearcher(&models.Config{Parallel: 2})
+		searcher.SetResultHook(func(position *models.GTIDPosition) error {
+			position.Database = "deployed-by-app"
+			return nil
+		})
+
+		result, err := searcher.SearchParallel(context.Background(), files, &targetGTID)
+		if err != nil {
+			t.Fatalf("SearchParallel() error = %v", err)
+		}
+		if result == nil || result.Database != "deployed-by-app" {
+			t.Errorf("Expected the hook to enrich the result, got %+v", result)
+		}
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		hookErr := errors.New("lookup failed")
+		searcher := NewSearcher(&models.Config{Parallel: 2})
+		searcher.SetResultHook(func(position *models.GTIDPosition) error {
+			return hookErr
+		})
+
+		result, err := searcher.SearchParallel(context.Background(), files, &targetGTID)
+		if !errors.Is(err, hookErr) {
+			t.Errorf("Expected the hook error, got %v", err)
+		}
+		if result != nil {
+			t.Errorf("Expected no result, got %+v", result)
+		}
+	})
+}
+
+func TestSearchParallel_MatchInterval(t *testing.T) {
+	files, err := testutil.WriteFixtures(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+
+	tests := []struct {
+		selection models.Selection
+		wantGNO   uint64
+		want      [2]uint64
+	}{
+		{models.SelectionFirst, 1, [2]uint64{1, 10}},
+		{models.SelectionLast, 150, [2]uint64{100, 150}},
+	}
+
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(testutil.FixtureUUID + ":1-10:100-150")
+	for _, tt := range tests {
+		t.Run(string(tt.selection), func(t *testing.T) {
+			searcher := NewSearcher(&models.Config{Parallel: 2, Selection: tt.selection})
+			result, err := searcher.SearchParallel(context.Background(), files, &targetGTID)
+			if err != nil {
+				t.Fatalf("SearchParallel() error = %v", err)
+			}
+			if result == nil || result.GNO != tt.wantGNO || result.MatchInterval != tt.want {
+				t.Errorf("Expected GNO %d in interval %v, got %+v", tt.wantGNO, tt.want, result)
+			}
+		})
+	}
+}
+
+func TestSearchParallel_GNOWindow(t *testing.T) {
+	uuidA := testutil.FixtureUUID
+	uuidB := "4e11fa47-71ca-11e1-9e33-c80aa9429562"
+	path := filepath.Join(t.TempDir(), "mysql-bin.000001")
+	binlog := testutil.Binlog{Transactions: append(testutil.Sequence(uuidA, 1, 50), testutil.Sequence(uuidB, 1, 20)...)}
+	if err := binlog.WriteFile(path); err != nil {
+		t.Fatalf("Failed to write binlog: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		target    string // Empty matches any server UUID
+		minGNO    uint64
+		maxGNO    uint64
+		selection models.Selection
+		want      string
+	}{
+		{name: "any uuid highest", minGNO: 10, maxGNO: 30, selection: models.SelectionHighestGNO, want: uuidA + ":30"},
+		{name: "any uuid first", minGNO: 10, maxGNO: 30, selection: models.SelectionFirst, want: uuidA + ":10"},
+		{name: "any uuid last", minGNO: 10, maxGNO: 30, selection: models.SelectionLast, want: uuidB + ":20"},
+		{name: "open window", minGNO: 45, selection: models.SelectionLast, want: uuidA + ":50"},
+		{name: "within target", target: uuidB + ":1-20", minGNO: 5, maxGNO: 15, selection: models.SelectionHighestGNO, want: uuidB + ":15"},
+		{name: "outside window", minGNO: 60, selection: models.SelectionHighestGNO},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var targetGTID mysql.GTIDSet = &mysql.MysqlGTIDSet{Sets: make(map[string]*mysql.UUIDSet)}
+			if tt.target != "" {
+				targetGTID, _ = mysql.ParseMysqlGTIDSet(tt.target)
+			}
+
+			searcher := NewSearcher(&models.Config{Parallel: 1, Selection: tt.selection, MinGNO: tt.minGNO, MaxGNO: tt.maxGNO})
+			result, err := searcher.SearchParallel(context.Background(), []string{path}, &targetGTID)
+			if err != nil {
+				t.Fatalf("SearchParallel() error = %v", err)
+			}
+			if tt.want == "" {
+				if result != nil {
+					t.Errorf("Expected no match, got %s", result.GTID)
+				}
+				return
+			}
+			if result == nil || result.GTID != tt.want {
+				t.Errorf("Expected %s, got %+v", tt.want, result)
+			}
+		})
+	}
+}
+
+func TestServerVersion(t *testing.T) {
+	files, err := testutil.WriteFixtures(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(testutil.FixtureUUID + ":1-300")
+
+	searcher := NewSearcher(&models.Config{Parallel: 2, Selection: models.SelectionLast})
+	if version := searcher.ServerVersion(); version != "" {
+		t.Errorf("Expected no server version before scanning, got %q", version)
+	}
+
+	if _, err := searcher.SearchParallel(context.Background(), files, &targetGTID); err != nil {
+		t.Fatalf("SearchParallel() error = %v", err)
+	}
+	if version := searcher.ServerVersion(); version != "8.0.30" {
+		t.Errorf("Expected server version 8.0.30, got %q", version)
+	}
+
+	// An archive spanning an upgrade lists both versions
+	searcher.observeServerVersion(files[0], "5.7.44")
+	if version := searcher.ServerVersion(); version != "5.7.44, 8.0.30" {
+		t.Errorf("Expected both server versions, got %q", version)
+	}
+}
+
+func TestCheckPurged(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+
+	previousEvent := &replication.BinlogEvent{
+		Header: &replication.EventHeader{
+			EventType: replication.PREVIOUS_GTIDS_EVENT,
+			LogPos:    197,
+			EventSize: 71,
+		},
+		Event: &replication.PreviousGTIDsEvent{GTIDSets: targetUUID + ":1-100"},
+	}
+
+	tests := []struct {
+		name         string
+		target       string
+		startFile    string
+		noSmartStart bool
+		wantPurged   bool
+	}{
+		{name: "fully purged", target: targetUUID + ":50-60", wantPurged: true},
+		{name: "partly purged", target: targetUUID + ":90-110", wantPurged: false},
+		{name: "not purged", target: targetUUID + ":150", wantPurged: false},
+		{name: "start file skips check", target: targetUUID + ":50", startFile: "mysql-bin.000002", wantPurged: false},
+		{name: "no smart start skips check", target: targetUUID + ":50", noSmartStart: true, wantPurged: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targetGTID, _ := mysql.ParseMysqlGTIDSet(tt.target)
+
+			searcher := &Searcher{
+				config: &models.Config{StartFile: tt.startFile, NoSmartStart: tt.noSmartStart},
+				parserFactory: func() BinlogParser {
+					return &MockBinlogParser{events: []interface{}{previousEvent, createGTIDEvent(targetUUID, 101)}}
+				},
+			}
+
+			err := searcher.CheckPurged([]string{"mysql-bin.000001"}, &targetGTID)
+
+			var purgedErr *PurgedError
+			if errors.As(err, &purgedErr) != tt.wantPurged {
+				t.Fatalf("CheckPurged() error = %v, wantPurged %v", err, tt.wantPurged)
+			}
+			if tt.wantPurged && purgedErr.Purged != targetUUID+":1-100" {
+				t.Errorf("Expected purged set %s:1-100, got %s", targetUUID, purgedErr.Purged)
+			}
+		})
+	}
+}
+
+// TestCheckPurged_MariaDB tests that the GTID_LIST header of MariaDB binlogs is read
+// like PREVIOUS_GTIDS, and that a MySQL target skips the check instead of comparing
+func TestCheckPurged_MariaDB(t *testing.T) {
+	listEvent := &replication.BinlogEvent{
+		Header: &replication.EventHeader{EventType: replication.MARIADB_GTID_LIST_EVENT, LogPos: 285, EventSize: 59},
+		Event: &replication.MariadbGTIDListEvent{GTIDs: []mysql.MariadbGTID{
+			{DomainID: 0, ServerID: 1, SequenceNumber: 100},
+			{DomainID: 1, ServerID: 2, SequenceNumber: 20},
+		}},
+	}
+
+	tests := []struct {
+		name       string
+		target     func() (mysql.GTIDSet, error)
+		wantPurged bool
+	}{
+		{name: "fully purged", target: func() (mysql.GTIDSet, error) { return mysql.ParseMariadbGTIDSet("0-1-50,1-2-20") }, wantPurged: true},
+		{name: "not purged", target: func() (mysql.GTIDSet, error) { return mysql.ParseMariadbGTIDSet("0-1-101") }, wantPurged: false},
+		{name: "mysql target", target: func() (mysql.GTIDSet, error) {
+			return mysql.ParseMysqlGTIDSet("3e11fa47-71ca-11e1-9e33-c80aa9429562:1")
+		}, wantPurged: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targetGTID, err := tt.target()
+			if err != nil {
+				t.Fatalf("Failed to parse target: %v", err)
+			}
+
+			searcher := &Searcher{
+				config: &models.Config{},
+				parserFactory: func() BinlogParser {
+					return &MockBinlogParser{events: []interface{}{listEvent}}
+				},
+			}
+
+			err = searcher.CheckPurged([]string{"mariadb-bin.000001"}, &targetGTID)
+
+			var purgedErr *PurgedError
+			if errors.As(err, &purgedErr) != tt.wantPurged {
+				t.Fatalf("CheckPurged() error = %v, wantPurged %v", err, tt.wantPurged)
+			}
+		})
+	}
+
+	// MariaDB binlogs have no MySQL previous GTIDs
+	searcher := &Searcher{
+		config:        &models.Config{},
+		parserFactory: func() BinlogParser { return &MockBinlogParser{events: []interface{}{listEvent}} },
+	}
+	if _, err := searcher.ReadPreviousGTIDs("mariadb-bin.000001"); err == nil {
+		t.Error("Expected error reading previous GTIDs of a MariaDB binlog, got nil")
+	}
+}
+
+// TestSearchBinlogFile_CommitTimestamp tests that the MySQL 8.0 commit timestamp of the
+// GTID event is preferred over the header timestamp for time filters and the result
+func TestSearchBinlogFile_CommitTimestamp(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-100", targetUUID))
+
+	headerTime := time.Date(2025, 12, 29, 15, 0, 0, 0, time.UTC)
+	commitTime := headerTime.Add(10 * time.Minute)
+
+	tests := []struct {
+		name            string
+		immediateCommit uint64
+		originalCommit  uint64
+		wantFound       bool
+		wantTimestamp   uint32
+	}{
+		{name: "immediate commit timestamp", immediateCommit: uint64(commitTime.UnixMicro()), wantFound: true, wantTimestamp: uint32(commitTime.Unix())},
+		{name: "original commit timestamp", originalCommit: uint64(commitTime.UnixMicro()), wantFound: true, wantTimestamp: uint32(commitTime.Unix())},
+		{name: "header timestamp before 8.0", wantFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gtidEvent := createGTIDEvent(targetUUID, 50)
+			gtidEvent.Header.Timestamp = uint32(headerTime.Unix())
+			gtidEvent.Event.(*replication.GTIDEvent).ImmediateCommitTimestamp = tt.immediateCommit
+			gtidEvent.Event.(*replication.GTIDEvent).OriginalCommitTimestamp = tt.originalCommit
+
+			xidEvent := &replication.BinlogEvent{
+				Header: &replication.EventHeader{
+					EventType: replication.XID_EVENT,
+					LogPos:    2000,
+					EventSize: 31,
+					Timestamp: uint32(headerTime.Unix()),
+				},
+				Event: &replication.XIDEvent{XID: 1},
+			}
+
+			searcher := &Searcher{
+				// Only the commit time falls inside the window
+				config: &models.Config{StartTime: headerTime.Add(5 * time.Minute)},
+				parserFactory: func() BinlogParser {
+					return &MockBinlogParser{events: []interface{}{gtidEvent, xidEvent}}
+				},
+			}
+
+			result, err := searcher.searchBinlogFile(context.Background(), "test.bin", &targetGTID)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if (result != nil) != tt.wantFound {
+				t.Fatalf("Expected found = %v, got %v", tt.wantFound, result)
+			}
+			if tt.wantFound && result.Timestamp != tt.wantTimestamp {
+				t.Errorf("Expected timestamp %d, got %d", tt.wantTimestamp, result.Timestamp)
+			}
+		})
+	}
+}
+
+func TestNewSearcherWithParser(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-100", targetUUID))
+
+	xidEvent := &replication.BinlogEvent{
+		Header: &replication.EventHeader{
+			EventType: replication.XID_EVENT,
+			LogPos:    2000,
+			EventSize: 31,
+		},
+		Event: &replication.XIDEvent{XID: 1},
+	}
+
+	calls := 0
+	searcher := NewSearcherWithParser(&models.Config{Parallel: 1}, func() BinlogParser {
+		calls++
+		return &MockBinlogParser{events: []interface{}{createGTIDEvent(targetUUID, 50), xidEvent}}
+	})
+
+	// Paths need not exist locally, the parser decides how to read them
+	result, err := searcher.SearchParallel(context.Background(), []string{"s3://bucket/mysql-bin.000001"}, &targetGTID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected parser factory to be called once, got %d", calls)
+	}
+	if result == nil || result.GNO != 50 {
+		t.Errorf("Expected match with GNO 50 from custom parser, got %v", result)
+	}
+}
+
+// ============================================================
+// Synthetic Binlog Tests
+// ============================================================
+
+// TestSearchBinlogFile_SyntheticBinlog scans a generated binlog with the real parser
+func TestSearchBinlogFile_SyntheticBinlog(t *testing.T) {
+	uuidStr := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	path := filepath.Join(t.TempDir(), "mysql-bin.000001")
+	binlog := &testutil.Binlog{Transactions: testutil.Sequence(uuidStr, 1, 10)}
+	if err := binlog.WriteFile(path); err != nil {
+		t.Fatalf("Failed to write binlog: %v", err)
+	}
+
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(uuidStr + ":1-5")
+	searcher := NewSearcher(&models.Config{})
+
+	result, err := searcher.searchBinlogFile(context.Background(), path, &targetGTID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result == nil {
+		t.Fatal("Expected result, got nil")
+	}
+
+	if result.GNO != 5 {
+		t.Errorf("Expected GNO 5, got %d", result.GNO)
+	}
+	firstTxn := uint32(4 + testutil.FormatDescriptionEventSize)
+	if wantStart := firstTxn + 4*testutil.TransactionSize; result.Position != wantStart {
+		t.Errorf("Expected start position %d, got %d", wantStart, result.Position)
+	}
+	if wantCommit := firstTxn + 5*testutil.TransactionSize; result.CommitPosition != wantCommit {
+		t.Errorf("Expected commit position %d, got %d", wantCommit, result.CommitPosition)
+	}
+	if wantResume := firstTxn + 5*testutil.TransactionSize + testutil.GTIDEventSize; result.ResumePosition != wantResume {
+		t.Errorf("Expected resume position %d (end of next GTID), got %d", wantResume, result.ResumePosition)
+	}
+}
+
+// TestFind_Fixtures runs the whole lookup over the generated fixture binlogs
+func TestFind_Fixtures(t *testing.T) {
+	dir := t.TempDir()
+	files, err := testutil.WriteFixtures(dir)
+	if err != nil {
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+
+	previous, err := NewSearcher(&models.Config{}).ReadPreviousGTIDs(files[1])
+	if err != nil {
+		t.Fatalf("ReadPreviousGTIDs() error = %v", err)
+	}
+	if want := testutil.FixtureUUID + ":1-100"; previous.String() != want {
+		t.Errorf("Expected previous GTIDs %s, got %s", want, previous.String())
+	}
+
+	tests := []struct {
+		name     string
+		gtid     string
+		wantFile string
+		wantGNO  uint64
+	}{
+		{name: "middle file", gtid: testutil.FixtureUUID + ":1-150", wantFile: "mysql-bin.000002", wantGNO: 150},
+		{name: "last transaction", gtid: testutil.FixtureUUID + ":1-300", wantFile: "mysql-bin.000003", wantGNO: 300},
+		{name: "first file", gtid: testutil.FixtureUUID + ":1-50", wantFile: "mysql-bin.000001", wantGNO: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Find(context.Background(), &models.Config{
+				BinlogDir:  dir,
+				IndexFile:  "mysql-bin.index",
+				TargetGTID: tt.gtid,
+				Parallel:   2,
+				LogFormat:  models.LogFormatText,
+				Selection:  models.SelectionLast, // Highest GNO stops at the first file with a match
+			})
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+			if result == nil {
+				t.Fatal("Expected result, got nil")
+			}
+			if filepath.Base(result.BinlogFile) != tt.wantFile || result.GNO != tt.wantGNO {
+				t.Errorf("Expected %s GNO %d, got %s GNO %d", tt.wantFile, tt.wantGNO, filepath.Base(result.BinlogFile), result.GNO)
+			}
+			if want := int64(time.Since(time.Unix(int64(result.Timestamp), 0)).Seconds()); result.AgeSeconds < want-5 || result.AgeSeconds > want {
+				t.Errorf("Expected age about %d seconds, got %d", want, result.AgeSeconds)
+			}
+		})
+	}
+}
+
+func TestUnseenUUIDs(t *testing.T) {
+	dir := t.TempDir()
+	files, err := testutil.WriteFixtures(dir)
+	if err != nil {
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+
+	const typoUUID = "3e11fa47-71ca-11e1-9e33-c80aa9429563"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(testutil.FixtureUUID + ":1-150," + typoUUID + ":1-50")
+
+	searcher := NewSearcher(&models.Config{Parallel: 1, Selection: models.SelectionLast, LogFormat: models.LogFormatText})
+	if unseen := searcher.UnseenUUIDs(&targetGTID); len(unseen) != 2 {
+		t.Errorf("Expected both UUIDs unseen before scanning, got %v", unseen)
+	}
+
+	result, err := searcher.SearchParallel(context.Background(), files, &targetGTID)
+	if err != nil {
+		t.Fatalf("SearchParallel() error = %v", err)
+	}
+	if result == nil || result.GNO != 150 {
+		t.Fatalf("Expected GNO 150, got %+v", result)
+	}
+
+	unseen := searcher.UnseenUUIDs(&targetGTID)
+	if len(unseen) != 1 || unseen[0] != typoUUID {
+		t.Errorf("Expected unseen [%s], got %v", typoUUID, unseen)
+	}
+}
+
+// BenchmarkLocalSearch measures scanning a local binlog of 10000 transactions
+// with the real parser, reporting MB/s and transactions per second
+func BenchmarkLocalSearch(b *testing.B) {
+	const transactions = 10000
+	uuidStr := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	path := filepath.Join(b.TempDir(), "mysql-bin.000001")
+	binlog := &testutil.Binlog{Transactions: testutil.Sequence(uuidStr, 1, transactions)}
+	if err := binlog.WriteFile(path); err != nil {
+		b.Fatalf("Failed to write binlog: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		b.Fatalf("Failed to stat binlog: %v", err)
+	}
+
+	// The last transaction, so the whole file is scanned
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-%d", uuidStr, transactions))
+	searcher := NewSearcher(&models.Config{})
+
+	b.SetBytes(info.Size())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result, err := searcher.searchBinlogFile(context.Background(), path, &targetGTID)
+		if err != nil {
+			b.Fatalf("Unexpected error: %v", err)
+		}
+		if result == nil || result.GNO != transactions {
+			b.Fatalf("Expected GNO %d, got %v", transactions, result)
+		}
+	}
+
+	b.ReportMetric(float64(transactions)*float64(b.N)/b.Elapsed().Seconds(), "txns/s")
+}
@@ -0,0 +1,170 @@
+package searcher
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	gtidparser "github.com/quyetmv/mysql-gtid-position/parser"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+// bisectWindow is the byte range left to scan linearly once the target is bracketed;
+// files under twice this size are scanned whole
+var bisectWindow int64 = 16 << 20
+
+// bisectSnapChunk is how much of the file is read at once when looking for an event boundary
+const bisectSnapChunk = 64 << 10
+
+// bisectStart brackets the target of a single-UUID set in a large binlog file. It reads
+// the first GTID of that UUID after sampled offsets, snapped to the next event boundary,
+// and narrows down to the last offset whose GTID is not past the highest target GNO: the
+// highest-gno match, if any, is committed after it as GNOs grow through a file. It reports
+// false, for a linear scan of the whole file, when bisection does not apply (other
+// selections, several UUIDs, text dumps, remote or small files) or a sample cannot be
+// parsed, e.g. a boundary found in the middle of an event.
+func (s *Searcher) bisectStart(ctx context.Context, filepath string, targetGTID *mysql.GTIDSet) (uint32, bool) {
+	if !s.config.Bisect || s.config.Selection.IsOrdered() || isTextDump(filepath) {
+		return 0, false
+	}
+	target, ok := (*targetGTID).(*mysql.MysqlGTIDSet)
+	if !ok || len(target.Sets) != 1 {
+		return 0, false
+	}
+	var uuidStr string
+	var maxGNO int64
+	for sid, set := range target.Sets {
+		uuidStr = sid
+		for _, interval := range set.Intervals {
+			maxGNO = max(maxGNO, interval.Stop-1) // Intervals are half-open
+		}
+	}
+
+	info, err := os.Stat(filepath)
+	if err != nil || info.Size() < 2*bisectWindow || info.Size() > math.MaxUint32 {
+		return 0, false // Not a local file, small, or with wrapped 32-bit positions
+	}
+
+	// The first GTID of the UUID at or after lo is not past maxGNO
+	lo, hi := int64(len(replication.BinLogFileHeader)), info.Size()
+	samples := 0
+	for hi-lo > bisectWindow && ctx.Err() == nil {
+		mid := lo + (hi-lo)/2
+		start, gno, found, err := s.sampleGTID(ctx, filepath, mid, hi, uuidStr)
+		samples++
+		if err != nil {
+			s.warn("bisection failed, scanning the whole file", "file", filepath, "offset", mid, "error", err)
+			return 0, false
+		}
+		if found && gno <= maxGNO {
+			lo = start
+		} else {
+			hi = mid
+		}
+	}
+
+	if s.verbose {
+		s.logger.Info("bisected binlog file", "file", filepath, "samples", samples, "start_position", lo, "end_position", hi)
+	}
+	return uint32(lo), lo > int64(len(replication.BinLogFileHeader)) && ctx.Err() == nil
+}
+
+// sampleGTID returns the start position and GNO of the first GTID event of uuidStr
+// starting at or after offset and before limit
+func (s *Searcher) sampleGTID(ctx context.Context, filepath string, offset, limit int64, uuidStr string) (start, gno int64, found bool, err error) {
+	boundary, ok, err := findEventBoundary(filepath, offset, limit)
+	if err != nil || !ok {
+		return 0, 0, false, err
+	}
+
+	var lastGoodPos uint32
+	parser := s.parserFactory()
+	err = parseFile(ctx, parser, filepath, boundary, func(e *replication.BinlogEvent) error {
+		// Past the header the parser reads the format description first, then boundary
+		eventStart := int64(eventStartPosition(e.Header, 0))
+		if e.Header.EventType == replication.FORMAT_DESCRIPTION_EVENT && eventStart != boundary {
+			return nil
+		}
+		if lastGoodPos == 0 && eventStart != boundary {
+			return fmt.Errorf("event header at position %d says it starts at %d", boundary, eventStart)
+		}
+		lastGoodPos = e.Header.LogPos
+
+		if eventStart >= limit {
+			return errFoundNextGTID // Nothing of the UUID in the sampled range
+		}
+		if e.Header.EventType == replication.GTID_EVENT {
+			gtidEvent := e.Event.(*replication.GTIDEvent)
+			if sidStr, _ := gtidparser.FormatGTIDFromEvent(gtidEvent); sidStr == uuidStr {
+				start, gno, found = eventStart, gtidEvent.GNO, true
+				return errFoundTarget
+			}
+		}
+		return nil
+	})
+
+	if errors.Is(err, errFoundTarget) || errors.Is(err, errFoundNextGTID) {
+		err = nil
+	} else if err != nil && lastGoodPos > 0 && isTruncatedTail(filepath, lastGoodPos, err) {
+		err = nil // Active binlog still being written
+	}
+	return start, gno, found, err
+}
+
+// findEventBoundary returns the first offset in [offset, limit) where an event header
+// agrees on where it starts (END_LOG_POS minus event size) and is followed by another
+// such header or the end of the file. Row data can look like a header by chance, the
+// parser's checksum verification of the event then rejects it.
+func findEventBoundary(filepath string, offset, limit int64) (int64, bool, error) {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return 0, false, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, false, err
+	}
+
+	buf := make([]byte, bisectSnapChunk+replication.EventHeaderSize)
+	next := make([]byte, replication.EventHeaderSize)
+	for base := offset; base < limit; base += bisectSnapChunk {
+		n, err := file.ReadAt(buf, base)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, false, err
+		}
+		for i := 0; i < bisectSnapChunk && i+replication.EventHeaderSize <= n && base+int64(i) < limit; i++ {
+			at := base + int64(i)
+			size, ok := eventHeaderAt(buf[i:i+replication.EventHeaderSize], at)
+			if !ok || at+size > info.Size() {
+				continue
+			}
+			if at+size == info.Size() {
+				return at, true, nil
+			}
+			if _, err := file.ReadAt(next, at+size); err != nil {
+				continue
+			}
+			if _, ok := eventHeaderAt(next, at+size); ok {
+				return at, true, nil
+			}
+		}
+	}
+	return 0, false, nil
+}
+
+// eventHeaderAt returns the event size of a v4 event header read at position at,
+// ok when its END_LOG_POS is at plus that size
+func eventHeaderAt(header []byte, at int64) (int64, bool) {
+	eventType := header[4]
+	size := int64(binary.LittleEndian.Uint32(header[9:13]))
+	logPos := int64(binary.LittleEndian.Uint32(header[13:17]))
+	return size, eventType != byte(replication.UNKNOWN_EVENT) && size >= replication.EventHeaderSize && logPos == at+size
+}
@@ -0,0 +1,129 @@
+package searcher
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+func TestFindEventBoundary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mysql-bin.000001")
+	binlog := &testutil.Binlog{Transactions: testutil.Sequence(testutil.FixtureUUID, 1, 100)}
+	if err := binlog.WriteFile(path); err != nil {
+		t.Fatalf("Failed to write binlog: %v", err)
+	}
+
+	// Inside the GTID event of the 11th transaction, the next boundary is its XID event
+	txn := int64(4 + testutil.FormatDescriptionEventSize + 10*testutil.TransactionSize)
+	boundary, ok, err := findEventBoundary(path, txn+5, txn+testutil.TransactionSize)
+	if err != nil || !ok {
+		t.Fatalf("findEventBoundary() = %d, %v, %v", boundary, ok, err)
+	}
+	if want := txn + testutil.GTIDEventSize; boundary != want {
+		t.Errorf("findEventBoundary() = %d, want %d", boundary, want)
+	}
+
+	// No event starts strictly inside an XID event
+	xid := txn + testutil.GTIDEventSize
+	if _, ok, _ := findEventBoundary(path, xid+1, xid+testutil.XIDEventSize); ok {
+		t.Errorf("findEventBoundary() found a boundary inside an event")
+	}
+}
+
+func TestSearchBinlogFile_Bisect(t *testing.T) {
+	defer func(window int64) { bisectWindow = window }(bisectWindow)
+	bisectWindow = 8 * testutil.TransactionSize
+
+	path := filepath.Join(t.TempDir(), "mysql-bin.000001")
+	binlog := &testutil.Binlog{Transactions: testutil.Sequence(testutil.FixtureUUID, 1, 3000)}
+	if err := binlog.WriteFile(path); err != nil {
+		t.Fatalf("Failed to write binlog: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		gtid     string
+		wantGNO  uint64
+		bisected bool // Found in the window, without an executed set
+	}{
+		{name: "inside the file", gtid: testutil.FixtureUUID + ":1-2000", wantGNO: 2000, bisected: true},
+		{name: "last transaction", gtid: testutil.FixtureUUID + ":1-5000", wantGNO: 3000, bisected: true},
+		// The window after GNO 3000 has no match, the whole file is scanned again
+		{name: "gap past the file", gtid: testutil.FixtureUUID + ":1-100:5000-6000", wantGNO: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := mysql.ParseMysqlGTIDSet(tt.gtid)
+			if err != nil {
+				t.Fatalf("Failed to parse GTID set: %v", err)
+			}
+
+			linear, err := NewSearcher(&models.Config{}).searchBinlogFile(context.Background(), path, &target)
+			if err != nil || linear == nil {
+				t.Fatalf("linear searchBinlogFile() = %v, %v", linear, err)
+			}
+
+			searcher := NewSearcher(&models.Config{Bisect: true})
+			result, err := searcher.searchBinlogFile(context.Background(), path, &target)
+			if err != nil || result == nil {
+				t.Fatalf("bisected searchBinlogFile() = %v, %v", result, err)
+			}
+			if result.GNO != tt.wantGNO || result.Position != linear.Position || result.CommitPosition != linear.CommitPosition ||
+				result.NextGTID != linear.NextGTID || result.ResumePosition != linear.ResumePosition {
+				t.Errorf("bisected match %s at %d-%d, next %q at %d, want %s at %d-%d, next %q at %d",
+					result.GTID, result.Position, result.CommitPosition, result.NextGTID, result.ResumePosition,
+					linear.GTID, linear.Position, linear.CommitPosition, linear.NextGTID, linear.ResumePosition)
+			}
+			if bisected := result.ExecutedSet == ""; bisected != tt.bisected {
+				t.Errorf("bisected = %v, want %v", bisected, tt.bisected)
+			}
+
+			from, ok := searcher.bisectStart(context.Background(), path, &target)
+			if !ok {
+				t.Fatalf("bisectStart() did not bisect")
+			}
+			if tt.bisected && (from > result.Position || int64(result.Position-from) > bisectWindow) {
+				t.Errorf("bisectStart() = %d, match at %d not in the window", from, result.Position)
+			}
+		})
+	}
+}
+
+func TestBisectStart_NotApplicable(t *testing.T) {
+	defer func(window int64) { bisectWindow = window }(bisectWindow)
+	bisectWindow = 8 * testutil.TransactionSize
+
+	path := filepath.Join(t.TempDir(), "mysql-bin.000001")
+	binlog := &testutil.Binlog{Transactions: testutil.Sequence(testutil.FixtureUUID, 1, 1000)}
+	if err := binlog.WriteFile(path); err != nil {
+		t.Fatalf("Failed to write binlog: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  *models.Config
+		gtid string
+	}{
+		{name: "not enabled", cfg: &models.Config{}, gtid: testutil.FixtureUUID + ":1-500"},
+		{name: "ordered selection", cfg: &models.Config{Bisect: true, Selection: models.SelectionFirst}, gtid: testutil.FixtureUUID + ":1-500"},
+		{name: "several uuids", cfg: &models.Config{Bisect: true}, gtid: testutil.FixtureUUID + ":1-500,4e11fa47-71ca-11e1-9e33-c80aa9429562:1-5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, err := mysql.ParseMysqlGTIDSet(tt.gtid)
+			if err != nil {
+				t.Fatalf("Failed to parse GTID set: %v", err)
+			}
+			if from, ok := NewSearcher(tt.cfg).bisectStart(context.Background(), path, &target); ok {
+				t.Errorf("bisectStart() = %d, want a linear scan", from)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package searcher
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	gtidparser "github.com/quyetmv/mysql-gtid-position/parser"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+// Contains reports whether the target set is fully contained in the GTIDs of files:
+// the union of their PREVIOUS_GTIDS headers and the GTIDs they hold. Headers are read
+// first, then files are scanned newest first, stopping as soon as the target is covered.
+// It answers "is this GTID already executed" without locating a position. A partial
+// answer is of no use, so on Config.Timeout only a *TimeoutError is returned.
+func (s *Searcher) Contains(ctx context.Context, files []string, targetGTID *mysql.GTIDSet) (bool, error) {
+	if err := s.CheckAllowedUUIDs(targetGTID); err != nil {
+		return false, err
+	}
+	if s.config.Timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, s.config.Timeout)
+		defer cancelTimeout()
+	}
+
+	executed := &mysql.MysqlGTIDSet{Sets: make(map[string]*mysql.UUIDSet)}
+
+	// PREVIOUS_GTIDS of each file only need the file header
+	for _, file := range files {
+		if ctx.Err() != nil {
+			return false, s.timeoutError(ctx, ctx.Err(), 0, len(files))
+		}
+		previous, err := s.ReadPreviousGTIDs(file)
+		if err != nil {
+			return false, fmt.Errorf("error reading %s: %w", file, err)
+		}
+		executed.Add(*previous)
+	}
+	if executed.Contain(*targetGTID) {
+		return true, nil
+	}
+
+	// GTIDs of the newest file are in no header, scan from there back
+	for i := len(files) - 1; i >= 0; i-- {
+		found, err := s.scanExecutedGTIDs(ctx, files[i], executed, targetGTID)
+		if err != nil {
+			return false, s.timeoutError(ctx, fmt.Errorf("error scanning %s: %w", files[i], err), len(files)-1-i, len(files))
+		}
+		s.fileScanned()
+
+		if found {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// ExecutedSet returns every GTID executed up to the end of files: the PREVIOUS_GTIDS
+// of the last file plus the GTIDs it holds. Earlier files are not read, their GTIDs
+// are in that header. A partial set is of no use, so on Config.Timeout only a
+// *TimeoutError is returned.
+func (s *Searcher) ExecutedSet(ctx context.Context, files []string) (*mysql.MysqlGTIDSet, error) {
+	if len(files) == 0 {
+		return &mysql.MysqlGTIDSet{Sets: make(map[string]*mysql.UUIDSet)}, nil
+	}
+	if s.config.Timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, s.config.Timeout)
+		defer cancelTimeout()
+	}
+
+	last := files[len(files)-1]
+	executed, err := s.ReadPreviousGTIDs(last)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", last, err)
+	}
+	if _, err := s.scanExecutedGTIDs(ctx, last, executed, nil); err != nil {
+		return nil, s.timeoutError(ctx, fmt.Errorf("error scanning %s: %w", last, err), 0, len(files))
+	}
+	s.fileScanned()
+
+	return executed, nil
+}
+
+// UnionExecutedSet returns the PREVIOUS_GTIDS of the first file plus every GTID held
+// by files, whose intervals the set coalesces: the gtid_executed of a server whose
+// binlogs are files, the value to restore as GTID_PURGED. Unlike ExecutedSet every file
+// is read, so GTIDs missing from a later header (a gap, or files of several servers)
+// are still covered. Files are scanned with parallel workers; a partial set is of no
+// use, so on Config.Timeout only a *TimeoutError is returned.
+func (s *Searcher) UnionExecutedSet(ctx context.Context, files []string) (*mysql.MysqlGTIDSet, error) {
+	if len(files) == 0 {
+		return &mysql.MysqlGTIDSet{Sets: make(map[string]*mysql.UUIDSet)}, nil
+	}
+	if s.config.Timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, s.config.Timeout)
+		defer cancelTimeout()
+	}
+
+	executed, err := s.ReadPreviousGTIDs(files[0])
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", files[0], err)
+	}
+
+	var executedMu sync.Mutex
+	scanned, err := s.scanFilesParallel(ctx, files, func(idx int) error {
+		fileSet := &mysql.MysqlGTIDSet{Sets: make(map[string]*mysql.UUIDSet)}
+		if _, err := s.scanExecutedGTIDs(ctx, files[idx], fileSet, nil); err != nil {
+			return err
+		}
+
+		executedMu.Lock()
+		defer executedMu.Unlock()
+		return executed.Add(*fileSet)
+	})
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, &TimeoutError{Timeout: s.config.Timeout, Scanned: scanned, Total: len(files)}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return executed, nil
+}
+
+// scanExecutedGTIDs adds the GTIDs of a binlog file or text dump to executed,
+// stopping and reporting true once it contains the target set. A nil target
+// reads the whole file. Once ctx is done the scan stops with ctx.Err().
+func (s *Searcher) scanExecutedGTIDs(ctx context.Context, filepath string, executed *mysql.MysqlGTIDSet, targetGTID *mysql.GTIDSet) (bool, error) {
+	// addGTID adds one GTID and reports whether the target is now covered
+	addGTID := func(gtidStr string) bool {
+		if err := executed.Update(gtidStr); err != nil {
+			return false // Skip invalid GTIDs
+		}
+		return targetGTID != nil && executed.Contain(*targetGTID)
+	}
+
+	if isTextDump(filepath) {
+		file, err := os.Open(filepath)
+		if err != nil {
+			return false, fmt.Errorf("failed to open text dump: %w", err)
+		}
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+		for scanner.Scan() {
+			if err := ctx.Err(); err != nil {
+				return false, err // Deadline reached or cancelled
+			}
+			m := textDumpGTIDNextRegex.FindStringSubmatch(strings.TrimRight(scanner.Text(), "\r"))
+			if m != nil && m[1] != "AUTOMATIC" && m[1] != "ANONYMOUS" && addGTID(m[1]) {
+				return true, nil
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return false, fmt.Errorf("failed to read text dump: %w", err)
+		}
+		return false, nil
+	}
+
+	parser := s.parserFactory()
+	err := parseFile(ctx, parser, filepath, 0, func(e *replication.BinlogEvent) error {
+		if err := ctx.Err(); err != nil {
+			return err // Deadline reached or cancelled
+		}
+		if e.Header.EventType != replication.GTID_EVENT {
+			return nil
+		}
+
+		gtidEvent := e.Event.(*replication.GTIDEvent)
+		_, gtidStr := gtidparser.FormatGTIDFromEvent(gtidEvent)
+		if addGTID(gtidStr) {
+			return errFoundTarget
+		}
+		return nil
+	})
+
+	if errors.Is(err, errFoundTarget) {
+		return true, nil
+	}
+	return false, err
+}
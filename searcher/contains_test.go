@@ -0,0 +1,115 @@
+package searcher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil/binlogtest"
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+func TestContains(t *testing.T) {
+	dir := t.TempDir()
+	files, err := testutil.WriteFixtures(dir) // FixtureUUID:1-300 over 3 files
+	if err != nil {
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		gtid        string
+		files       []string
+		want        bool
+		wantScanned int
+	}{
+		{name: "in previous GTIDs", gtid: ":1-150", files: files, want: true, wantScanned: 0},
+		{name: "in newest file", gtid: ":1-250", files: files, want: true, wantScanned: 1},
+		{name: "beyond binlogs", gtid: ":1-301", files: files, want: false, wantScanned: 3},
+		{name: "oldest file only", gtid: ":50-60", files: files[:1], want: true, wantScanned: 1},
+		{name: "text dump", gtid: ":10", files: []string{writeTextDump(t)}, want: true, wantScanned: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targetGTID, err := mysql.ParseMysqlGTIDSet(testutil.FixtureUUID + tt.gtid)
+			if err != nil {
+				t.Fatalf("Invalid GTID: %v", err)
+			}
+
+			searcher := NewSearcher(&models.Config{})
+			got, err := searcher.Contains(context.Background(), tt.files, &targetGTID)
+			if err != nil {
+				t.Fatalf("Contains() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Contains(%s) = %v, want %v", tt.gtid, got, tt.want)
+			}
+			if searcher.ScannedFiles() != tt.wantScanned {
+				t.Errorf("Expected %d files scanned, got %d", tt.wantScanned, searcher.ScannedFiles())
+			}
+		})
+	}
+}
+
+func TestExecutedSet(t *testing.T) {
+	files, err := testutil.WriteFixtures(t.TempDir()) // FixtureUUID:1-300 over 3 files
+	if err != nil {
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		files []string
+		want  string
+	}{
+		{name: "all files", files: files, want: testutil.FixtureUUID + ":1-300"},
+		{name: "first two files", files: files[:2], want: testutil.FixtureUUID + ":1-200"},
+		{name: "no files", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searcher := NewSearcher(&models.Config{})
+			got, err := searcher.ExecutedSet(context.Background(), tt.files)
+			if err != nil {
+				t.Fatalf("ExecutedSet() error = %v", err)
+			}
+			if got.String() != tt.want {
+				t.Errorf("ExecutedSet() = %s, want %s", got.String(), tt.want)
+			}
+			if len(tt.files) > 0 && searcher.ScannedFiles() != 1 {
+				t.Errorf("Expected only the last file scanned, got %d files", searcher.ScannedFiles())
+			}
+		})
+	}
+}
+
+func TestUnionExecutedSet(t *testing.T) {
+	const otherUUID = "4c2f5a9e-0b1d-11ef-9a6b-0242ac120003"
+
+	dir := t.TempDir()
+	// The header of the second file misses 101-150 and the other server, which only
+	// the GTID events carry
+	binlogs := []*testutil.Binlog{
+		{PreviousGTIDs: testutil.FixtureUUID + ":1-100", Transactions: testutil.Sequence(testutil.FixtureUUID, 101, 150)},
+		{PreviousGTIDs: testutil.FixtureUUID + ":1-100", Transactions: append(testutil.Sequence(testutil.FixtureUUID, 151, 200), testutil.Sequence(otherUUID, 1, 5)...)},
+		{Transactions: testutil.Sequence(otherUUID, 7, 9)},
+	}
+	files := binlogtest.WriteBinlogs(t, dir, binlogs)
+
+	searcher := NewSearcher(&models.Config{Parallel: 2})
+	got, err := searcher.UnionExecutedSet(context.Background(), files)
+	if err != nil {
+		t.Fatalf("UnionExecutedSet() error = %v", err)
+	}
+	want := testutil.FixtureUUID + ":1-200," + otherUUID + ":1-5:7-9"
+	if got.String() != want {
+		t.Errorf("UnionExecutedSet() = %s, want %s", got.String(), want)
+	}
+	if searcher.ScannedFiles() != len(files) {
+		t.Errorf("Expected %d files scanned, got %d", len(files), searcher.ScannedFiles())
+	}
+}
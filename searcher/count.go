@@ -0,0 +1,87 @@
+package searcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+// CountParallel counts the committed transactions of all files that are contained in
+// the target set, using parallel workers. Database and time filters apply as in
+// SearchParallel. A file that cannot be scanned fails the count, since the total
+// would be silently short; on Config.Timeout the partial count is returned with a *TimeoutError.
+func (s *Searcher) CountParallel(ctx context.Context, files []string, targetGTID *mysql.GTIDSet) (*models.MatchCount, error) {
+	if err := s.CheckAllowedUUIDs(targetGTID); err != nil {
+		return nil, err
+	}
+
+	if s.config.Timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, s.config.Timeout)
+		defer cancelTimeout()
+	}
+
+	count := &models.MatchCount{ByUUID: make(map[string]uint64)}
+	var countMu sync.Mutex
+	var firstErr error
+	var scanned atomic.Int64
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, max(s.config.Parallel, 1))
+
+	for i, file := range files {
+		wg.Add(1)
+		go func(idx int, filepath string) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			if s.verbose {
+				s.logger.Info("counting binlog file", "index", idx+1, "total", len(files), "file", filepath)
+			}
+
+			counts := make(map[string]uint64)
+			_, err := s.scanBinlogFile(ctx, filepath, targetGTID, counts, 0)
+
+			countMu.Lock()
+			defer countMu.Unlock()
+
+			// Counts of a file stopped by the deadline are partial but still real matches
+			for uuid, n := range counts {
+				count.ByUUID[uuid] += n
+				count.Total += n
+			}
+
+			if err != nil {
+				if ctx.Err() == nil && firstErr == nil {
+					firstErr = fmt.Errorf("error scanning %s: %w", filepath, err)
+				}
+				return
+			}
+			scanned.Add(1)
+			s.fileScanned()
+		}(i, file)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return count, &TimeoutError{Timeout: s.config.Timeout, Scanned: int(scanned.Load()), Total: len(files)}
+	}
+
+	return count, nil
+}
@@ -0,0 +1,97 @@
+package searcher
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+func TestCountParallel(t *testing.T) {
+	uuidA := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	uuidB := "4e11fa47-71ca-11e1-9e33-c80aa9429562"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-100,%s:1-5", uuidA, uuidB))
+
+	xidEvent := &replication.BinlogEvent{
+		Header: &replication.EventHeader{
+			EventType: replication.XID_EVENT,
+			LogPos:    2000,
+			EventSize: 31,
+		},
+		Event: &replication.XIDEvent{XID: 1},
+	}
+
+	smartMockParser := &SmartMockParser{
+		files: map[string]*MockBinlogParser{
+			// Matches before and after a transaction outside the target set are all counted
+			"file1": {events: []interface{}{
+				createGTIDEvent(uuidA, 10), xidEvent,
+				createGTIDEvent(uuidA, 200), xidEvent,
+				createGTIDEvent(uuidA, 11), xidEvent,
+			}},
+			"file2": {events: []interface{}{
+				createGTIDEvent(uuidB, 3), xidEvent,
+				createGTIDEvent(uuidB, 4), // Never committed
+			}},
+			"file3": {events: []interface{}{
+				createGTIDEvent(uuidA, 12), xidEvent,
+			}},
+		},
+	}
+
+	searcher := &Searcher{
+		config: &models.Config{Parallel: 2},
+		parserFactory: func() BinlogParser {
+			return smartMockParser
+		},
+	}
+
+	count, err := searcher.CountParallel(context.Background(), []string{"file1", "file2", "file3"}, &targetGTID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if count.Total != 4 {
+		t.Errorf("Expected 4 matching transactions, got %d", count.Total)
+	}
+	if count.ByUUID[uuidA] != 3 || count.ByUUID[uuidB] != 1 {
+		t.Errorf("Unexpected per-UUID counts: %v", count.ByUUID)
+	}
+	if searcher.ScannedFiles() != 3 {
+		t.Errorf("Expected 3 scanned files, got %d", searcher.ScannedFiles())
+	}
+}
+
+func TestCountParallel_Error(t *testing.T) {
+	targetGTID, _ := mysql.ParseMysqlGTIDSet("3e11fa47-71ca-11e1-9e33-c80aa9429562:1-100")
+
+	searcher := &Searcher{
+		config: &models.Config{Parallel: 2},
+		parserFactory: func() BinlogParser {
+			return &MockBinlogParser{forcedError: fmt.Errorf("read error")}
+		},
+	}
+
+	// A missing file would make the count silently short
+	if _, err := searcher.CountParallel(context.Background(), []string{"file1"}, &targetGTID); err == nil {
+		t.Error("Expected error for unreadable file, got nil")
+	}
+}
+
+func TestCountParallel_TextDump(t *testing.T) {
+	targetGTID, _ := mysql.ParseMysqlGTIDSet("3e11fa47-71ca-11e1-9e33-c80aa9429562:1-100")
+
+	searcher := &Searcher{config: &models.Config{Parallel: 1}}
+
+	count, err := searcher.CountParallel(context.Background(), []string{writeTextDump(t)}, &targetGTID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if count.Total != 2 {
+		t.Errorf("Expected 2 matching transactions (Xid and COMMIT), got %d", count.Total)
+	}
+}
@@ -0,0 +1,145 @@
+package searcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+	gtidparser "github.com/quyetmv/mysql-gtid-position/parser"
+
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+// ListDatabases counts, for every database, the committed transactions of files that
+// wrote it: the schemas of their TABLE_MAP events, and of their statements in
+// statement-based binlogs (the qualified table of the SQL, else the default schema of
+// the query). A transaction writing several databases counts once for each. Files are
+// scanned with parallel workers; as with FindNeighbor the search filters do not apply,
+// except -allowed-uuids, and text dumps are not supported. Databases are sorted by
+// transaction count, most written first. On Config.Timeout the partial counts are
+// returned with a *TimeoutError.
+func (s *Searcher) ListDatabases(ctx context.Context, files []string) ([]models.DatabaseCount, error) {
+	if s.config.Timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, s.config.Timeout)
+		defer cancelTimeout()
+	}
+
+	counts := make(map[string]uint64)
+	var countsMu sync.Mutex
+	scanned, err := s.scanFilesParallel(ctx, files, func(idx int) error {
+		fileCounts, err := s.fileDatabases(ctx, files[idx])
+
+		// Counts of a file stopped by the deadline are partial but still real
+		countsMu.Lock()
+		defer countsMu.Unlock()
+		for database, count := range fileCounts {
+			counts[database] += count
+		}
+		return err
+	})
+	if err != nil && ctx.Err() == nil {
+		return nil, err
+	}
+
+	databases := make([]models.DatabaseCount, 0, len(counts))
+	for database, count := range counts {
+		databases = append(databases, models.DatabaseCount{Database: database, Transactions: count})
+	}
+	sort.Slice(databases, func(i, j int) bool {
+		if databases[i].Transactions != databases[j].Transactions {
+			return databases[i].Transactions > databases[j].Transactions
+		}
+		return databases[i].Database < databases[j].Database
+	})
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return databases, &TimeoutError{Timeout: s.config.Timeout, Scanned: scanned, Total: len(files)}
+	}
+	return databases, nil
+}
+
+// fileDatabases counts the committed transactions of a binlog file per database written
+func (s *Searcher) fileDatabases(ctx context.Context, filepath string) (map[string]uint64, error) {
+	if isTextDump(filepath) {
+		return nil, fmt.Errorf("mysqlbinlog text dumps are not supported")
+	}
+
+	counts := make(map[string]uint64)
+	var databases map[string]bool // Written by the current transaction, nil outside one
+	var begun bool                // Whether the current transaction opened with a BEGIN query
+	var lastGoodPos uint32        // END_LOG_POS of the last fully parsed event
+
+	// commit counts the current transaction once per database it wrote
+	commit := func() {
+		for database := range databases {
+			counts[database]++
+		}
+		databases = nil
+	}
+
+	parser := s.parserFactory()
+	err := parseFile(ctx, parser, filepath, 0, func(e *replication.BinlogEvent) error {
+		if err := ctx.Err(); err != nil {
+			return err // Deadline reached or cancelled
+		}
+		lastGoodPos = e.Header.LogPos
+
+		switch e.Header.EventType {
+		case replication.GTID_EVENT:
+			databases, begun = nil, false
+			uuidStr, _ := gtidparser.FormatGTIDFromEvent(e.Event.(*replication.GTIDEvent))
+			if s.uuidAllowed(uuidStr) {
+				databases = make(map[string]bool)
+			}
+
+		case replication.TABLE_MAP_EVENT:
+			if databases != nil {
+				databases[string(e.Event.(*replication.TableMapEvent).Schema)] = true
+			}
+
+		case replication.QUERY_EVENT:
+			if databases == nil {
+				return nil
+			}
+			queryEvent := e.Event.(*replication.QueryEvent)
+			query := string(queryEvent.Query)
+			switch {
+			case isStatement(query, "BEGIN"):
+				begun = true
+			case isCommitQuery(query) || isRollbackQuery(query):
+				// Transaction boundaries write no database
+			default:
+				// Statement-based DML or DDL: the qualified table, else the query schema
+				if schema, _, ok := statementTable(query); ok && schema != "" {
+					databases[schema] = true
+				} else if len(queryEvent.Schema) > 0 {
+					databases[string(queryEvent.Schema)] = true
+				}
+			}
+		}
+
+		if databases == nil {
+			return nil
+		}
+		if s.isCommit(e) {
+			commit()
+		} else if e.Header.EventType == replication.QUERY_EVENT {
+			query := string(e.Event.(*replication.QueryEvent).Query)
+			if isRollbackQuery(query) {
+				databases = nil // Nothing committed
+			} else if !begun && isDDLStatement(query) {
+				commit() // A DDL commits on its own
+			}
+		}
+		return nil
+	})
+
+	if err != nil && ctx.Err() == nil && isTruncatedTail(filepath, lastGoodPos, err) {
+		return counts, nil // Active binlog still being written
+	}
+	return counts, err
+}
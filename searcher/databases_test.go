@@ -0,0 +1,88 @@
+package searcher
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+func TestListDatabases(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+
+	query := func(schema, sql string) *replication.BinlogEvent {
+		return &replication.BinlogEvent{
+			Header: &replication.EventHeader{EventType: replication.QUERY_EVENT, LogPos: 1100, EventSize: 50},
+			Event:  &replication.QueryEvent{Schema: []byte(schema), Query: []byte(sql)},
+		}
+	}
+	tableMap := func(schema string) *replication.BinlogEvent {
+		return &replication.BinlogEvent{
+			Header: &replication.EventHeader{EventType: replication.TABLE_MAP_EVENT, LogPos: 1100, EventSize: 50},
+			Event:  &replication.TableMapEvent{Schema: []byte(schema), Table: []byte("t")},
+		}
+	}
+	xid := &replication.BinlogEvent{
+		Header: &replication.EventHeader{EventType: replication.XID_EVENT, LogPos: 1200, EventSize: 30},
+		Event:  &replication.XIDEvent{},
+	}
+
+	// GNO 1 and 2 write shop in row format, GNO 2 also audit; GNO 3 inserts into
+	// shop.orders in statement format; GNO 4 alters crm.users; GNO 5 is rolled back
+	events := []interface{}{
+		createGTIDEvent(targetUUID, 1),
+		query("", "BEGIN"),
+		tableMap("shop"),
+		xid,
+		createGTIDEvent(targetUUID, 2),
+		query("", "BEGIN"),
+		tableMap("shop"),
+		tableMap("audit"),
+		xid,
+		createGTIDEvent(targetUUID, 3),
+		query("mysql", "BEGIN"),
+		query("mysql", "INSERT INTO shop.orders VALUES (1)"),
+		query("mysql", "COMMIT"),
+		createGTIDEvent(targetUUID, 4),
+		query("crm", "ALTER TABLE users ADD COLUMN note TEXT"),
+		createGTIDEvent(targetUUID, 5),
+		query("", "BEGIN"),
+		tableMap("scratch"),
+		query("", "ROLLBACK"),
+	}
+
+	tests := []struct {
+		name         string
+		allowedUUIDs []string
+		want         []models.DatabaseCount
+	}{
+		{
+			name: "all",
+			want: []models.DatabaseCount{
+				{Database: "shop", Transactions: 3},
+				{Database: "audit", Transactions: 1},
+				{Database: "crm", Transactions: 1},
+			},
+		},
+		{name: "uuid not allowed", allowedUUIDs: []string{"00000000-0000-0000-0000-000000000001"}, want: []models.DatabaseCount{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searcher := NewSearcherWithParser(&models.Config{Parallel: 2, AllowedUUIDs: tt.allowedUUIDs}, func() BinlogParser {
+				return &MockBinlogParser{events: events}
+			})
+
+			databases, err := searcher.ListDatabases(context.Background(), []string{"mysql-bin.000001"})
+			if err != nil {
+				t.Fatalf("ListDatabases() error = %v", err)
+			}
+			if !reflect.DeepEqual(databases, tt.want) {
+				t.Errorf("ListDatabases() = %v, want %v", databases, tt.want)
+			}
+		})
+	}
+}
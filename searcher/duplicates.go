@@ -0,0 +1,181 @@
+package searcher
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+// FindDuplicates reports the GTIDs committed more than once in files, in the same file
+// or in different ones, with every copy. A first pass collects the GTID set of each file
+// and intersects them, so memory stays bounded by intervals; only when duplicates exist
+// a second pass locates their copies. Files are scanned with parallel workers. As with
+// FindNeighbor the search filters do not apply and text dumps are not supported. The
+// result is sorted by GTID; on Config.Timeout a *TimeoutError is returned.
+func (s *Searcher) FindDuplicates(ctx context.Context, files []string) ([]*models.DuplicateGTID, error) {
+	if s.config.Timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, s.config.Timeout)
+		defer cancelTimeout()
+	}
+
+	// First pass: GTIDs of each file, and those repeated inside it
+	fileSets := make([]*mysql.MysqlGTIDSet, len(files))
+	duplicated := &mysql.MysqlGTIDSet{Sets: make(map[string]*mysql.UUIDSet)}
+	var duplicatedMu sync.Mutex
+	scanned, err := s.walkFilesParallel(ctx, files, func(idx int, txn *models.GTIDPosition) error {
+		if fileSets[idx] == nil {
+			fileSets[idx] = &mysql.MysqlGTIDSet{Sets: make(map[string]*mysql.UUIDSet)}
+		}
+		if gtidSetHas(fileSets[idx], txn.ServerUUID, txn.GNO) {
+			duplicatedMu.Lock()
+			defer duplicatedMu.Unlock()
+			return duplicated.Update(txn.GTID)
+		}
+		return fileSets[idx].Update(txn.GTID)
+	})
+	if err != nil {
+		return nil, s.timeoutError(ctx, err, scanned, len(files))
+	}
+
+	// GTIDs of a file already seen in an earlier one
+	seen := &mysql.MysqlGTIDSet{Sets: make(map[string]*mysql.UUIDSet)}
+	for _, fileSet := range fileSets {
+		if fileSet == nil {
+			continue
+		}
+		fresh := fileSet.Clone().(*mysql.MysqlGTIDSet)
+		fresh.Minus(*seen)
+		repeated := fileSet.Clone().(*mysql.MysqlGTIDSet)
+		repeated.Minus(*fresh)
+		duplicated.Add(*repeated)
+		seen.Add(*fresh)
+	}
+	if duplicated.IsEmpty() {
+		return nil, nil
+	}
+
+	// Second pass: every copy of the duplicated GTIDs, by file then position
+	occurrences := make([][]*models.GTIDPosition, len(files))
+	scanned, err = s.walkFilesParallel(ctx, files, func(idx int, txn *models.GTIDPosition) error {
+		if gtidSetHas(duplicated, txn.ServerUUID, txn.GNO) {
+			occurrences[idx] = append(occurrences[idx], txn)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, s.timeoutError(ctx, err, scanned, len(files))
+	}
+
+	var duplicates []*models.DuplicateGTID
+	byGTID := make(map[string]*models.DuplicateGTID)
+	gnos := make(map[*models.DuplicateGTID]uint64)
+	for _, fileOccurrences := range occurrences {
+		for _, txn := range fileOccurrences {
+			duplicate, ok := byGTID[txn.GTID]
+			if !ok {
+				duplicate = &models.DuplicateGTID{GTID: txn.GTID}
+				byGTID[txn.GTID] = duplicate
+				gnos[duplicate] = txn.GNO
+				duplicates = append(duplicates, duplicate)
+			}
+			duplicate.Occurrences = append(duplicate.Occurrences, models.GTIDOccurrence{
+				BinlogFile:     txn.BinlogFile,
+				Position:       txn.Position,
+				CommitPosition: txn.CommitPosition,
+				Timestamp:      txn.Timestamp,
+			})
+		}
+	}
+
+	// By server UUID, then GNO
+	sort.Slice(duplicates, func(i, j int) bool {
+		uuidI, _, _ := strings.Cut(duplicates[i].GTID, ":")
+		uuidJ, _, _ := strings.Cut(duplicates[j].GTID, ":")
+		if uuidI != uuidJ {
+			return uuidI < uuidJ
+		}
+		return gnos[duplicates[i]] < gnos[duplicates[j]]
+	})
+	return duplicates, nil
+}
+
+// walkFilesParallel calls fn with every committed transaction of files, with the
+// index of its file, scanning files with parallel workers. Transactions of a file are
+// passed in order from a single goroutine. It stops at the first error or once ctx is
+// done, returning the number of files scanned to the end.
+func (s *Searcher) walkFilesParallel(ctx context.Context, files []string, fn func(idx int, txn *models.GTIDPosition) error) (int, error) {
+	return s.scanFilesParallel(ctx, files, func(idx int) error {
+		return s.walkTransactions(ctx, files[idx], func(txn *models.GTIDPosition) error {
+			return fn(idx, txn)
+		})
+	})
+}
+
+// scanFilesParallel calls scan with the index of every file from parallel workers,
+// stopping at the first error or once ctx is done. scan should return ctx.Err() when
+// ctx is done mid-file. It returns the number of files scanned to the end.
+func (s *Searcher) scanFilesParallel(ctx context.Context, files []string, scan func(idx int) error) (int, error) {
+	var mu sync.Mutex
+	var firstErr error
+	var scanned int
+
+	workers := max(min(s.config.Parallel, len(files)), 1)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				err := scan(idx)
+
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = fmt.Errorf("error scanning %s: %w", files[idx], err)
+				} else if err == nil {
+					scanned++
+					s.fileScanned()
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for idx := range files {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed || ctx.Err() != nil {
+			break
+		}
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr == nil && ctx.Err() != nil {
+		firstErr = ctx.Err()
+	}
+	return scanned, firstErr
+}
+
+// gtidSetHas reports whether set holds the GTID uuidStr:gno
+func gtidSetHas(set *mysql.MysqlGTIDSet, uuidStr string, gno uint64) bool {
+	uuidSet, ok := set.Sets[uuidStr]
+	if !ok {
+		return false
+	}
+	for _, interval := range uuidSet.Intervals {
+		if int64(gno) >= interval.Start && int64(gno) < interval.Stop {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package searcher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil/binlogtest"
+	"github.com/quyetmv/mysql-gtid-position/models"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	dir := t.TempDir()
+	binlogs := []*testutil.Binlog{
+		{Transactions: testutil.Sequence(testutil.FixtureUUID, 1, 3)},
+		{Transactions: append(testutil.Sequence(testutil.FixtureUUID, 3, 5),
+			testutil.Transaction{UUID: testutil.FixtureUUID, GNO: 5})},
+	}
+	files := binlogtest.WriteBinlogs(t, dir, binlogs)
+
+	for _, parallel := range []int{1, 2} {
+		searcher := NewSearcher(&models.Config{Parallel: parallel})
+		duplicates, err := searcher.FindDuplicates(context.Background(), files)
+		if err != nil {
+			t.Fatalf("FindDuplicates() error = %v", err)
+		}
+
+		want := []struct {
+			gtid  string
+			files []string
+		}{
+			{testutil.FixtureUUID + ":3", []string{files[0], files[1]}},
+			{testutil.FixtureUUID + ":5", []string{files[1], files[1]}},
+		}
+		if len(duplicates) != len(want) {
+			t.Fatalf("Expected %d duplicates, got %d", len(want), len(duplicates))
+		}
+		for i, w := range want {
+			got := duplicates[i]
+			if got.GTID != w.gtid || len(got.Occurrences) != len(w.files) {
+				t.Fatalf("Expected %s twice, got %s %d times", w.gtid, got.GTID, len(got.Occurrences))
+			}
+			for j, file := range w.files {
+				if got.Occurrences[j].BinlogFile != file {
+					t.Errorf("Expected copy %d of %s in %s, got %s", j, w.gtid, file, got.Occurrences[j].BinlogFile)
+				}
+			}
+		}
+		if occ := duplicates[1].Occurrences; occ[0].Position >= occ[1].Position {
+			t.Errorf("Expected copies of a file in position order, got %d then %d", occ[0].Position, occ[1].Position)
+		}
+	}
+
+	searcher := NewSearcher(&models.Config{})
+	duplicates, err := searcher.FindDuplicates(context.Background(), files[:1])
+	if err != nil || len(duplicates) != 0 {
+		t.Errorf("Expected no duplicates in a single clean file, got %d (err %v)", len(duplicates), err)
+	}
+}
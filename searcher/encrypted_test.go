@@ -0,0 +1,54 @@
+package searcher
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
+	"github.com/quyetmv/mysql-gtid-position/models"
+)
+
+func TestEncryptedBinlog(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "mysql-bin.000001")
+
+	// Magic and a zeroed encryption header, then ciphertext
+	content := append(append([]byte{}, encryptedBinlogMagic...), make([]byte, 508)...)
+	content = append(content, []byte("\x8a\x13\x07\xc4 not a plain binlog event")...)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatalf("Failed to write binlog: %v", err)
+	}
+
+	searcher := NewSearcher(&models.Config{})
+	if !isEncryptedBinlog(path) {
+		t.Fatal("Expected the file to be detected as encrypted")
+	}
+
+	var encErr *EncryptedBinlogError
+	if _, err := searcher.ReadPreviousGTIDs(path); !errors.As(err, &encErr) {
+		t.Errorf("ReadPreviousGTIDs() error = %v, want EncryptedBinlogError", err)
+	}
+
+	_, err := Find(context.Background(), &models.Config{
+		BinlogDir:   dir,
+		FilePattern: "mysql-bin.*",
+		TargetGTID:  testutil.FixtureUUID + ":1-10",
+		Parallel:    1,
+		LogFormat:   models.LogFormatText,
+	})
+	if !errors.As(err, &encErr) || encErr.File != path {
+		t.Errorf("Find() error = %v, want EncryptedBinlogError for %s", err, path)
+	}
+
+	// Plain binlogs are not mistaken for encrypted ones
+	files, err := testutil.WriteFixtures(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+	if isEncryptedBinlog(files[0]) {
+		t.Error("Expected a plain binlog not to be detected as encrypted")
+	}
+}
@@ -0,0 +1,65 @@
+package searcher
+
+import (
+	"fmt"
+	"strings"
+
+	gtidparser "github.com/quyetmv/mysql-gtid-position/parser"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+// endGTID returns the server UUID and GNO of Config.EndGTID, ok is false without one
+func (s *Searcher) endGTID() (uuid string, gno uint64, ok bool) {
+	if s.config.EndGTID == "" {
+		return "", 0, false
+	}
+	uuid, gno, err := gtidparser.ExtractGTIDInfo(s.config.EndGTID)
+	if err != nil {
+		return "", 0, false
+	}
+	return strings.ToLower(uuid), gno, true
+}
+
+// trimToEndGTID drops the files that start past Config.EndGTID, those whose
+// PREVIOUS_GTIDS header already holds it. All files are kept when
+// a header cannot be read or with Config.NoSmartStart; the scan still stops in the
+// first transaction past the end.
+func (s *Searcher) trimToEndGTID(files []string) []string {
+	endUUID, endGNO, ok := s.endGTID()
+	if !ok || s.config.NoSmartStart {
+		return files
+	}
+	end, err := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:%d", endUUID, endGNO))
+	if err != nil {
+		return files
+	}
+
+	for i := 1; i < len(files); i++ {
+		previous, err := s.ReadHeaderGTIDs(files[i])
+		if err != nil {
+			if s.verbose {
+				s.logger.Warn("cannot read previous GTIDs, scanning to the last file", "file", files[i], "error", err)
+			}
+			return files
+		}
+		contained, err := headerContains(previous, end)
+		if err != nil {
+			if s.verbose {
+				s.logger.Warn("cannot compare previous GTIDs, scanning to the last file", "file", files[i], "error", err)
+			}
+			return files
+		}
+		if contained {
+			if s.verbose {
+				s.logger.Info("end file chosen from PREVIOUS_GTIDS headers", "file", files[i-1],
+					"reason", "the previous GTIDs of the next file hold the end GTID", "end_gtid", s.config.EndGTID)
+			}
+			s.showHeader(files[i], previous, true, "dropped, starts past the end GTID")
+			s.showUnprobedHeaders(files[i+1:], end, "dropped")
+			return files[:i]
+		}
+		s.showHeader(files[i], previous, false, "kept")
+	}
+	return files
+}
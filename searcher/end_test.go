@@ -0,0 +1,56 @@
+package searcher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+func TestEndGTID(t *testing.T) {
+	files, err := testutil.WriteFixtures(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(testutil.FixtureUUID + ":1-300")
+
+	tests := []struct {
+		name         string
+		endGTID      string
+		noSmartStart bool
+		wantFiles    int
+		wantGNO      uint64
+		wantNext     string
+	}{
+		{name: "no end", wantFiles: 3, wantGNO: 300},
+		{name: "end in second file", endGTID: testutil.FixtureUUID + ":150", wantFiles: 2, wantGNO: 150, wantNext: testutil.FixtureUUID + ":151"},
+		{name: "end of first file", endGTID: testutil.FixtureUUID + ":100", wantFiles: 1, wantGNO: 100},
+		{name: "headers ignored", endGTID: testutil.FixtureUUID + ":150", noSmartStart: true, wantFiles: 3, wantGNO: 150, wantNext: testutil.FixtureUUID + ":151"},
+		{name: "other server", endGTID: "22f7ce9e-7f4c-11ef-8423-3a25d006dfee:5", wantFiles: 3, wantGNO: 300},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searcher := NewSearcher(&models.Config{EndGTID: tt.endGTID, NoSmartStart: tt.noSmartStart, Parallel: 2, Selection: models.SelectionLast})
+
+			trimmed := searcher.trimToEndGTID(files)
+			if len(trimmed) != tt.wantFiles {
+				t.Errorf("trimToEndGTID() kept %d files, want %d", len(trimmed), tt.wantFiles)
+			}
+
+			result, err := searcher.SearchParallel(context.Background(), trimmed, &targetGTID)
+			if err != nil {
+				t.Fatalf("SearchParallel() error = %v", err)
+			}
+			if result == nil || result.GNO != tt.wantGNO {
+				t.Fatalf("Expected GNO %d, got %+v", tt.wantGNO, result)
+			}
+			if tt.wantNext != "" && result.NextGTID != tt.wantNext {
+				t.Errorf("Expected next GTID %s, got %s", tt.wantNext, result.NextGTID)
+			}
+		})
+	}
+}
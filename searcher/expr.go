@@ -0,0 +1,112 @@
+package searcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+	gtidparser "github.com/quyetmv/mysql-gtid-position/parser"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+// SearchExpr returns the first committed transaction after which expr holds, evaluated
+// against the executed set: the PREVIOUS_GTIDS of the file the scan starts in plus the
+// GTIDs committed since. Expressions only become true as transactions are executed,
+// so the start file is the last one whose header does not satisfy expr. It returns nil
+// when expr never holds, and an error when it already holds before the first
+// transaction. As with FindNeighbor the search filters do not apply, except
+// -allowed-uuids, and text dumps are not supported. On Config.Timeout a *TimeoutError
+// is returned.
+func (s *Searcher) SearchExpr(ctx context.Context, files []string, expr gtidparser.GTIDExpr) (*models.GTIDPosition, error) {
+	for _, set := range expr.Sets() {
+		if err := s.CheckAllowedUUIDs(&set); err != nil {
+			return nil, err
+		}
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+	if s.config.Timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, s.config.Timeout)
+		defer cancelTimeout()
+	}
+
+	start, executed, err := s.locateExprFile(files, expr)
+	if err != nil {
+		return nil, err
+	}
+	if expr.Eval(executed) {
+		return nil, fmt.Errorf("%s already holds in the previous GTIDs of %s, before its first transaction", expr, files[start])
+	}
+
+	var match *models.GTIDPosition
+	for i := start; i < len(files) && match == nil; i++ {
+		err := s.walkTransactions(ctx, files[i], func(txn *models.GTIDPosition) error {
+			if !s.uuidAllowed(txn.ServerUUID) {
+				return nil // Not from an allowed server, skipped as if absent
+			}
+			if err := executed.Update(txn.GTID); err != nil {
+				return nil // Skip invalid GTIDs
+			}
+			if expr.Eval(executed) {
+				match = txn
+				return errFoundNeighbor // Stops the walk
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, s.timeoutError(ctx, fmt.Errorf("error scanning %s: %w", files[i], err), i-start, len(files))
+		}
+		s.fileScanned()
+	}
+
+	if match != nil {
+		match.SetAge(time.Now())
+	}
+	return match, nil
+}
+
+// locateExprFile returns the index of the last file whose PREVIOUS_GTIDS do not satisfy
+// expr, with those previous GTIDs. It falls back to the first file when a later file has
+// no header, or with Config.NoSmartStart.
+func (s *Searcher) locateExprFile(files []string, expr gtidparser.GTIDExpr) (int, *mysql.MysqlGTIDSet, error) {
+	last := len(files) - 1
+	if s.config.NoSmartStart {
+		last = 0
+	}
+
+	for i := last; i > 0; i-- {
+		header, err := s.readHeaderGTIDs(files[i])
+		if errors.Is(err, errNoHeaderGTIDs) {
+			if s.verbose {
+				s.logger.Info("file has no PREVIOUS_GTIDS header, scanning from the first file", "file", files[i])
+			}
+			break
+		}
+		if err != nil {
+			return 0, nil, fmt.Errorf("error reading %s: %w", files[i], err)
+		}
+		previous, ok := header.(*mysql.MysqlGTIDSet)
+		if !ok {
+			return 0, nil, fmt.Errorf("%s is a MariaDB binlog, it has no previous GTIDs", files[i])
+		}
+		if !expr.Eval(previous) {
+			if s.verbose {
+				s.logger.Info("start file chosen from PREVIOUS_GTIDS headers", "file", files[i],
+					"reason", "its previous GTIDs do not satisfy the expression, those of later files do", "previous_gtids", previous.String())
+			}
+			return i, previous, nil
+		}
+	}
+
+	// A missing header of the first file only means nothing was executed before it
+	previous, err := s.ReadPreviousGTIDs(files[0])
+	if err != nil {
+		return 0, nil, fmt.Errorf("error reading %s: %w", files[0], err)
+	}
+	return 0, previous, nil
+}
@@ -0,0 +1,72 @@
+package searcher
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
+	"github.com/quyetmv/mysql-gtid-position/models"
+	gtidparser "github.com/quyetmv/mysql-gtid-position/parser"
+)
+
+func TestSearchExpr(t *testing.T) {
+	files, err := testutil.WriteFixtures(t.TempDir()) // FixtureUUID:1-300 over 3 files
+	if err != nil {
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+	u := testutil.FixtureUUID
+
+	tests := []struct {
+		name         string
+		expr         string
+		files        []string
+		noSmartStart bool
+		wantGNO      uint64 // 0 for no match
+		wantFile     string
+		wantScanned  int
+		wantErr      bool
+	}{
+		{name: "contains", expr: "contains(" + u + ":1-150)", files: files, wantGNO: 150, wantFile: "mysql-bin.000002", wantScanned: 1},
+		{name: "contains without headers", expr: "contains(" + u + ":1-150)", files: files, noSmartStart: true, wantGNO: 150, wantFile: "mysql-bin.000002", wantScanned: 2},
+		{name: "contains and any", expr: "contains(" + u + ":1-50) and any(" + u + ":220-230)", files: files, wantGNO: 220, wantFile: "mysql-bin.000003", wantScanned: 1},
+		{name: "or takes the first", expr: "any(" + u + ":250) or contains(" + u + ":1-120)", files: files, wantGNO: 120, wantFile: "mysql-bin.000002", wantScanned: 1},
+		{name: "never holds", expr: "contains(" + u + ":1-301)", files: files, wantScanned: 1},
+		{name: "holds in the first header", expr: "any(" + u + ":5)", files: files[1:], wantErr: true},
+		{name: "not allowed", expr: "any(" + rogueUUID + ":1)", files: files, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := gtidparser.ParseGTIDExpr(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseGTIDExpr() error = %v", err)
+			}
+
+			searcher := NewSearcher(&models.Config{NoSmartStart: tt.noSmartStart, AllowedUUIDs: []string{u}})
+			got, err := searcher.SearchExpr(context.Background(), tt.files, expr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SearchExpr() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if tt.wantGNO == 0 {
+				if got != nil {
+					t.Errorf("Expected no match, got %s", got.GTID)
+				}
+			} else if got == nil {
+				t.Fatal("Expected a match, got nil")
+			} else if got.GNO != tt.wantGNO || filepath.Base(got.BinlogFile) != tt.wantFile {
+				t.Errorf("Expected GNO %d in %s, got GNO %d in %s", tt.wantGNO, tt.wantFile, got.GNO, filepath.Base(got.BinlogFile))
+			} else if !strings.HasSuffix(got.ExecutedSet, ":1-"+got.GTID[len(u)+1:]) {
+				t.Errorf("Expected the executed set to end at the match, got %s", got.ExecutedSet)
+			}
+			if searcher.ScannedFiles() != tt.wantScanned {
+				t.Errorf("Expected %d files scanned, got %d", tt.wantScanned, searcher.ScannedFiles())
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package searcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+	gtidparser "github.com/quyetmv/mysql-gtid-position/parser"
+)
+
+// ListBinlogFiles discovers the binlog files to scan for a config: remote files when
+// SSHSource or S3Source is set, the FilesFrom list as given, BinlogDir alone when it
+// is a file, in index order when IndexFile is set, otherwise by FilePatternRegex or
+// FilePattern, starting from StartFile if given, stopping before BeforeFile if given,
+// keeping the newest Recent of them if set and ending at the file of EndGTID
+func (s *Searcher) ListBinlogFiles() ([]string, error) {
+	var binlogFiles []string
+	var err error
+	if s.store != nil {
+		binlogFiles, err = s.GetBinlogFilesFromStore(context.Background(), s.storeSource())
+	} else if s.config.FilesFrom != "" {
+		binlogFiles, err = s.GetBinlogFilesFromList(s.config.FilesFrom)
+	} else if IsBinlogFile(s.config.BinlogDir) {
+		binlogFiles = []string{s.config.BinlogDir} // Single-file scan
+	} else if s.config.IndexFile != "" {
+		binlogFiles, err = s.GetBinlogFilesFromIndex(s.config.BinlogDir, s.config.IndexFile)
+	} else if s.config.FilePatternRegex != "" {
+		binlogFiles, err = s.GetBinlogFilesByRegex(s.config.BinlogDir, s.config.FilePatternRegex)
+	} else {
+		binlogFiles, err = s.GetBinlogFiles(s.config.BinlogDir, s.config.FilePattern)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(binlogFiles) == 0 {
+		return nil, fmt.Errorf("no binlog files found")
+	}
+
+	// Skip files before start-file
+	start, end := 0, len(binlogFiles)
+	if s.config.StartFile != "" {
+		if start = fileIndex(binlogFiles, s.config.StartFile); start < 0 {
+			return nil, fmt.Errorf("start file '%s' not found in binlog files", s.config.StartFile)
+		}
+	}
+
+	// Skip before-file and the files after it
+	if s.config.BeforeFile != "" {
+		if end = fileIndex(binlogFiles, s.config.BeforeFile); end < 0 {
+			return nil, fmt.Errorf("before file '%s' not found in binlog files", s.config.BeforeFile)
+		}
+		if end <= start {
+			return nil, fmt.Errorf("no binlog files left before file '%s'", s.config.BeforeFile)
+		}
+	}
+
+	// Keep only the newest files
+	if s.config.Recent > 0 && end-start > s.config.Recent {
+		start = end - s.config.Recent
+	}
+
+	return s.trimToEndGTID(binlogFiles[start:end]), nil
+}
+
+// IsBinlogFile reports whether a -dir path is a regular file rather than a directory,
+// which is scanned on its own
+func IsBinlogFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+// fileIndex returns the index of the file named name (a base name or path suffix), or -1
+func fileIndex(files []string, name string) int {
+	for i, file := range files {
+		if strings.HasSuffix(file, name) || filepath.Base(file) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Find locates config.TargetGTID in the binlogs of config.BinlogDir.
+// It is the library entry point: nothing is printed, verbose messages go to stderr
+// through the searcher's logger. A nil position with a nil error means not found.
+func Find(ctx context.Context, config *models.Config) (*models.GTIDPosition, error) {
+	s, err := NewSearcherForSource(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return s.Find(ctx)
+}
+
+// Find is like the package-level Find, using the searcher's config and metrics
+func (s *Searcher) Find(ctx context.Context) (*models.GTIDPosition, error) {
+	config := s.config
+
+	binlogFiles, err := s.ListBinlogFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	targetGTID, err := gtidparser.ParseGTID(config.TargetGTID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GTID format: %v", err)
+	}
+
+	filterUUID := config.FilterUUID
+	if config.FindActiveMaster {
+		filterUUID, err = gtidparser.FindActiveMasterUUID(&targetGTID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find active master: %v", err)
+		}
+	}
+
+	if filterUUID != "" {
+		targetGTID, err = gtidparser.FilterByUUID(&targetGTID, filterUUID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to filter by UUID: %v", err)
+		}
+	}
+
+	if err := s.CheckPurged(binlogFiles, &targetGTID); err != nil {
+		return nil, err
+	}
+
+	return s.SearchParallel(ctx, binlogFiles, &targetGTID)
+}
@@ -0,0 +1,152 @@
+package searcher
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+// GlobalLockFile is the lock file shared by the instances scanning a binlog directory
+const GlobalLockFile = ".binlog-info.lock"
+
+// Backoff between attempts to take a global slot while every slot is busy
+const (
+	globalSlotMinBackoff = 50 * time.Millisecond
+	globalSlotMaxBackoff = 2 * time.Second
+)
+
+// globalSlots caps the binlog files scanned at once by every tool instance sharing a
+// lock file. Slot k is a write lock on byte k of the file, so the locks of other
+// processes are seen through the filesystem, NFS included via its lock manager. POSIX
+// locks do not exclude their own process, so the slots of this one are tracked in held.
+type globalSlots struct {
+	file *os.File // Kept open: closing any descriptor of the file drops its locks
+
+	mu   sync.Mutex
+	held []bool
+}
+
+var (
+	globalSlotsMu    sync.Mutex
+	globalSlotsByDir = make(map[string]*globalSlots)
+)
+
+// OpenGlobalLock prepares the lock file of a binlog directory (or of the directory of
+// a single binlog file) for a cap of limit files scanned at once across instances, and
+// checks the filesystem supports locking. Searchers created with MaxGlobalParallel share
+// it. All instances should use the same limit: the cap is the largest one in use.
+func OpenGlobalLock(dir string, limit int) (string, error) {
+	slots, err := openGlobalSlots(dir, limit)
+	if err != nil {
+		return "", err
+	}
+	return slots.file.Name(), nil
+}
+
+// openGlobalSlots returns the slots of the lock file of dir, opening it once per process
+func openGlobalSlots(dir string, limit int) (*globalSlots, error) {
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	path := filepath.Join(dir, GlobalLockFile)
+
+	globalSlotsMu.Lock()
+	defer globalSlotsMu.Unlock()
+
+	if slots, ok := globalSlotsByDir[path]; ok {
+		if limit > len(slots.held) {
+			slots.mu.Lock()
+			slots.held = append(slots.held, make([]bool, limit-len(slots.held))...)
+			slots.mu.Unlock()
+		}
+		return slots, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open global lock file: %w", err)
+	}
+	file.Chmod(0666) // Instances may run as different users, the umask would lock them out
+
+	// Probe a byte past the slots, so a busy slot does not hide a filesystem without locks
+	if _, err := tryLockSlot(file, int64(limit)); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("cannot lock %s: %w", path, err)
+	}
+	unlockSlot(file, int64(limit))
+
+	slots := &globalSlots{file: file, held: make([]bool, limit)}
+	globalSlotsByDir[path] = slots
+	return slots, nil
+}
+
+// acquire takes a free slot, waiting with a jittered exponential backoff while all are
+// busy so that waiting instances do not retry in lockstep. It gives up with ctx.Err()
+// once ctx is done.
+func (g *globalSlots) acquire(ctx context.Context) (int, error) {
+	backoff := globalSlotMinBackoff
+	for {
+		g.mu.Lock()
+		for slot, held := range g.held {
+			if held {
+				continue
+			}
+			locked, err := tryLockSlot(g.file, int64(slot))
+			if err != nil {
+				g.mu.Unlock()
+				return -1, err
+			}
+			if locked {
+				g.held[slot] = true
+				g.mu.Unlock()
+				return slot, nil
+			}
+		}
+		g.mu.Unlock()
+
+		timer := time.NewTimer(backoff/2 + rand.N(backoff/2))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return -1, ctx.Err()
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, globalSlotMaxBackoff)
+	}
+}
+
+// release frees a slot taken by acquire
+func (g *globalSlots) release(slot int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	unlockSlot(g.file, int64(slot))
+	g.held[slot] = false
+}
+
+// slotParser holds a global slot while a file is parsed
+type slotParser struct {
+	BinlogParser
+	slots *globalSlots
+}
+
+func (p slotParser) ParseFile(name string, offset int64, execution replication.OnEventFunc) error {
+	return p.ParseFileContext(context.Background(), name, offset, execution)
+}
+
+// ParseFileContext is ParseFile, giving up the wait for a slot once ctx is done
+func (p slotParser) ParseFileContext(ctx context.Context, name string, offset int64, execution replication.OnEventFunc) error {
+	slot, err := p.slots.acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to take a global parallelism slot: %w", err)
+	}
+	defer p.slots.release(slot)
+
+	return p.BinlogParser.ParseFile(name, offset, execution)
+}
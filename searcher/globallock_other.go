@@ -0,0 +1,16 @@
+//go:build !unix
+
+package searcher
+
+import (
+	"errors"
+	"os"
+)
+
+// tryLockSlot needs POSIX record locks, -max-global-parallel is only supported on Unix
+func tryLockSlot(file *os.File, slot int64) (bool, error) {
+	return false, errors.New("global parallelism locks are not supported on this platform")
+}
+
+// unlockSlot is a no-op without POSIX record locks
+func unlockSlot(file *os.File, slot int64) {}
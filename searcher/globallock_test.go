@@ -0,0 +1,92 @@
+package searcher
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+func TestGlobalSlots(t *testing.T) {
+	dir := t.TempDir()
+	lockFile, err := OpenGlobalLock(dir, 1)
+	if err != nil {
+		t.Skipf("Global locks unavailable: %v", err)
+	}
+	if lockFile != filepath.Join(dir, GlobalLockFile) {
+		t.Errorf("Expected the lock file in %s, got %s", dir, lockFile)
+	}
+
+	slots, err := openGlobalSlots(dir, 1)
+	if err != nil {
+		t.Fatalf("openGlobalSlots() error = %v", err)
+	}
+	slot, err := slots.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+
+	// The only slot is held, a second worker waits for it
+	acquired := make(chan int)
+	go func() {
+		second, _ := slots.acquire(context.Background())
+		acquired <- second
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("Expected the second worker to wait for the held slot")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	slots.release(slot)
+	select {
+	case second := <-acquired:
+		slots.release(second)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the second worker to take the released slot")
+	}
+}
+
+// TestGlobalSlots_Timeout checks that a search waiting for a slot held elsewhere stops
+// at Config.Timeout
+func TestGlobalSlots_Timeout(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := testutil.WriteFixtures(dir); err != nil { // FixtureUUID:1-300 over 3 files
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+	slots, err := openGlobalSlots(dir, 1)
+	if err != nil {
+		t.Skipf("Global locks unavailable: %v", err)
+	}
+	slot, err := slots.acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire() error = %v", err)
+	}
+	defer slots.release(slot)
+
+	searcher := NewSearcher(&models.Config{BinlogDir: dir, Parallel: 1, MaxGlobalParallel: 1, Timeout: 100 * time.Millisecond})
+	files, err := searcher.GetBinlogFiles(dir, "mysql-bin.*")
+	if err != nil {
+		t.Fatalf("GetBinlogFiles() error = %v", err)
+	}
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(testutil.FixtureUUID + ":150")
+
+	start := time.Now()
+	_, err = searcher.SearchParallel(context.Background(), files, &targetGTID)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("Search did not stop at the deadline, took %v", elapsed)
+	}
+	var timeoutErr *TimeoutError
+	if !errors.As(err, &timeoutErr) {
+		t.Fatalf("Expected TimeoutError, got %v", err)
+	}
+	if timeoutErr.Scanned != 0 {
+		t.Errorf("Expected no file scanned, got %d", timeoutErr.Scanned)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build unix
+
+package searcher
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// tryLockSlot takes the write lock of byte slot of file without waiting, reporting
+// false when another process holds it
+func tryLockSlot(file *os.File, slot int64) (bool, error) {
+	lock := syscall.Flock_t{Type: syscall.F_WRLCK, Whence: 0, Start: slot, Len: 1}
+	err := syscall.FcntlFlock(file.Fd(), syscall.F_SETLK, &lock)
+	if errors.Is(err, syscall.EAGAIN) || errors.Is(err, syscall.EACCES) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// unlockSlot releases the lock of byte slot of file
+func unlockSlot(file *os.File, slot int64) {
+	lock := syscall.Flock_t{Type: syscall.F_UNLCK, Whence: 0, Start: slot, Len: 1}
+	syscall.FcntlFlock(file.Fd(), syscall.F_SETLK, &lock)
+}
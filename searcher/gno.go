@@ -0,0 +1,26 @@
+package searcher
+
+import (
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+// gtidMatches is the match predicate of the searchers: the GTID of a transaction is
+// in the target set, from an allowed server and inside the Config.MinGNO/MaxGNO window.
+// With a window an empty target set matches the GTIDs of any server UUID.
+func (s *Searcher) gtidMatches(targetGTID mysql.GTIDSet, current mysql.GTIDSet, uuidStr string, gno uint64) bool {
+	if !s.uuidAllowed(uuidStr) {
+		return false
+	}
+	if !s.gnoWindowed() {
+		return targetGTID.Contain(current)
+	}
+	if gno < s.config.MinGNO || (s.config.MaxGNO > 0 && gno > s.config.MaxGNO) {
+		return false
+	}
+	return targetGTID.IsEmpty() || targetGTID.Contain(current)
+}
+
+// gnoWindowed reports whether a -min-gno/-max-gno window is set
+func (s *Searcher) gnoWindowed() bool {
+	return s.config.MinGNO > 0 || s.config.MaxGNO > 0
+}
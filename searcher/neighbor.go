@@ -0,0 +1,289 @@
+package searcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/google/uuid"
+)
+
+// FindNeighbor returns the committed transaction immediately before gtid, or after it
+// when after is true, in binlog order across file boundaries. It returns nil when gtid
+// has no such neighbor in files and an error when gtid is not in them.
+// The search filters of the config do not apply; text dumps are not supported.
+// On Config.Timeout a *TimeoutError is returned.
+func (s *Searcher) FindNeighbor(ctx context.Context, files []string, gtid string, after bool) (*models.GTIDPosition, error) {
+	target, err := mysql.ParseMysqlGTIDSet(gtid)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GTID format: %v", err)
+	}
+
+	if err := s.CheckAllowedUUIDs(&target); err != nil {
+		return nil, err
+	}
+
+	if s.config.Timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, s.config.Timeout)
+		defer cancelTimeout()
+	}
+
+	start := s.locateGTIDFile(files, target)
+
+	var neighbor, previous *models.GTIDPosition
+	found := false
+	scanned := 0
+	for i := start; i < len(files) && !(found && (neighbor != nil || !after)); i++ {
+		err := s.walkTransactions(ctx, files[i], func(txn *models.GTIDPosition) error {
+			switch {
+			case !s.uuidAllowed(txn.ServerUUID):
+				return nil // Not from an allowed server, skipped as if absent
+			case found:
+				neighbor = txn // First transaction after the target
+				return errFoundNeighbor
+			case txnContained(target, txn):
+				found = true
+				if !after {
+					neighbor = previous
+					return errFoundNeighbor
+				}
+			default:
+				previous = txn
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, s.timeoutError(ctx, fmt.Errorf("error scanning %s: %w", files[i], err), scanned, len(files))
+		}
+		s.fileScanned()
+		scanned++
+
+		// First transaction of the file holding it: the neighbor ends the file before
+		if found && !after && neighbor == nil && i == start && start > 0 {
+			err := s.walkTransactions(ctx, files[start-1], func(txn *models.GTIDPosition) error {
+				if s.uuidAllowed(txn.ServerUUID) {
+					neighbor = txn
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, s.timeoutError(ctx, fmt.Errorf("error scanning %s: %w", files[start-1], err), scanned, len(files))
+			}
+			s.fileScanned()
+			scanned++
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("GTID %s not found in binlog files", gtid)
+	}
+	if neighbor != nil {
+		neighbor.SetAge(time.Now())
+	}
+	return neighbor, nil
+}
+
+// LastGTID returns the newest committed transaction of files, the head of the binlogs,
+// complete with the executed set up to it. Files without transactions at the end
+// (e.g. just rotated) are skipped. It returns nil when no file holds a transaction.
+// Text dumps are not supported. On Config.Timeout a *TimeoutError is returned.
+func (s *Searcher) LastGTID(ctx context.Context, files []string) (*models.GTIDPosition, error) {
+	if s.config.Timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, s.config.Timeout)
+		defer cancelTimeout()
+	}
+
+	for i := len(files) - 1; i >= 0; i-- {
+		var last *models.GTIDPosition
+		err := s.walkTransactions(ctx, files[i], func(txn *models.GTIDPosition) error {
+			if s.uuidAllowed(txn.ServerUUID) {
+				last = txn
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, s.timeoutError(ctx, fmt.Errorf("error scanning %s: %w", files[i], err), len(files)-1-i, len(files))
+		}
+		s.fileScanned()
+
+		if last != nil {
+			last.SetAge(time.Now())
+			return last, nil
+		}
+	}
+	return nil, nil
+}
+
+// locateGTIDFile returns the index of the file holding the target GTID according to
+// the PREVIOUS_GTIDS headers: the last file whose header does not contain it.
+// It falls back to the first file when a header is missing or cannot be read, or
+// with Config.NoSmartStart.
+func (s *Searcher) locateGTIDFile(files []string, target mysql.GTIDSet) int {
+	if s.config.NoSmartStart {
+		return 0
+	}
+
+	for i := len(files) - 1; i > 0; i-- {
+		previous, err := s.readHeaderGTIDs(files[i])
+		if errors.Is(err, errNoHeaderGTIDs) {
+			// Not "contains nothing": the start cannot be told, scan everything
+			if s.verbose {
+				s.logger.Info("file has no PREVIOUS_GTIDS header, scanning from the first file", "file", files[i])
+			}
+			return 0
+		}
+		if err != nil {
+			if s.verbose {
+				s.logger.Warn("cannot read previous GTIDs, scanning from the first file", "file", files[i], "error", err)
+			}
+			return 0
+		}
+		contained, err := headerContains(previous, target)
+		if err != nil {
+			if s.verbose {
+				s.logger.Warn("cannot compare previous GTIDs, scanning from the first file", "file", files[i], "error", err)
+			}
+			return 0
+		}
+		if !contained {
+			if s.verbose {
+				s.logger.Info("start file chosen from PREVIOUS_GTIDS headers", "file", files[i],
+					"reason", "its previous GTIDs do not contain the GTID, those of later files do", "previous_gtids", previous.String())
+			}
+			s.showHeader(files[i], previous, false, "start file")
+			s.showUnprobedHeaders(files[:i], target, "skipped")
+			return i
+		}
+		s.showHeader(files[i], previous, true, "GTID is in an earlier file")
+	}
+	return 0
+}
+
+// txnContained reports whether the GTID of a transaction is in set
+func txnContained(set mysql.GTIDSet, txn *models.GTIDPosition) bool {
+	current, err := mysql.ParseMysqlGTIDSet(txn.GTID)
+	return err == nil && set.Contain(current)
+}
+
+// walkTransactions calls fn with every committed transaction of a binlog file, in
+// order and complete with its commit and resume positions. An errFoundNeighbor error
+// from fn stops the walk without error; once ctx is done the walk stops with ctx.Err().
+func (s *Searcher) walkTransactions(ctx context.Context, filepath string, fn func(txn *models.GTIDPosition) error) error {
+	if isTextDump(filepath) {
+		return fmt.Errorf("mysqlbinlog text dumps are not supported")
+	}
+
+	executedSet := &mysql.MysqlGTIDSet{Sets: make(map[string]*mysql.UUIDSet)}
+	defer s.observeUUIDs(executedSet)
+
+	var current *models.GTIDPosition // Transaction being read
+	var pending *models.GTIDPosition // Committed, waiting for the next GTID as resume position
+	var currentDatabase, lastGTID string
+	var lastGoodPos, txnTimestamp uint32
+
+	parser := s.parserFactory()
+	err := parseFile(ctx, parser, filepath, 0, func(e *replication.BinlogEvent) error {
+		if err := ctx.Err(); err != nil {
+			return err // Deadline reached or cancelled
+		}
+		prevPos := lastGoodPos
+		lastGoodPos = e.Header.LogPos
+
+		eventTime := e.Header.Timestamp
+		if txnTimestamp > 0 {
+			eventTime = txnTimestamp
+		}
+
+		// finish marks the current transaction committed at the event
+		finish := func() {
+			current.CommitPosition = e.Header.LogPos
+			current.ResumePosition = e.Header.LogPos // Last of the file resumes at its commit
+			current.Timestamp = eventTime
+			current.ExecutedSet = executedSet.String()
+			pending, current = current, nil
+		}
+
+		switch e.Header.EventType {
+		case replication.PREVIOUS_GTIDS_EVENT:
+			previousEvent := e.Event.(*replication.PreviousGTIDsEvent)
+			if previousSet, err := mysql.ParseMysqlGTIDSet(previousEvent.GTIDSets); err == nil {
+				executedSet.Add(*previousSet.(*mysql.MysqlGTIDSet))
+			}
+
+		case replication.GTID_EVENT:
+			gtidEvent := e.Event.(*replication.GTIDEvent)
+			sid, err := uuid.FromBytes(gtidEvent.SID)
+			if err != nil {
+				return nil // Skip invalid GTIDs
+			}
+			executedSet.AddGTID(sid, gtidEvent.GNO)
+			txnTimestamp = commitTimestamp(gtidEvent)
+			gtidStr := fmt.Sprintf("%s:%d", sid, gtidEvent.GNO)
+
+			if pending != nil {
+				pending.NextGTID = gtidStr
+				pending.ResumePosition = e.Header.LogPos // END_LOG_POS of next GTID
+				txn := pending
+				pending = nil
+				if err := fn(txn); err != nil {
+					return err
+				}
+			}
+
+			current = &models.GTIDPosition{
+				BinlogFile:     filepath,
+				Position:       eventStartPosition(e.Header, prevPos),
+				CommitPosition: e.Header.LogPos,
+				ResumePosition: e.Header.LogPos,
+				Timestamp:      e.Header.Timestamp,
+				GTID:           gtidStr,
+				ServerUUID:     sid.String(),
+				GNO:            uint64(gtidEvent.GNO),
+				Database:       currentDatabase,
+				PrevGTID:       lastGTID,
+				LastCommitted:  gtidEvent.LastCommitted,
+				SequenceNumber: gtidEvent.SequenceNumber,
+				CreatedAt:      time.Now(),
+			}
+			lastGTID = gtidStr
+
+		case replication.QUERY_EVENT:
+			queryEvent := e.Event.(*replication.QueryEvent)
+			if len(queryEvent.Schema) > 0 {
+				currentDatabase = string(queryEvent.Schema)
+			}
+		}
+
+		// Transaction end: XID_EVENT or COMMIT by default, see SetCommitDetector
+		if current != nil {
+			if s.isCommit(e) {
+				finish()
+			} else if e.Header.EventType == replication.QUERY_EVENT && isRollbackQuery(string(e.Event.(*replication.QueryEvent).Query)) {
+				current = nil // Nothing committed
+			}
+		}
+		return nil
+	})
+
+	if errors.Is(err, errFoundNeighbor) {
+		return nil
+	}
+	if err != nil && !isTruncatedTail(filepath, lastGoodPos, err) {
+		return err
+	}
+
+	// Last committed transaction of the file
+	if pending != nil {
+		if err := fn(pending); err != nil && !errors.Is(err, errFoundNeighbor) {
+			return err
+		}
+	}
+	return nil
+}
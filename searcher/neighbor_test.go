@@ -0,0 +1,213 @@
+package searcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil/binlogtest"
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+func TestFindNeighbor(t *testing.T) {
+	dir := t.TempDir()
+	files, err := testutil.WriteFixtures(dir) // FixtureUUID:1-300 over 3 files
+	if err != nil {
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		gno          int
+		after        bool
+		noSmartStart bool
+		wantGNO      uint64 // 0 for no neighbor
+		wantFile     string
+		wantErr      bool
+	}{
+		{name: "before in file", gno: 150, wantGNO: 149, wantFile: "mysql-bin.000002"},
+		{name: "before across files", gno: 101, wantGNO: 100, wantFile: "mysql-bin.000001"},
+		{name: "before across files without headers", gno: 101, noSmartStart: true, wantGNO: 100, wantFile: "mysql-bin.000001"},
+		{name: "after in file", gno: 150, after: true, wantGNO: 151, wantFile: "mysql-bin.000002"},
+		{name: "after across files", gno: 200, after: true, wantGNO: 201, wantFile: "mysql-bin.000003"},
+		{name: "before first", gno: 1},
+		{name: "after last", gno: 300, after: true},
+		{name: "not found", gno: 400, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gtid := fmt.Sprintf("%s:%d", testutil.FixtureUUID, tt.gno)
+			got, err := NewSearcher(&models.Config{NoSmartStart: tt.noSmartStart}).FindNeighbor(context.Background(), files, gtid, tt.after)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FindNeighbor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if tt.wantGNO == 0 {
+				if got != nil {
+					t.Errorf("Expected no neighbor, got %s", got.GTID)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("Expected neighbor, got nil")
+			}
+			if got.GNO != tt.wantGNO || filepath.Base(got.BinlogFile) != tt.wantFile {
+				t.Errorf("Expected GNO %d in %s, got GNO %d in %s", tt.wantGNO, tt.wantFile, got.GNO, filepath.Base(got.BinlogFile))
+			}
+			if got.CommitPosition != got.Position+testutil.TransactionSize {
+				t.Errorf("Expected commit position %d, got %d", got.Position+testutil.TransactionSize, got.CommitPosition)
+			}
+		})
+	}
+}
+
+func TestFindNeighbor_MixedUUIDs(t *testing.T) {
+	const otherUUID = "22f7ce9e-7f4c-11ef-8423-3a25d006dfee"
+	path := filepath.Join(t.TempDir(), "mysql-bin.000001")
+	binlog := &testutil.Binlog{Transactions: []testutil.Transaction{
+		{UUID: testutil.FixtureUUID, GNO: 1},
+		{UUID: otherUUID, GNO: 7},
+		{UUID: testutil.FixtureUUID, GNO: 2},
+	}}
+	if err := binlog.WriteFile(path); err != nil {
+		t.Fatalf("Failed to write binlog: %v", err)
+	}
+
+	searcher := NewSearcher(&models.Config{})
+	got, err := searcher.FindNeighbor(context.Background(), []string{path}, testutil.FixtureUUID+":1", true)
+	if err != nil {
+		t.Fatalf("FindNeighbor() error = %v", err)
+	}
+	if got == nil || got.GTID != otherUUID+":7" {
+		t.Fatalf("Expected %s:7, got %+v", otherUUID, got)
+	}
+	if got.PrevGTID != testutil.FixtureUUID+":1" || got.NextGTID != testutil.FixtureUUID+":2" {
+		t.Errorf("Expected previous %s:1 and next %s:2, got %q and %q", testutil.FixtureUUID, testutil.FixtureUUID, got.PrevGTID, got.NextGTID)
+	}
+	if got.ResumePosition != got.CommitPosition+testutil.GTIDEventSize {
+		t.Errorf("Expected resume position after the next GTID event, got %d", got.ResumePosition)
+	}
+
+	got, err = searcher.FindNeighbor(context.Background(), []string{path}, testutil.FixtureUUID+":2", false)
+	if err != nil || got == nil || got.GTID != otherUUID+":7" {
+		t.Errorf("Expected %s:7 before %s:2, got %+v (error %v)", otherUUID, testutil.FixtureUUID, got, err)
+	}
+}
+
+func TestLastGTID(t *testing.T) {
+	dir := t.TempDir()
+	files, err := testutil.WriteFixtures(dir) // FixtureUUID:1-300 over 3 files
+	if err != nil {
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+
+	// A just rotated binlog without transactions
+	empty := filepath.Join(dir, "mysql-bin.000004")
+	if err := (&testutil.Binlog{}).WriteFile(empty); err != nil {
+		t.Fatalf("Failed to write binlog: %v", err)
+	}
+
+	searcher := NewSearcher(&models.Config{})
+	got, err := searcher.LastGTID(context.Background(), append(files, empty))
+	if err != nil {
+		t.Fatalf("LastGTID() error = %v", err)
+	}
+	if got == nil {
+		t.Fatal("Expected last transaction, got nil")
+	}
+	if got.GNO != 300 || filepath.Base(got.BinlogFile) != "mysql-bin.000003" {
+		t.Errorf("Expected GNO 300 in mysql-bin.000003, got GNO %d in %s", got.GNO, filepath.Base(got.BinlogFile))
+	}
+	if want := testutil.FixtureUUID + ":1-300"; got.ExecutedSet != want {
+		t.Errorf("Expected executed set %s, got %s", want, got.ExecutedSet)
+	}
+	if got.ResumePosition != got.CommitPosition {
+		t.Errorf("Expected the tail to resume at its commit %d, got %d", got.CommitPosition, got.ResumePosition)
+	}
+
+	got, err = searcher.LastGTID(context.Background(), []string{empty})
+	if err != nil || got != nil {
+		t.Errorf("LastGTID() of an empty binlog = %v, %v, want nil", got, err)
+	}
+}
+
+func TestLocateGTIDFile_ShowSkipped(t *testing.T) {
+	dir := t.TempDir()
+	files, err := testutil.WriteFixtures(dir) // FixtureUUID:1-300 over 3 files
+	if err != nil {
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+	target, _ := mysql.ParseMysqlGTIDSet(testutil.FixtureUUID + ":150")
+
+	tests := []struct {
+		mode      string
+		wantLines int
+	}{
+		{mode: "", wantLines: 0},
+		{mode: models.ShowSkippedProbed, wantLines: 2}, // Headers of files 3 and 2
+		{mode: models.ShowSkippedFull, wantLines: 3},   // And the skipped file 1
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			var buf bytes.Buffer
+			searcher := NewSearcher(&models.Config{ShowSkipped: tt.mode})
+			searcher.logger = NewLogger(&buf, models.LogFormatText)
+
+			if start := searcher.locateGTIDFile(files, target); start != 1 {
+				t.Errorf("Expected start file 1, got %d", start)
+			}
+			if lines := strings.Count(buf.String(), "smart selection header"); lines != tt.wantLines {
+				t.Errorf("Expected %d header lines, got %d:\n%s", tt.wantLines, lines, buf.String())
+			}
+			if tt.mode == models.ShowSkippedFull && !strings.Contains(buf.String(), "mysql-bin.000001 previous_gtids=\"\" contains_target=false decision=skipped") {
+				t.Errorf("Expected the skipped first file, got:\n%s", buf.String())
+			}
+		})
+	}
+}
+
+func TestLocateGTIDFile_MissingHeader(t *testing.T) {
+	dir := t.TempDir()
+	// The second file has no PREVIOUS_GTIDS event (written before 5.6, or truncated):
+	// its empty header does not contain :50, yet :50 is in the first file
+	binlogs := []*testutil.Binlog{
+		{Transactions: testutil.Sequence(testutil.FixtureUUID, 1, 100)},
+		{Transactions: testutil.Sequence(testutil.FixtureUUID, 101, 200)},
+		{PreviousGTIDs: testutil.FixtureUUID + ":1-200", Transactions: testutil.Sequence(testutil.FixtureUUID, 201, 300)},
+	}
+	files := binlogtest.WriteBinlogs(t, dir, binlogs)
+	target, _ := mysql.ParseMysqlGTIDSet(testutil.FixtureUUID + ":50")
+
+	var buf bytes.Buffer
+	searcher := NewSearcher(&models.Config{Verbose: true})
+	searcher.logger = NewLogger(&buf, models.LogFormatText)
+
+	if start := searcher.locateGTIDFile(files, target); start != 0 {
+		t.Errorf("Expected the first file, got %d", start)
+	}
+	if !strings.Contains(buf.String(), "file has no PREVIOUS_GTIDS header") || !strings.Contains(buf.String(), "mysql-bin.000002") {
+		t.Errorf("Expected a note about the missing header, got:\n%s", buf.String())
+	}
+
+	got, err := searcher.FindNeighbor(context.Background(), files, testutil.FixtureUUID+":50", true)
+	if err != nil {
+		t.Fatalf("FindNeighbor() error = %v", err)
+	}
+	if got == nil || got.GNO != 51 {
+		t.Errorf("Expected GNO 51, got %v", got)
+	}
+
+	// ReadHeaderGTIDs still reports the missing header as an empty set
+	header, err := searcher.ReadHeaderGTIDs(files[1])
+	if err != nil || !header.IsEmpty() {
+		t.Errorf("ReadHeaderGTIDs() = %v, %v, want an empty set", header, err)
+	}
+}
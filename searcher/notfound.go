@@ -0,0 +1,60 @@
+package searcher
+
+import (
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+// NotFoundReason explains, from the GTIDs of the files scanned so far, why a search of
+// the target set found no match. It is only meaningful after a complete search without
+// a match; files skipped by the smart start count through the headers of later files.
+func (s *Searcher) NotFoundReason(targetGTID *mysql.GTIDSet) models.NotFoundReason {
+	target, ok := (*targetGTID).(*mysql.MysqlGTIDSet)
+	if !ok {
+		return models.NotFoundAbsent
+	}
+	if target.IsEmpty() {
+		return models.NotFoundFiltered // Only a -min-gno/-max-gno window searches without a target
+	}
+
+	s.seenMu.Lock()
+	defer s.seenMu.Unlock()
+
+	seenAny := false
+	for uuidStr := range target.Sets {
+		seenAny = seenAny || s.seenUUIDs[uuidStr]
+	}
+	if !seenAny {
+		return models.NotFoundUnseenUUID
+	}
+
+	// Executed GTIDs are either matched by a transaction or only known from the headers
+	remaining := target.Clone().(*mysql.MysqlGTIDSet)
+	remaining.Minus(*s.seenGTIDs)
+	if !remaining.Equal(target) {
+		if s.scanFiltered() {
+			return models.NotFoundFiltered
+		}
+		return models.NotFoundPurged
+	}
+
+	// Every target GTID of a seen server is past the last GNO it wrote
+	for uuidStr, set := range target.Sets {
+		seen, ok := s.seenGTIDs.Sets[uuidStr]
+		if !ok || len(set.Intervals) == 0 || len(seen.Intervals) == 0 {
+			continue
+		}
+		if set.Intervals[0].Start < seen.Intervals[len(seen.Intervals)-1].Stop {
+			return models.NotFoundAbsent
+		}
+	}
+	return models.NotFoundFuture
+}
+
+// scanFiltered reports whether a filter may drop transactions of the target set
+func (s *Searcher) scanFiltered() bool {
+	return !s.config.StartTime.IsZero() || !s.config.EndTime.IsZero() ||
+		s.config.FilterDatabase != "" || s.config.FilterTable != "" ||
+		len(s.config.AllowedUUIDs) > 0 || s.gnoWindowed()
+}
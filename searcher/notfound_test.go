@@ -0,0 +1,44 @@
+package searcher
+
+import (
+	"testing"
+	"time"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+func TestNotFoundReason(t *testing.T) {
+	uuidA := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	uuidB := "4e11fa47-71ca-11e1-9e33-c80aa9429562"
+
+	tests := []struct {
+		name   string
+		config *models.Config
+		target string
+		want   models.NotFoundReason
+	}{
+		{name: "unseen uuid", config: &models.Config{}, target: uuidB + ":5", want: models.NotFoundUnseenUUID},
+		{name: "purged", config: &models.Config{}, target: uuidA + ":5", want: models.NotFoundPurged},
+		{name: "filtered", config: &models.Config{StartTime: time.Now()}, target: uuidA + ":150", want: models.NotFoundFiltered},
+		{name: "future", config: &models.Config{}, target: uuidA + ":301-400", want: models.NotFoundFuture},
+		{name: "gap", config: &models.Config{}, target: uuidA + ":250", want: models.NotFoundAbsent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searcher := &Searcher{config: tt.config}
+			seen, _ := mysql.ParseMysqlGTIDSet(uuidA + ":1-200:260-300")
+			searcher.observeUUIDs(seen.(*mysql.MysqlGTIDSet))
+
+			target, err := mysql.ParseMysqlGTIDSet(tt.target)
+			if err != nil {
+				t.Fatalf("Invalid target: %v", err)
+			}
+			if got := searcher.NotFoundReason(&target); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
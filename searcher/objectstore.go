@@ -0,0 +1,174 @@
+package searcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+// ObjectStore lists and reads binlog objects from remote storage such as S3 or SFTP
+type ObjectStore interface {
+	// List returns the keys of all objects in bucket starting with prefix
+	List(ctx context.Context, bucket, prefix string) ([]string, error)
+	// Open streams the content of an object
+	Open(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+}
+
+// ParseS3URL splits "s3://bucket/prefix/mysql-bin.*" into bucket, key prefix
+// directory ("prefix/") and file pattern ("mysql-bin.*")
+func ParseS3URL(rawURL string) (bucket, dir, pattern string, err error) {
+	return parseObjectURL(rawURL, "s3", "s3://bucket/prefix/pattern")
+}
+
+// ParseSSHURL splits "ssh://user@host/path/mysql-bin.*" into the host as the bucket
+// ("user@host", with the port if any), the directory without its leading slash
+// ("path/") and file pattern ("mysql-bin.*")
+func ParseSSHURL(rawURL string) (host, dir, pattern string, err error) {
+	return parseObjectURL(rawURL, "ssh", "ssh://user@host/path/pattern")
+}
+
+// parseObjectURL splits an object source URL of a scheme, see ParseS3URL
+func parseObjectURL(rawURL, scheme, form string) (bucket, dir, pattern string, err error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid %s URL: %w", strings.ToUpper(scheme), err)
+	}
+	if u.Scheme != scheme || u.Host == "" {
+		return "", "", "", fmt.Errorf("invalid %s URL %q: must be %s", strings.ToUpper(scheme), rawURL, form)
+	}
+
+	dir, pattern = path.Split(strings.TrimPrefix(u.Path, "/"))
+	if pattern == "" {
+		return "", "", "", fmt.Errorf("invalid %s URL %q: missing file pattern", strings.ToUpper(scheme), rawURL)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return "", "", "", fmt.Errorf("invalid %s file pattern %q: %w", strings.ToUpper(scheme), pattern, err)
+	}
+
+	return objectBucket(u), dir, pattern, nil
+}
+
+// objectBucket is the bucket of an object URL: its host, with the user of ssh:// URLs
+func objectBucket(u *url.URL) string {
+	if u.User != nil {
+		return u.User.Username() + "@" + u.Host
+	}
+	return u.Host
+}
+
+// NewObjectStoreSearcher creates a Searcher whose binlogs are objects of store.
+// Files are named by their "s3://bucket/key" or "ssh://user@host/key" URL.
+func NewObjectStoreSearcher(config *models.Config, store ObjectStore) *Searcher {
+	s := NewSearcherWithParser(config, func() BinlogParser {
+		return &objectParser{store: store}
+	})
+	s.store = store
+	return s
+}
+
+// NewSearcherForSource creates a Searcher for the binlog source of config: files
+// read over SFTP when SSHSource is set, S3 objects when S3Source is set, the local
+// BinlogDir otherwise
+func NewSearcherForSource(ctx context.Context, config *models.Config) (*Searcher, error) {
+	var store ObjectStore
+	var err error
+	switch {
+	case config.SSHSource != "":
+		store, err = newSSHStore(ctx, config.SSHSource)
+	case config.S3Source != "":
+		store, err = newS3Store(ctx)
+	default:
+		return NewSearcher(config), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return NewObjectStoreSearcher(config, store), nil
+}
+
+// storeSource is the URL of the remote binlog source of the config, if any
+func (s *Searcher) storeSource() string {
+	if s.config.SSHSource != "" {
+		return s.config.SSHSource
+	}
+	return s.config.S3Source
+}
+
+// GetBinlogFilesFromStore lists the objects matching an "s3://bucket/prefix/pattern"
+// or "ssh://user@host/path/pattern" source, sorted by key and named by their URL
+func (s *Searcher) GetBinlogFilesFromStore(ctx context.Context, source string) ([]string, error) {
+	scheme, parse := "s3", ParseS3URL
+	if strings.HasPrefix(source, "ssh://") {
+		scheme, parse = "ssh", ParseSSHURL
+	}
+	bucket, dir, pattern, err := parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := s.store.List(ctx, bucket, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+
+	var binlogs []string
+	for _, key := range keys {
+		name := strings.TrimPrefix(key, dir)
+		if strings.Contains(name, "/") || strings.HasSuffix(name, ".index") {
+			continue // Nested "directories" and index files
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			binlogs = append(binlogs, scheme+"://"+bucket+"/"+key)
+		}
+	}
+
+	sort.Strings(binlogs)
+	return binlogs, nil
+}
+
+// objectParser is a BinlogParser streaming binlogs from an ObjectStore,
+// the files it is given being "s3://bucket/key" or "ssh://user@host/key" URLs
+type objectParser struct {
+	store ObjectStore
+}
+
+// ParseFile streams the object named by an object URL through the binlog parser.
+// Objects are read sequentially, so only offset 0 (the whole binlog) is supported.
+func (p *objectParser) ParseFile(name string, offset int64, execution replication.OnEventFunc) error {
+	if offset > 0 {
+		return fmt.Errorf("offset %d not supported for object store binlogs", offset)
+	}
+
+	u, err := url.Parse(name)
+	if err != nil || (u.Scheme != "s3" && u.Scheme != "ssh") {
+		return fmt.Errorf("invalid object URL: %s", name)
+	}
+
+	body, err := p.store.Open(context.Background(), objectBucket(u), strings.TrimPrefix(u.Path, "/"))
+	if err != nil {
+		return fmt.Errorf("failed to open object: %w", err)
+	}
+	defer body.Close()
+
+	// Same header check as ParseFile on a local binlog
+	magic := make([]byte, len(replication.BinLogFileHeader))
+	if _, err := io.ReadFull(body, magic); err != nil {
+		return fmt.Errorf("failed to read binlog header: %w", err)
+	}
+	if !bytes.Equal(magic, replication.BinLogFileHeader) {
+		return fmt.Errorf("%s is not a valid binlog file, head 4 bytes must fe'bin'", name)
+	}
+
+	parser := replication.NewBinlogParser()
+	parser.SetVerifyChecksum(true)
+	return parser.ParseReader(body, execution)
+}
@@ -0,0 +1,160 @@
+package searcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+// memStore is an in-memory ObjectStore keyed by "bucket/key"
+type memStore struct {
+	objects map[string][]byte
+}
+
+func (m *memStore) List(ctx context.Context, bucket, prefix string) ([]string, error) {
+	var keys []string
+	for name := range m.objects {
+		if key, ok := strings.CutPrefix(name, bucket+"/"); ok && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (m *memStore) Open(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	data, ok := m.objects[bucket+"/"+key]
+	if !ok {
+		return nil, fmt.Errorf("no such key: %s", key)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func TestParseS3URL(t *testing.T) {
+	tests := []struct {
+		url         string
+		wantBucket  string
+		wantDir     string
+		wantPattern string
+		wantErr     bool
+	}{
+		{url: "s3://backups/mysql/prod/mysql-bin.*", wantBucket: "backups", wantDir: "mysql/prod/", wantPattern: "mysql-bin.*"},
+		{url: "s3://backups/mysql-bin.*", wantBucket: "backups", wantDir: "", wantPattern: "mysql-bin.*"},
+		{url: "s3://backups/mysql/", wantErr: true},
+		{url: "/data/mysql-bin.*", wantErr: true},
+		{url: "s3://backups/mysql-bin.[", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			bucket, dir, pattern, err := ParseS3URL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseS3URL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if bucket != tt.wantBucket || dir != tt.wantDir || pattern != tt.wantPattern {
+				t.Errorf("ParseS3URL() = (%q, %q, %q), want (%q, %q, %q)",
+					bucket, dir, pattern, tt.wantBucket, tt.wantDir, tt.wantPattern)
+			}
+		})
+	}
+}
+
+func TestParseSSHURL(t *testing.T) {
+	tests := []struct {
+		url         string
+		wantHost    string
+		wantDir     string
+		wantPattern string
+		wantErr     bool
+	}{
+		{url: "ssh://dba@archive01/var/lib/mysql/mysql-bin.*", wantHost: "dba@archive01", wantDir: "var/lib/mysql/", wantPattern: "mysql-bin.*"},
+		{url: "ssh://archive01:2222/mysql-bin.*", wantHost: "archive01:2222", wantDir: "", wantPattern: "mysql-bin.*"},
+		{url: "ssh://dba@archive01/var/lib/mysql/", wantErr: true},
+		{url: "s3://backups/mysql-bin.*", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			host, dir, pattern, err := ParseSSHURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSSHURL() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if host != tt.wantHost || dir != tt.wantDir || pattern != tt.wantPattern {
+				t.Errorf("ParseSSHURL() = (%q, %q, %q), want (%q, %q, %q)",
+					host, dir, pattern, tt.wantHost, tt.wantDir, tt.wantPattern)
+			}
+		})
+	}
+}
+
+func TestGetBinlogFilesFromStore(t *testing.T) {
+	store := &memStore{objects: map[string][]byte{
+		"backups/prod/mysql-bin.000002":     nil,
+		"backups/prod/mysql-bin.000001":     nil,
+		"backups/prod/mysql-bin.index":      nil,
+		"backups/prod/relay-bin.000001":     nil,
+		"backups/prod/old/mysql-bin.000001": nil,
+		"other/prod/mysql-bin.000003":       nil,
+	}}
+
+	searcher := NewObjectStoreSearcher(&models.Config{S3Source: "s3://backups/prod/mysql-bin.*"}, store)
+
+	files, err := searcher.ListBinlogFiles()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	want := []string{"s3://backups/prod/mysql-bin.000001", "s3://backups/prod/mysql-bin.000002"}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Errorf("ListBinlogFiles() = %v, want %v", files, want)
+	}
+}
+
+func TestGetBinlogFilesFromStore_SSH(t *testing.T) {
+	store := &memStore{objects: map[string][]byte{
+		"dba@archive01/var/lib/mysql/mysql-bin.000001": replication.BinLogFileHeader,
+		"dba@archive01/var/lib/mysql/mysql-bin.index":  nil,
+	}}
+
+	searcher := NewObjectStoreSearcher(&models.Config{SSHSource: "ssh://dba@archive01/var/lib/mysql/mysql-bin.*"}, store)
+
+	files, err := searcher.ListBinlogFiles()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := "ssh://dba@archive01/var/lib/mysql/mysql-bin.000001"
+	if len(files) != 1 || files[0] != want {
+		t.Fatalf("ListBinlogFiles() = %v, want [%s]", files, want)
+	}
+
+	// Files are opened on the host of their URL, user included
+	parser := &objectParser{store: store}
+	if err := parser.ParseFile(files[0], 0, func(e *replication.BinlogEvent) error { return nil }); err != nil {
+		t.Errorf("Expected binlog to parse, got %v", err)
+	}
+}
+
+func TestObjectParser_ParseFile(t *testing.T) {
+	store := &memStore{objects: map[string][]byte{
+		"backups/empty.000001": replication.BinLogFileHeader, // Header only, no events
+		"backups/bad.000001":   []byte("not a binlog"),
+	}}
+	parser := &objectParser{store: store}
+	noop := func(e *replication.BinlogEvent) error { return nil }
+
+	if err := parser.ParseFile("s3://backups/empty.000001", 0, noop); err != nil {
+		t.Errorf("Expected empty binlog to parse, got %v", err)
+	}
+	if err := parser.ParseFile("s3://backups/bad.000001", 0, noop); err == nil {
+		t.Error("Expected error for invalid binlog header, got nil")
+	}
+	if err := parser.ParseFile("s3://backups/missing.000001", 0, noop); err == nil {
+		t.Error("Expected error for missing object, got nil")
+	}
+}
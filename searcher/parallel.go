@@ -0,0 +1,70 @@
+package searcher
+
+import (
+	"os"
+	"runtime"
+	"sort"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+)
+
+// Storage hints for AutoParallel
+const (
+	StorageAuto = "auto" // Detect network filesystems, assume SSD-like storage otherwise
+	StorageSSD  = "ssd"
+	StorageHDD  = "hdd"
+	StorageNFS  = "nfs"
+)
+
+// maxAutoParallel caps the workers picked for fast storage: scanning is CPU-bound
+// parsing, and more workers than cores only adds scheduling and memory.
+const maxAutoParallel = 16
+
+// AutoParallel picks a worker count for scanning binlogs in dir on the given storage.
+//
+// Each worker reads one file sequentially and parses it, so on SSD/NVMe storage the
+// scan is CPU-bound and one worker per core is best. On spinning disks concurrent
+// sequential reads turn into seeks, and on network filesystems they compete for the
+// same link and server, so both are capped at 2 workers. Rotational disks cannot be
+// detected reliably and need the "hdd" hint.
+func AutoParallel(storage, dir string) int {
+	if storage == StorageAuto && dir != "" && isNetworkFilesystem(dir) {
+		storage = StorageNFS
+	}
+
+	switch storage {
+	case StorageHDD, StorageNFS:
+		return 2
+	default:
+		return min(runtime.NumCPU(), maxAutoParallel)
+	}
+}
+
+// scanOrder returns the indexes of files in the order they are handed to the workers.
+//
+// Workers take the next file as they finish one, so a huge file picked up last leaves
+// the others idle until it is done. With several workers and highest-gno selection the
+// largest files go first (longest processing time first), the small ones then fill the
+// gaps. First/last selection keeps file order: it stops files that can no longer win,
+// which only pays off scanning from the winning end. Files that cannot be stat'ed, such
+// as remote objects, count as empty; ties keep file order.
+func (s *Searcher) scanOrder(files []string, workers int) []int {
+	order := make([]int, len(files))
+	for i := range order {
+		order[i] = i
+	}
+	if workers < 2 || s.store != nil || (s.config.Selection != "" && s.config.Selection != models.SelectionHighestGNO) {
+		return order
+	}
+
+	sizes := make([]int64, len(files))
+	for i, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			sizes[i] = info.Size()
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return sizes[order[a]] > sizes[order[b]]
+	})
+	return order
+}
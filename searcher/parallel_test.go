@@ -0,0 +1,67 @@
+package searcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+)
+
+func TestAutoParallel(t *testing.T) {
+	fast := min(runtime.NumCPU(), maxAutoParallel)
+
+	tests := []struct {
+		storage string
+		dir     string
+		want    int
+	}{
+		{storage: StorageSSD, want: fast},
+		{storage: StorageHDD, want: 2},
+		{storage: StorageNFS, want: 2},
+		{storage: StorageAuto, dir: t.TempDir(), want: fast}, // Local temp directory
+		{storage: StorageAuto, want: fast},                   // S3 source, no directory
+	}
+
+	for _, tt := range tests {
+		if got := AutoParallel(tt.storage, tt.dir); got != tt.want {
+			t.Errorf("AutoParallel(%q, %q) = %d, want %d", tt.storage, tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestScanOrder(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for i, size := range []int{10, 300, 0, 300, 50} {
+		file := filepath.Join(dir, fmt.Sprintf("mysql-bin.%06d", i+1))
+		if err := os.WriteFile(file, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, file)
+	}
+	files = append(files, filepath.Join(dir, "missing")) // Cannot be stat'ed, counts as empty
+
+	tests := []struct {
+		name      string
+		selection models.Selection
+		workers   int
+		want      []int
+	}{
+		{name: "largest first", workers: 4, want: []int{1, 3, 4, 0, 2, 5}},
+		{name: "single worker", workers: 1, want: []int{0, 1, 2, 3, 4, 5}},
+		{name: "first selection", selection: models.SelectionFirst, workers: 4, want: []int{0, 1, 2, 3, 4, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searcher := &Searcher{config: &models.Config{Selection: tt.selection}}
+			if got := searcher.scanOrder(files, tt.workers); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
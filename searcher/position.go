@@ -0,0 +1,47 @@
+package searcher
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+)
+
+// GTIDAtPosition is the inverse of a search: it returns the GTID set executed up to pos
+// of a binlog file, the PREVIOUS_GTIDS header plus every transaction committed at or
+// before pos, and the GTID of the last such transaction. lastGTID is empty when no
+// transaction of the file committed by pos. Text dumps are not supported. On
+// Config.Timeout only a *TimeoutError is returned.
+func (s *Searcher) GTIDAtPosition(ctx context.Context, file string, pos uint32) (executedSet, lastGTID string, err error) {
+	if info, err := os.Stat(file); err == nil && int64(pos) > info.Size() {
+		return "", "", fmt.Errorf("position %d is past the end of the file (%d bytes)", pos, info.Size())
+	}
+	if s.config.Timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, s.config.Timeout)
+		defer cancelTimeout()
+	}
+
+	var last *models.GTIDPosition
+	err = s.walkTransactions(ctx, file, func(txn *models.GTIDPosition) error {
+		if txn.CommitPosition > pos {
+			return errFoundNeighbor // Commits past pos, as does everything after it
+		}
+		last = txn
+		return nil
+	})
+	if err != nil {
+		return "", "", s.timeoutError(ctx, fmt.Errorf("error scanning %s: %w", file, err), 0, 1)
+	}
+
+	if last != nil {
+		return last.ExecutedSet, last.GTID, nil
+	}
+
+	header, err := s.ReadHeaderGTIDs(file)
+	if err != nil {
+		return "", "", err
+	}
+	return header.String(), "", nil
+}
@@ -0,0 +1,66 @@
+package searcher
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
+	"github.com/quyetmv/mysql-gtid-position/models"
+)
+
+func TestGTIDAtPosition(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mysql-bin.000002")
+	binlog := &testutil.Binlog{
+		PreviousGTIDs: testutil.FixtureUUID + ":1-100",
+		Transactions:  testutil.Sequence(testutil.FixtureUUID, 101, 103),
+	}
+	if err := binlog.WriteFile(path); err != nil {
+		t.Fatalf("Failed to write binlog: %v", err)
+	}
+
+	searcher := NewSearcher(&models.Config{})
+	var txns []*models.GTIDPosition
+	if err := searcher.walkTransactions(context.Background(), path, func(txn *models.GTIDPosition) error {
+		txns = append(txns, txn)
+		return nil
+	}); err != nil || len(txns) != 3 {
+		t.Fatalf("Expected 3 transactions, got %d (err %v)", len(txns), err)
+	}
+
+	tests := []struct {
+		name         string
+		pos          uint32
+		wantExecuted string
+		wantLast     string
+	}{
+		{name: "before any transaction", pos: 4, wantExecuted: testutil.FixtureUUID + ":1-100"},
+		{name: "at a commit", pos: txns[1].CommitPosition, wantExecuted: testutil.FixtureUUID + ":1-102", wantLast: testutil.FixtureUUID + ":102"},
+		{name: "inside a transaction", pos: txns[2].Position + 1, wantExecuted: testutil.FixtureUUID + ":1-102", wantLast: testutil.FixtureUUID + ":102"},
+		{name: "end of file", pos: txns[2].CommitPosition, wantExecuted: testutil.FixtureUUID + ":1-103", wantLast: testutil.FixtureUUID + ":103"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			executed, last, err := searcher.GTIDAtPosition(context.Background(), path, tt.pos)
+			if err != nil {
+				t.Fatalf("GTIDAtPosition() error = %v", err)
+			}
+			if executed != tt.wantExecuted || last != tt.wantLast {
+				t.Errorf("Expected %s (last %q), got %s (last %q)", tt.wantExecuted, tt.wantLast, executed, last)
+			}
+		})
+	}
+
+	if _, _, err := searcher.GTIDAtPosition(context.Background(), path, txns[2].CommitPosition+1); err == nil {
+		t.Error("Expected an error for a position past the end of the file")
+	}
+
+	var timeoutErr *TimeoutError
+	timed := NewSearcher(&models.Config{Timeout: time.Nanosecond})
+	if _, _, err := timed.GTIDAtPosition(context.Background(), path, txns[2].CommitPosition); !errors.As(err, &timeoutErr) {
+		t.Errorf("Expected a TimeoutError with -timeout, got %v", err)
+	}
+}
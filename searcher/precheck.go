@@ -0,0 +1,156 @@
+package searcher
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+// Kinds of FileIssue
+const (
+	IssueUnreadable      = "unreadable"       // Cannot be opened or parsed up to its format description
+	IssueNotBinlog       = "not-binlog"       // No binlog magic, e.g. another file matching the pattern
+	IssueEncrypted       = "encrypted"        // Written with binlog_encryption=ON, see EncryptedBinlogError
+	IssueVersionMismatch = "version-mismatch" // Written by another major.minor server version than most files
+)
+
+// FileIssue is a problem found by Precheck in a binlog file
+type FileIssue struct {
+	File   string `json:"file"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+}
+
+// errFoundFormatDescription stops a parse once the format description is read
+var errFoundFormatDescription = errors.New("stop: format description found")
+
+// Precheck reads the header of every file, up to its format description, and returns
+// the problems that would make a scan fail or mislead, in file order: unreadable files,
+// non-binlog files, encrypted binlogs and files of another major.minor server version
+// than most. Patch versions are not compared, a rolling upgrade is expected in archives.
+func (s *Searcher) Precheck(files []string) []FileIssue {
+	var issues []FileIssue
+	versions := make(map[string]string, len(files))
+	for _, file := range files {
+		version, issue := s.precheckFile(file)
+		if issue != nil {
+			issues = append(issues, *issue)
+			continue
+		}
+		versions[file] = version
+	}
+
+	// Most common major.minor, the lowest on a tie
+	counts := make(map[string]int)
+	for _, version := range versions {
+		counts[minorVersion(version)]++
+	}
+	var common string
+	for minor, count := range counts {
+		if common == "" || count > counts[common] || (count == counts[common] && minor < common) {
+			common = minor
+		}
+	}
+
+	for _, file := range files {
+		if version, ok := versions[file]; ok && minorVersion(version) != common {
+			issues = append(issues, FileIssue{File: file, Kind: IssueVersionMismatch,
+				Detail: fmt.Sprintf("server %s, most files are %s", version, common)})
+		}
+	}
+
+	order := make(map[string]int, len(files))
+	for i, file := range files {
+		order[file] = i
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return order[issues[i].File] < order[issues[j].File] })
+	return issues
+}
+
+// precheckFile returns the server version of a file, or its issue
+func (s *Searcher) precheckFile(filepath string) (string, *FileIssue) {
+	if isTextDump(filepath) {
+		return precheckTextDump(filepath)
+	}
+
+	// The magic of local files tells binlogs from other files before parsing
+	if s.store == nil {
+		file, err := os.Open(filepath)
+		if err != nil {
+			return "", &FileIssue{File: filepath, Kind: IssueUnreadable, Detail: err.Error()}
+		}
+		magic := make([]byte, len(replication.BinLogFileHeader))
+		_, err = io.ReadFull(file, magic)
+		file.Close()
+
+		switch {
+		case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+			return "", &FileIssue{File: filepath, Kind: IssueNotBinlog, Detail: "shorter than the binlog magic"}
+		case err != nil:
+			return "", &FileIssue{File: filepath, Kind: IssueUnreadable, Detail: err.Error()}
+		case bytes.Equal(magic, encryptedBinlogMagic):
+			return "", &FileIssue{File: filepath, Kind: IssueEncrypted, Detail: "binlog_encryption=ON, decryption is not supported"}
+		case !bytes.Equal(magic, replication.BinLogFileHeader):
+			return "", &FileIssue{File: filepath, Kind: IssueNotBinlog, Detail: fmt.Sprintf("no binlog magic (starts with %q)", magic)}
+		}
+	}
+
+	var version string
+	parser := s.parserFactory()
+	err := parser.ParseFile(filepath, 0, func(e *replication.BinlogEvent) error {
+		if e.Header.EventType == replication.FORMAT_DESCRIPTION_EVENT {
+			version = e.Event.(*replication.FormatDescriptionEvent).ServerVersion
+			return errFoundFormatDescription
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errFoundFormatDescription) {
+		return "", &FileIssue{File: filepath, Kind: IssueUnreadable, Detail: err.Error()}
+	}
+	if version == "" {
+		return "", &FileIssue{File: filepath, Kind: IssueUnreadable, Detail: "no format description event"}
+	}
+	return version, nil
+}
+
+// precheckTextDump returns the server version of a text dump from its "Start:" header
+func precheckTextDump(filepath string) (string, *FileIssue) {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return "", &FileIssue{File: filepath, Kind: IssueUnreadable, Detail: err.Error()}
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		m := textDumpHeaderRegex.FindStringSubmatch(strings.TrimRight(scanner.Text(), "\r"))
+		if m == nil {
+			continue
+		}
+		if v := textDumpServerVersionRegex.FindStringSubmatch(m[3]); v != nil {
+			return v[1], nil
+		}
+		break // First event is not the format description
+	}
+	if err := scanner.Err(); err != nil {
+		return "", &FileIssue{File: filepath, Kind: IssueUnreadable, Detail: err.Error()}
+	}
+	return "", &FileIssue{File: filepath, Kind: IssueUnreadable, Detail: "no format description header"}
+}
+
+// minorVersion returns the major.minor part of a server version ("8.0.30-log" is "8.0")
+func minorVersion(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	return parts[0] + "." + parts[1]
+}
@@ -0,0 +1,56 @@
+package searcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
+	"github.com/quyetmv/mysql-gtid-position/models"
+)
+
+func TestPrecheck(t *testing.T) {
+	dir := t.TempDir()
+	files, err := testutil.WriteFixtures(dir) // Server 8.0.30
+	if err != nil {
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+
+	// A 5.7 binlog, a stray file and a missing one between the fixtures
+	oldBinlog := filepath.Join(dir, "mysql-bin.000004")
+	binlog := &testutil.Binlog{ServerVersion: "5.7.44-log", Transactions: testutil.Sequence(testutil.FixtureUUID, 301, 310)}
+	if err := binlog.WriteFile(oldBinlog); err != nil {
+		t.Fatalf("Failed to write binlog: %v", err)
+	}
+	stray := filepath.Join(dir, "mysql-bin.000005")
+	if err := os.WriteFile(stray, []byte("not a binlog"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	encrypted := filepath.Join(dir, "mysql-bin.000006")
+	if err := os.WriteFile(encrypted, append([]byte{0xfd, 'b', 'i', 'n'}, make([]byte, 512)...), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	missing := filepath.Join(dir, "mysql-bin.000007")
+
+	searcher := NewSearcher(&models.Config{})
+	issues := searcher.Precheck(append(files, oldBinlog, stray, encrypted, missing))
+
+	want := []FileIssue{
+		{File: oldBinlog, Kind: IssueVersionMismatch},
+		{File: stray, Kind: IssueNotBinlog},
+		{File: encrypted, Kind: IssueEncrypted},
+		{File: missing, Kind: IssueUnreadable},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("Precheck() = %+v, want %d issues", issues, len(want))
+	}
+	for i, issue := range issues {
+		if issue.File != want[i].File || issue.Kind != want[i].Kind || issue.Detail == "" {
+			t.Errorf("Precheck()[%d] = %+v, want %s for %s", i, issue, want[i].Kind, want[i].File)
+		}
+	}
+
+	if issues := searcher.Precheck(files); len(issues) != 0 {
+		t.Errorf("Precheck() of clean fixtures = %+v, want none", issues)
+	}
+}
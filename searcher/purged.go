@@ -0,0 +1,234 @@
+package searcher
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+// PurgedError is returned when the whole target set was purged before the earliest binlog
+type PurgedError struct {
+	Purged string // PREVIOUS_GTIDS of the earliest binlog
+}
+
+func (e *PurgedError) Error() string {
+	return fmt.Sprintf("GTID has been purged, cannot locate (purged up to %s)", e.Purged)
+}
+
+// CheckPurged returns a *PurgedError when targetGTID is fully contained in the
+// PREVIOUS_GTIDS of the earliest binlog, so no file can hold it and scanning is futile.
+// The check is skipped when the search starts at -start-file or -recent rather than the earliest binlog,
+// with Config.NoSmartStart for archives whose headers cannot be trusted, and for an empty
+// target set (any GTID of a -min-gno/-max-gno window).
+func (s *Searcher) CheckPurged(files []string, targetGTID *mysql.GTIDSet) error {
+	if len(files) == 0 || s.config.StartFile != "" || s.config.Recent > 0 || s.config.NoSmartStart || (*targetGTID).IsEmpty() {
+		return nil
+	}
+
+	purged, err := s.ReadHeaderGTIDs(files[0])
+	if err != nil {
+		// Not fatal, the scan itself reports unreadable files
+		if s.verbose {
+			s.logger.Warn("cannot read previous GTIDs, skipping purged check", "file", files[0], "error", err)
+		}
+		return nil
+	}
+	if purged.IsEmpty() {
+		return nil
+	}
+
+	contained, err := headerContains(purged, *targetGTID)
+	if err != nil {
+		if s.verbose {
+			s.logger.Warn("cannot compare previous GTIDs, skipping purged check", "file", files[0], "error", err)
+		}
+		return nil
+	}
+	s.showHeader(files[0], purged, contained, "purged check")
+	if contained {
+		return &PurgedError{Purged: purged.String()}
+	}
+	return nil
+}
+
+// ReadPreviousGTIDs returns the PREVIOUS_GTIDS set of a binlog file or text dump,
+// i.e. every GTID executed before the file starts. The file is read only up to
+// that event, which directly follows the format description. MariaDB binlogs
+// hold no MySQL GTIDs and return an error.
+func (s *Searcher) ReadPreviousGTIDs(filepath string) (*mysql.MysqlGTIDSet, error) {
+	header, err := s.ReadHeaderGTIDs(filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	previousSet, ok := header.(*mysql.MysqlGTIDSet)
+	if !ok {
+		return nil, fmt.Errorf("%s is a MariaDB binlog, it has no previous GTIDs", filepath)
+	}
+	return previousSet, nil
+}
+
+// errNoHeaderGTIDs is returned by readHeaderGTIDs for a file without a PREVIOUS_GTIDS
+// or GTID_LIST event: written before MySQL 5.6, or with a truncated header
+var errNoHeaderGTIDs = errors.New("no PREVIOUS_GTIDS header")
+
+// ReadHeaderGTIDs returns the GTID set at the start of a binlog file or text dump:
+// the MySQL PREVIOUS_GTIDS set, or the MariaDB GTID_LIST (last GTID of each domain
+// and server) as a *mysql.MariadbGTIDSet. Files without a header return an empty
+// MySQL set. Smart selection compares it with headerContains.
+func (s *Searcher) ReadHeaderGTIDs(filepath string) (mysql.GTIDSet, error) {
+	header, err := s.readHeaderGTIDs(filepath)
+	if errors.Is(err, errNoHeaderGTIDs) {
+		return &mysql.MysqlGTIDSet{Sets: make(map[string]*mysql.UUIDSet)}, nil
+	}
+	return header, err
+}
+
+// readHeaderGTIDs is ReadHeaderGTIDs, but returns errNoHeaderGTIDs for a file without
+// a header. The empty set would read as "contains nothing yet", which is true of a
+// file that starts the history, not of one whose header was never written.
+func (s *Searcher) readHeaderGTIDs(filepath string) (mysql.GTIDSet, error) {
+	if isTextDump(filepath) {
+		previousSet, err := readTextDumpPreviousGTIDs(filepath)
+		if err != nil {
+			return nil, err // Not the nil *MysqlGTIDSet, a non-nil interface
+		}
+		return previousSet, nil
+	}
+
+	var headerSet mysql.GTIDSet
+
+	parser := s.parserFactory()
+	err := parser.ParseFile(filepath, 0, func(e *replication.BinlogEvent) error {
+		switch e.Header.EventType {
+		case replication.PREVIOUS_GTIDS_EVENT:
+			previousEvent := e.Event.(*replication.PreviousGTIDsEvent)
+			set, err := mysql.ParseMysqlGTIDSet(previousEvent.GTIDSets)
+			if err != nil {
+				return fmt.Errorf("invalid previous GTIDs %q: %w", previousEvent.GTIDSets, err)
+			}
+			headerSet = set
+			return errFoundPreviousGTIDs
+		case replication.MARIADB_GTID_LIST_EVENT:
+			listEvent := e.Event.(*replication.MariadbGTIDListEvent)
+			set := &mysql.MariadbGTIDSet{Sets: make(map[uint32]map[uint32]*mysql.MariadbGTID)}
+			for i := range listEvent.GTIDs {
+				if err := set.AddSet(&listEvent.GTIDs[i]); err != nil {
+					return fmt.Errorf("invalid GTID list: %w", err)
+				}
+			}
+			headerSet = set
+			return errFoundPreviousGTIDs
+		case replication.GTID_EVENT, replication.ANONYMOUS_GTID_EVENT, replication.MARIADB_GTID_EVENT:
+			return errFoundPreviousGTIDs // Transactions started, no header set
+		}
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, errFoundPreviousGTIDs) {
+		return nil, err
+	}
+	if headerSet == nil {
+		return nil, errNoHeaderGTIDs
+	}
+
+	return headerSet, nil
+}
+
+// headerContains reports whether a header set (see ReadHeaderGTIDs) contains target.
+// MySQL and MariaDB sets cannot be compared: Contain would silently report false.
+func headerContains(header, target mysql.GTIDSet) (bool, error) {
+	if gtidFlavor(header) != gtidFlavor(target) {
+		return false, fmt.Errorf("cannot compare a %s header with a %s GTID set", gtidFlavor(header), gtidFlavor(target))
+	}
+	return header.Contain(target), nil
+}
+
+// showHeader logs a header read by smart selection, whether it contains the target
+// and what was decided for its file, with Config.ShowSkipped
+func (s *Searcher) showHeader(file string, header mysql.GTIDSet, contained bool, decision string) {
+	if s.config.ShowSkipped == "" || s.logger == nil {
+		return
+	}
+	s.logger.Info("smart selection header", "file", file, "previous_gtids", header.String(),
+		"contains_target", contained, "decision", decision)
+}
+
+// showUnprobedHeaders reads and logs the headers smart selection did not need to
+// take its decision, with Config.ShowSkipped set to full
+func (s *Searcher) showUnprobedHeaders(files []string, target mysql.GTIDSet, decision string) {
+	if s.config.ShowSkipped != models.ShowSkippedFull {
+		return
+	}
+	for _, file := range files {
+		header, err := s.ReadHeaderGTIDs(file)
+		if err != nil {
+			s.warn("cannot read previous GTIDs", "file", file, "error", err)
+			continue
+		}
+		contained, _ := headerContains(header, target)
+		s.showHeader(file, header, contained, decision)
+	}
+}
+
+// gtidFlavor returns the server flavor of a GTID set
+func gtidFlavor(set mysql.GTIDSet) string {
+	if _, ok := set.(*mysql.MariadbGTIDSet); ok {
+		return mysql.MariaDBFlavor
+	}
+	return mysql.MySQLFlavor
+}
+
+// readTextDumpPreviousGTIDs reads the "# uuid:1-100," lines printed after the
+// Previous-GTIDs event header of a mysqlbinlog text dump
+func readTextDumpPreviousGTIDs(filepath string) (*mysql.MysqlGTIDSet, error) {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open text dump: %w", err)
+	}
+	defer file.Close()
+
+	previousSet := &mysql.MysqlGTIDSet{Sets: make(map[string]*mysql.UUIDSet)}
+	var reading, found bool
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		if reading {
+			if !strings.HasPrefix(line, "# ") || textDumpAtRegex.MatchString(line) {
+				break // End of the set
+			}
+			set := strings.TrimSpace(strings.TrimPrefix(line, "# "))
+			if err := previousSet.Update(strings.TrimSuffix(set, ",")); err != nil {
+				return nil, fmt.Errorf("invalid previous GTIDs %q: %w", set, err)
+			}
+			continue
+		}
+
+		if m := textDumpHeaderRegex.FindStringSubmatch(line); m != nil {
+			if strings.HasPrefix(m[3], "Previous-GTIDs") {
+				reading, found = true, true
+			} else if strings.HasPrefix(m[3], "GTID") {
+				break // Transactions started, no header set
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read text dump: %w", err)
+	}
+	if !found {
+		return nil, errNoHeaderGTIDs
+	}
+
+	return previousSet, nil
+}
@@ -0,0 +1,68 @@
+package searcher
+
+import (
+	"context"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/quyetmv/mysql-gtid-position/models"
+)
+
+// FindRange returns the range of files to replay for the target set: from the start
+// of its first contained transaction in binlog order to the resume position after the
+// last one. It runs a first and a last selection search, each bound by Config.Timeout,
+// and returns nil if no transaction is contained.
+func (s *Searcher) FindRange(ctx context.Context, files []string, targetGTID *mysql.GTIDSet) (*models.PositionRange, error) {
+	first, err := s.searchWithSelection(ctx, models.SelectionFirst, files, targetGTID)
+	if err != nil || first == nil {
+		return nil, err
+	}
+	last, err := s.searchWithSelection(ctx, models.SelectionLast, files, targetGTID)
+	if err != nil || last == nil {
+		return nil, err
+	}
+
+	positionRange := &models.PositionRange{
+		StartFile:     first.BinlogFile,
+		StartPosition: first.Position,
+		EndFile:       last.BinlogFile,
+		EndPosition:   last.ResumePosition,
+		FirstGTID:     first.GTID,
+		LastGTID:      last.GTID,
+	}
+	if last.ResumeFile != "" {
+		positionRange.EndPosition = last.CommitPosition // Last transaction of its file, the next GTID is in the following one
+	}
+
+	inRange := false
+	for _, file := range files {
+		if file == first.BinlogFile {
+			inRange = true
+		}
+		if inRange {
+			positionRange.Files = append(positionRange.Files, file)
+		}
+		if file == last.BinlogFile {
+			break
+		}
+	}
+
+	return positionRange, nil
+}
+
+// searchWithSelection runs SearchParallel with another selection mode, counting the
+// scanned files in s
+func (s *Searcher) searchWithSelection(ctx context.Context, selection models.Selection, files []string, targetGTID *mysql.GTIDSet) (*models.GTIDPosition, error) {
+	config := *s.config
+	config.Selection = selection
+
+	sibling := NewSearcherWithParser(&config, s.parserFactory)
+	sibling.logger = s.logger
+	sibling.metrics = s.metrics
+	sibling.store = s.store
+	sibling.resultHook = s.resultHook
+	sibling.commitDetector = s.commitDetector
+
+	position, err := sibling.SearchParallel(ctx, files, targetGTID)
+	s.scannedFiles.Add(int64(sibling.ScannedFiles()))
+	return position, err
+}
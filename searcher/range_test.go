@@ -0,0 +1,76 @@
+package searcher
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+func TestFindRange(t *testing.T) {
+	dir := t.TempDir()
+	files, err := testutil.WriteFixtures(dir) // FixtureUUID:1-300 over 3 files
+	if err != nil {
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+
+	// Header, FORMAT_DESCRIPTION and a one-interval PREVIOUS_GTIDS event
+	const firstTransaction = 4 + testutil.FormatDescriptionEventSize + 19 + 48 + 4
+
+	tests := []struct {
+		name      string
+		gtid      string
+		wantRange *models.PositionRange
+	}{
+		{
+			name: "across files",
+			gtid: ":150-250",
+			wantRange: &models.PositionRange{
+				StartFile:     files[1],
+				StartPosition: firstTransaction + 49*testutil.TransactionSize,
+				EndFile:       files[2],
+				EndPosition:   firstTransaction + 50*testutil.TransactionSize + testutil.GTIDEventSize, // After GTID 251
+				FirstGTID:     testutil.FixtureUUID + ":150",
+				LastGTID:      testutil.FixtureUUID + ":250",
+				Files:         files[1:],
+			},
+		},
+		{
+			name: "single transaction",
+			gtid: ":120",
+			wantRange: &models.PositionRange{
+				StartFile:     files[1],
+				StartPosition: firstTransaction + 19*testutil.TransactionSize,
+				EndFile:       files[1],
+				EndPosition:   firstTransaction + 20*testutil.TransactionSize + testutil.GTIDEventSize,
+				FirstGTID:     testutil.FixtureUUID + ":120",
+				LastGTID:      testutil.FixtureUUID + ":120",
+				Files:         files[1:2],
+			},
+		},
+		{name: "not found", gtid: ":400-500"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			targetGTID, err := mysql.ParseMysqlGTIDSet(testutil.FixtureUUID + tt.gtid)
+			if err != nil {
+				t.Fatalf("Invalid GTID: %v", err)
+			}
+
+			searcher := NewSearcher(&models.Config{Parallel: 2, LogFormat: models.LogFormatText})
+			got, err := searcher.FindRange(context.Background(), files, &targetGTID)
+			if err != nil {
+				t.Fatalf("FindRange() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.wantRange) {
+				t.Errorf("FindRange() = %+v, want %+v", got, tt.wantRange)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package searcher
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+// RawEvent is a binlog event with its bytes as written: header, body and checksum
+type RawEvent struct {
+	Type   replication.EventType
+	Offset uint32 // Start position in the file
+	Data   []byte
+}
+
+// RawEvents re-reads the binlog of a found position and returns the GTID event and
+// the commit event of its transaction, undecoded, to debug position math.
+// Text dumps are not supported.
+func (s *Searcher) RawEvents(position *models.GTIDPosition) ([]RawEvent, error) {
+	if isTextDump(position.BinlogFile) {
+		return nil, fmt.Errorf("mysqlbinlog text dumps have no raw events")
+	}
+
+	var gtid, commit *RawEvent
+	parser := s.parserFactory()
+	err := parser.ParseFile(position.BinlogFile, int64(position.Position), func(e *replication.BinlogEvent) error {
+		// Past the header the parser reads the format description first, then the position
+		start := eventStartPosition(e.Header, 0)
+		if e.Header.EventType == replication.FORMAT_DESCRIPTION_EVENT && start != position.Position {
+			return nil
+		}
+
+		event := &RawEvent{Type: e.Header.EventType, Offset: start, Data: bytes.Clone(e.RawData)}
+		if gtid == nil {
+			gtid = event
+		}
+		if e.Header.LogPos >= position.CommitPosition {
+			commit = event
+			return errFoundTarget
+		}
+		return nil
+	})
+
+	if err != nil && !errors.Is(err, errFoundTarget) {
+		return nil, fmt.Errorf("failed to read events at %s:%d: %w", position.BinlogFile, position.Position, err)
+	}
+	if gtid == nil {
+		return nil, fmt.Errorf("no event at %s:%d", position.BinlogFile, position.Position)
+	}
+
+	events := []RawEvent{*gtid}
+	if commit != nil && commit != gtid {
+		events = append(events, *commit)
+	}
+	return events, nil
+}
@@ -0,0 +1,57 @@
+package searcher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+func TestRawEvents(t *testing.T) {
+	files, err := testutil.WriteFixtures(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(testutil.FixtureUUID + ":150")
+
+	searcher := NewSearcher(&models.Config{Parallel: 2})
+	position, err := searcher.SearchParallel(context.Background(), files, &targetGTID)
+	if err != nil || position == nil {
+		t.Fatalf("SearchParallel() = %v, %v", position, err)
+	}
+
+	events, err := searcher.RawEvents(position)
+	if err != nil {
+		t.Fatalf("RawEvents() error = %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("Expected GTID and commit events, got %d events", len(events))
+	}
+
+	want := []struct {
+		eventType replication.EventType
+		offset    uint32
+		size      int
+	}{
+		{replication.GTID_EVENT, position.Position, testutil.GTIDEventSize},
+		{replication.XID_EVENT, position.CommitPosition - testutil.XIDEventSize, testutil.XIDEventSize},
+	}
+	for i, w := range want {
+		event := events[i]
+		if event.Type != w.eventType || event.Offset != w.offset || len(event.Data) != w.size {
+			t.Errorf("Event %d = %s at %d, %d bytes; want %s at %d, %d bytes",
+				i, event.Type, event.Offset, len(event.Data), w.eventType, w.offset, w.size)
+		}
+		if len(event.Data) > 4 && replication.EventType(event.Data[4]) != w.eventType {
+			t.Errorf("Event %d raw header has type %d, want %d", i, event.Data[4], w.eventType)
+		}
+	}
+
+	if _, err := searcher.RawEvents(&models.GTIDPosition{BinlogFile: writeTextDump(t)}); err == nil {
+		t.Error("Expected an error for a text dump")
+	}
+}
@@ -0,0 +1,64 @@
+package searcher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	gtidparser "github.com/quyetmv/mysql-gtid-position/parser"
+
+	"github.com/go-mysql-org/go-mysql/client"
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+// replicaConnectTimeout bounds the connection to a replica
+const replicaConnectTimeout = 10 * time.Second
+
+// ReadReplicaExecutedGTID connects to a live replica and returns the Executed_Gtid_Set
+// of SHOW REPLICA STATUS, or of SHOW SLAVE STATUS on servers older than MySQL 8.0.22
+func ReadReplicaExecutedGTID(ctx context.Context, addr, user, password string) (string, error) {
+	conn, err := client.ConnectWithContext(ctx, addr, user, password, "", replicaConnectTimeout)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to replica %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	result, err := conn.Execute("SHOW REPLICA STATUS")
+	if err != nil {
+		result, err = conn.Execute("SHOW SLAVE STATUS")
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read replica status of %s: %w", addr, err)
+	}
+	defer result.Close()
+
+	return executedSetFromStatus(result.Resultset)
+}
+
+// executedSetFromStatus extracts the Executed_Gtid_Set of a replica status result.
+// Every replication channel has a row, all with the same server-wide set.
+func executedSetFromStatus(status *mysql.Resultset) (string, error) {
+	if status == nil || status.RowNumber() == 0 {
+		return "", fmt.Errorf("replica status is empty, the server is not a replica")
+	}
+
+	column := -1
+	for i, field := range status.Fields {
+		if string(field.Name) == "Executed_Gtid_Set" {
+			column = i
+		}
+	}
+	if column < 0 {
+		return "", fmt.Errorf("replica status has no Executed_Gtid_Set column")
+	}
+
+	executed, err := status.GetString(0, column)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Executed_Gtid_Set: %w", err)
+	}
+	executed = gtidparser.NormalizeGTIDSet(executed)
+	if executed == "" {
+		return "", fmt.Errorf("replica has an empty Executed_Gtid_Set, GTID mode may be off")
+	}
+	return executed, nil
+}
@@ -0,0 +1,59 @@
+package searcher
+
+import (
+	"testing"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+func TestExecutedSetFromStatus(t *testing.T) {
+	const multiUUID = "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5,\n22f7ce9e-7f4c-11ef-8423-3a25d006dfee:1-3"
+
+	tests := []struct {
+		name    string
+		names   []string
+		rows    [][]interface{}
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "single channel",
+			names: []string{"Source_Host", "Executed_Gtid_Set"},
+			rows:  [][]interface{}{{"master", multiUUID}},
+			want:  "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5,22f7ce9e-7f4c-11ef-8423-3a25d006dfee:1-3",
+		},
+		{
+			name:  "several channels",
+			names: []string{"Channel_Name", "Executed_Gtid_Set"},
+			rows:  [][]interface{}{{"a", "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5"}, {"b", "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5"}},
+			want:  "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-5",
+		},
+		{name: "not a replica", names: []string{"Executed_Gtid_Set"}, wantErr: true},
+		{name: "GTID mode off", names: []string{"Executed_Gtid_Set"}, rows: [][]interface{}{{""}}, wantErr: true},
+		{name: "missing column", names: []string{"Source_Host"}, rows: [][]interface{}{{"master"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, err := mysql.BuildSimpleTextResultset(tt.names, tt.rows)
+			if err != nil {
+				t.Fatalf("Failed to build result set: %v", err)
+			}
+			for _, row := range status.RowDatas { // Decoded like the client does
+				values, err := row.ParseText(status.Fields, nil)
+				if err != nil {
+					t.Fatalf("Failed to decode row: %v", err)
+				}
+				status.Values = append(status.Values, values)
+			}
+
+			got, err := executedSetFromStatus(status)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("executedSetFromStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("executedSetFromStatus() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
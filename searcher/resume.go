@@ -0,0 +1,50 @@
+package searcher
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/replication"
+	"github.com/google/uuid"
+)
+
+// resumeInNextFile completes a match committed at the tail of its file, whose next GTID
+// could not be seen by the scan of that file: the next GTID is the first one of nextFile,
+// and the resume position its END_LOG_POS in that file. Without a GTID in nextFile, or
+// when it cannot be read, the match keeps resuming at its commit position.
+func (s *Searcher) resumeInNextFile(position *models.GTIDPosition, nextFile string) {
+	if s.store == nil && isTextDump(nextFile) {
+		return // Text dumps carry no binary positions to resume from
+	}
+
+	var nextGTID string
+	var endPos uint32
+	parser := s.parserFactory()
+	err := parser.ParseFile(nextFile, 0, func(e *replication.BinlogEvent) error {
+		if e.Header.EventType != replication.GTID_EVENT {
+			return nil
+		}
+		gtidEvent := e.Event.(*replication.GTIDEvent)
+		sid, err := uuid.FromBytes(gtidEvent.SID)
+		if err != nil {
+			return err
+		}
+		nextGTID, endPos = fmt.Sprintf("%s:%d", sid, gtidEvent.GNO), e.Header.LogPos
+		return errFoundNextGTID
+	})
+	if err != nil && !errors.Is(err, errFoundNextGTID) {
+		if s.verbose {
+			s.logger.Warn("cannot read the next GTID from the following file", "file", nextFile, "error", err)
+		}
+		return
+	}
+	if nextGTID == "" {
+		return
+	}
+
+	position.NextGTID = nextGTID
+	position.ResumeFile = nextFile
+	position.ResumePosition = endPos
+}
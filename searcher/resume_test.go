@@ -0,0 +1,66 @@
+package searcher
+
+import (
+	"context"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil/binlogtest"
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+// TestSearchParallel_ResumeInNextFile tests a match at the tail of its file, whose next
+// GTID is the first of the following file
+func TestSearchParallel_ResumeInNextFile(t *testing.T) {
+	dir := t.TempDir()
+	files := binlogtest.WriteBinlogs(t, dir, []*testutil.Binlog{
+		{Transactions: testutil.Sequence(testutil.FixtureUUID, 1, 3)},
+		{PreviousGTIDs: testutil.FixtureUUID + ":1-3", Transactions: testutil.Sequence(testutil.FixtureUUID, 4, 5)},
+	})
+	previousGTIDs, _ := mysql.ParseMysqlGTIDSet(testutil.FixtureUUID + ":1-3")
+	previousGTIDsEventSize := uint32(19 + len(previousGTIDs.Encode()) + 4)
+
+	tests := []struct {
+		name           string
+		gtid           string
+		wantNext       string
+		wantResumeFile string
+		wantResume     uint32
+	}{
+		{
+			name:           "tail of first file",
+			gtid:           testutil.FixtureUUID + ":1-3",
+			wantNext:       testutil.FixtureUUID + ":4",
+			wantResumeFile: files[1],
+			wantResume:     4 + testutil.FormatDescriptionEventSize + previousGTIDsEventSize + testutil.GTIDEventSize,
+		},
+		{
+			name:       "tail of last file",
+			gtid:       testutil.FixtureUUID + ":5",
+			wantResume: 4 + testutil.FormatDescriptionEventSize + previousGTIDsEventSize + 2*testutil.TransactionSize,
+		},
+		{
+			name:       "next GTID in the same file",
+			gtid:       testutil.FixtureUUID + ":4",
+			wantNext:   testutil.FixtureUUID + ":5",
+			wantResume: 4 + testutil.FormatDescriptionEventSize + previousGTIDsEventSize + testutil.TransactionSize + testutil.GTIDEventSize,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target, _ := mysql.ParseMysqlGTIDSet(tt.gtid)
+			searcher := NewSearcher(&models.Config{Parallel: 2})
+			result, err := searcher.SearchParallel(context.Background(), files, &target)
+			if err != nil || result == nil {
+				t.Fatalf("SearchParallel() = %v, %v", result, err)
+			}
+			if result.NextGTID != tt.wantNext || result.ResumeFile != tt.wantResumeFile || result.ResumePosition != tt.wantResume {
+				t.Errorf("Expected next %q resuming at %q:%d, got %q at %q:%d", tt.wantNext, tt.wantResumeFile, tt.wantResume,
+					result.NextGTID, result.ResumeFile, result.ResumePosition)
+			}
+		})
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !s3
+
+package searcher
+
+import (
+	"context"
+	"fmt"
+)
+
+// newS3Store reports that S3 support was left out of this build
+func newS3Store(ctx context.Context) (ObjectStore, error) {
+	return nil, fmt.Errorf("S3 support is not built in, rebuild with: go build -tags s3")
+}
@@ -0,0 +1,140 @@
+//go:build ssh
+
+package searcher
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// sshReadBuffer batches the small reads of the binlog parser into few SFTP requests
+const sshReadBuffer = 1 << 20
+
+// sshStore is an ObjectStore reading files of a single host over SFTP. Keys are
+// absolute paths without their leading slash; the bucket, the host of the source
+// URL, is the host the store is connected to.
+type sshStore struct {
+	client *sftp.Client
+}
+
+// newSSHStore connects to the host of an ssh:// source, authenticating with the
+// ssh-agent and the default keys of ~/.ssh, and checking the host key against
+// ~/.ssh/known_hosts. The connection lasts as long as the process.
+func newSSHStore(ctx context.Context, source string) (ObjectStore, error) {
+	u, err := url.Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SSH URL: %w", err)
+	}
+
+	username := u.User.Username()
+	if username == "" {
+		current, err := user.Current()
+		if err != nil {
+			return nil, fmt.Errorf("no user in %s: %w", source, err)
+		}
+		username = current.Username
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "22")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	hostKeys, err := knownhosts.New(filepath.Join(home, ".ssh", "known_hosts"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known hosts: %w", err)
+	}
+
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+	netConn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(netConn, addr, &ssh.ClientConfig{
+		User:            username,
+		Auth:            sshAuthMethods(home),
+		HostKeyCallback: hostKeys,
+		Timeout:         30 * time.Second,
+	})
+	if err != nil {
+		netConn.Close()
+		return nil, fmt.Errorf("SSH handshake with %s failed: %w", addr, err)
+	}
+
+	client, err := sftp.NewClient(ssh.NewClient(sshConn, chans, reqs))
+	if err != nil {
+		sshConn.Close()
+		return nil, fmt.Errorf("failed to start SFTP on %s: %w", addr, err)
+	}
+	return &sshStore{client: client}, nil
+}
+
+// sshAuthMethods returns the ssh-agent, when SSH_AUTH_SOCK is set, then the
+// unencrypted default private keys of ~/.ssh
+func sshAuthMethods(home string) []ssh.AuthMethod {
+	var methods []ssh.AuthMethod
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+
+	var signers []ssh.Signer
+	for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+		key, err := os.ReadFile(filepath.Join(home, ".ssh", name))
+		if err != nil {
+			continue
+		}
+		if signer, err := ssh.ParsePrivateKey(key); err == nil {
+			signers = append(signers, signer)
+		}
+	}
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+	return methods
+}
+
+// List returns the keys of the regular files of the directory prefix
+func (s *sshStore) List(ctx context.Context, bucket, prefix string) ([]string, error) {
+	entries, err := s.client.ReadDir("/" + prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, entry := range entries {
+		if entry.Mode().IsRegular() {
+			keys = append(keys, prefix+entry.Name())
+		}
+	}
+	return keys, nil
+}
+
+// Open streams the content of a file
+func (s *sshStore) Open(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	file, err := s.client.Open("/" + key)
+	if err != nil {
+		return nil, err
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{bufio.NewReaderSize(file, sshReadBuffer), file}, nil
+}
@@ -0,0 +1,13 @@
+//go:build !ssh
+
+package searcher
+
+import (
+	"context"
+	"fmt"
+)
+
+// newSSHStore reports that SSH support was left out of this build
+func newSSHStore(ctx context.Context, source string) (ObjectStore, error) {
+	return nil, fmt.Errorf("SSH support is not built in, rebuild with: go build -tags ssh")
+}
@@ -0,0 +1,74 @@
+package searcher
+
+import (
+	"regexp"
+	"strings"
+)
+
+// statementTableRegex matches the table written by a DML or DDL statement: INSERT/REPLACE
+// INTO, UPDATE, DELETE FROM, CREATE/ALTER/DROP/TRUNCATE/RENAME TABLE and CREATE/DROP INDEX
+// ... ON, capturing the optional schema and the table, backquoted or not
+var statementTableRegex = regexp.MustCompile("(?is)^(?:" +
+	`(?:INSERT|REPLACE)(?:\s+(?:LOW_PRIORITY|DELAYED|HIGH_PRIORITY|IGNORE))*(?:\s+INTO)?` +
+	`|UPDATE(?:\s+(?:LOW_PRIORITY|IGNORE))*` +
+	`|DELETE(?:\s+(?:LOW_PRIORITY|QUICK|IGNORE))*\s+FROM` +
+	`|(?:CREATE(?:\s+TEMPORARY)?|ALTER(?:\s+ONLINE)?|DROP(?:\s+TEMPORARY)?|TRUNCATE|RENAME)\s+TABLE(?:\s+IF(?:\s+NOT)?\s+EXISTS)?` +
+	`|TRUNCATE` +
+	"|(?:CREATE(?:\\s+(?:UNIQUE|FULLTEXT|SPATIAL))?|DROP)\\s+INDEX\\s+(?:`[^`]+`|[\\w$]+)\\s+ON" +
+	")\\s+(?:(`[^`]+`|[\\w$]+)\\s*\\.\\s*)?(`[^`]+`|[\\w$]+)")
+
+// ddlKeywords start the statements that commit on their own, logged without BEGIN and COMMIT
+var ddlKeywords = []string{"CREATE", "ALTER", "DROP", "TRUNCATE", "RENAME"}
+
+// statementTable extracts the table written by a statement-based QUERY_EVENT from its SQL.
+// It is a heuristic: only the first table of a statement is found (not the other tables of
+// a multi-table UPDATE, DELETE, DROP or RENAME), and statements such as LOAD DATA or
+// stored procedure calls are not recognized. The schema is empty when unqualified.
+func statementTable(query string) (schema, table string, ok bool) {
+	m := statementTableRegex.FindStringSubmatch(stripLeadingComments(query))
+	if m == nil {
+		return "", "", false
+	}
+	return strings.Trim(m[1], "`"), strings.Trim(m[2], "`"), true
+}
+
+// isDDLStatement reports whether query is a DDL statement, which is logged as a
+// transaction of its own: a GTID then the QUERY_EVENT, no XID or COMMIT
+func isDDLStatement(query string) bool {
+	query = stripLeadingComments(query)
+	for _, keyword := range ddlKeywords {
+		if isStatement(query, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// stripLeadingComments removes the whitespace and /* */ comments before a statement,
+// such as those added by ORMs and proxies
+func stripLeadingComments(query string) string {
+	for {
+		query = strings.TrimSpace(query)
+		if !strings.HasPrefix(query, "/*") || strings.HasPrefix(query, "/*!") {
+			return query
+		}
+		_, rest, ok := strings.Cut(query[2:], "*/")
+		if !ok {
+			return query
+		}
+		query = rest
+	}
+}
+
+// statementMatchesTable reports whether the table written by a statement-based
+// QUERY_EVENT, an unqualified one being in defaultSchema, is the FilterTable table
+func (s *Searcher) statementMatchesTable(defaultSchema, query string) bool {
+	schema, table, ok := statementTable(query)
+	if !ok {
+		return false
+	}
+	if schema == "" {
+		schema = defaultSchema
+	}
+	return s.matchesTable(schema, table)
+}
@@ -0,0 +1,109 @@
+package searcher
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+func TestStatementTable(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantSchema string
+		wantTable  string
+		wantOK     bool
+	}{
+		{query: "INSERT INTO orders VALUES (1)", wantTable: "orders", wantOK: true},
+		{query: "insert ignore into `shop`.`orders` (id) values (1)", wantSchema: "shop", wantTable: "orders", wantOK: true},
+		{query: "REPLACE LOW_PRIORITY orders SET id = 1", wantTable: "orders", wantOK: true},
+		{query: "UPDATE shop.orders SET paid = 1 WHERE id = 2", wantSchema: "shop", wantTable: "orders", wantOK: true},
+		{query: "/* app:checkout */ DELETE QUICK FROM `orders` WHERE id = 2", wantTable: "orders", wantOK: true},
+		{query: "ALTER TABLE orders ADD COLUMN note TEXT", wantTable: "orders", wantOK: true},
+		{query: "CREATE TABLE IF NOT EXISTS shop.orders (id INT)", wantSchema: "shop", wantTable: "orders", wantOK: true},
+		{query: "DROP TEMPORARY TABLE IF EXISTS tmp_orders", wantTable: "tmp_orders", wantOK: true},
+		{query: "TRUNCATE orders", wantTable: "orders", wantOK: true},
+		{query: "CREATE UNIQUE INDEX idx_ref ON shop.orders (ref)", wantSchema: "shop", wantTable: "orders", wantOK: true},
+		{query: "BEGIN"},
+		{query: "SET @@session.foreign_key_checks = 0"},
+		{query: "CALL archive_orders()"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			schema, table, ok := statementTable(tt.query)
+			if schema != tt.wantSchema || table != tt.wantTable || ok != tt.wantOK {
+				t.Errorf("statementTable() = %q, %q, %v, want %q, %q, %v", schema, table, ok, tt.wantSchema, tt.wantTable, tt.wantOK)
+			}
+		})
+	}
+}
+
+// TestSearchBinlogFile_StatementTableFilter tests the table filter on statement-based
+// transactions, and DDL transactions which have no XID or COMMIT
+func TestSearchBinlogFile_StatementTableFilter(t *testing.T) {
+	targetUUID := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-100", targetUUID))
+
+	query := func(schema, sql string, logPos uint32) *replication.BinlogEvent {
+		return &replication.BinlogEvent{
+			Header: &replication.EventHeader{EventType: replication.QUERY_EVENT, LogPos: logPos, EventSize: 50},
+			Event:  &replication.QueryEvent{Schema: []byte(schema), Query: []byte(sql)},
+		}
+	}
+
+	// GNO 50 inserts into mydb.orders, GNO 60 alters mydb.users, GNO 70 updates other.orders
+	events := []interface{}{
+		createGTIDEvent(targetUUID, 50),
+		query("mydb", "BEGIN", 1050),
+		query("mydb", "INSERT INTO orders VALUES (1)", 1100),
+		query("mydb", "COMMIT", 1150),
+		createGTIDEvent(targetUUID, 60),
+		query("mydb", "ALTER TABLE users ADD COLUMN note TEXT", 1250),
+		createGTIDEvent(targetUUID, 70),
+		query("mydb", "BEGIN", 1350),
+		query("mydb", "UPDATE other.orders SET paid = 1", 1400),
+		query("mydb", "COMMIT", 1450),
+	}
+
+	tests := []struct {
+		name        string
+		filterTable string
+		wantGNO     uint64 // 0 = not found
+		wantCommit  uint32
+	}{
+		{name: "no filter", wantGNO: 70, wantCommit: 1450},
+		{name: "insert", filterTable: "mydb.orders", wantGNO: 50, wantCommit: 1150},
+		{name: "ddl", filterTable: "users", wantGNO: 60, wantCommit: 1250},
+		{name: "qualified in statement", filterTable: "other.orders", wantGNO: 70, wantCommit: 1450},
+		{name: "no statement writes it", filterTable: "mydb.payments"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			searcher := &Searcher{
+				config: &models.Config{FilterTable: tt.filterTable},
+				parserFactory: func() BinlogParser {
+					return &MockBinlogParser{events: events}
+				},
+			}
+
+			result, err := searcher.searchBinlogFile(context.Background(), "test-file", &targetGTID)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var gno uint64
+			var commit uint32
+			if result != nil {
+				gno, commit = result.GNO, result.CommitPosition
+			}
+			if gno != tt.wantGNO || commit != tt.wantCommit {
+				t.Errorf("Expected GNO %d committed at %d, got %d at %d", tt.wantGNO, tt.wantCommit, gno, commit)
+			}
+		})
+	}
+}
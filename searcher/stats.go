@@ -0,0 +1,123 @@
+package searcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+// transactionSize is the size of one committed transaction
+type transactionSize struct {
+	uuid string
+	gtid string
+	size uint64
+}
+
+// TransactionStats computes the sizes of the committed transactions of files that are
+// contained in the target set, overall and per server UUID, scanning files with parallel
+// workers. As with FindNeighbor the search filters do not apply and text dumps are not
+// supported. On Config.Timeout the partial stats are returned with a *TimeoutError.
+func (s *Searcher) TransactionStats(ctx context.Context, files []string, targetGTID *mysql.GTIDSet) (*models.TransactionStats, error) {
+	if err := s.CheckAllowedUUIDs(targetGTID); err != nil {
+		return nil, err
+	}
+
+	if s.config.Timeout > 0 {
+		var cancelTimeout context.CancelFunc
+		ctx, cancelTimeout = context.WithTimeout(ctx, s.config.Timeout)
+		defer cancelTimeout()
+	}
+
+	var sizes []transactionSize
+	var sizesMu sync.Mutex
+	var firstErr error
+	var scanned int
+
+	workers := max(min(s.config.Parallel, len(files)), 1)
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for filepath := range jobs {
+				var fileSizes []transactionSize
+				err := s.walkTransactions(ctx, filepath, func(txn *models.GTIDPosition) error {
+					if s.uuidAllowed(txn.ServerUUID) && txnContained(*targetGTID, txn) {
+						fileSizes = append(fileSizes, transactionSize{uuid: txn.ServerUUID, gtid: txn.GTID, size: uint64(txn.CommitPosition - txn.Position)})
+					}
+					return nil
+				})
+
+				sizesMu.Lock()
+				// Sizes of a file stopped by the deadline are partial but still real
+				sizes = append(sizes, fileSizes...)
+				if err != nil && ctx.Err() == nil && firstErr == nil {
+					firstErr = fmt.Errorf("error scanning %s: %w", filepath, err)
+				} else if err == nil {
+					scanned++
+					s.fileScanned()
+				}
+				sizesMu.Unlock()
+			}
+		}()
+	}
+
+	for _, file := range files {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- file
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	stats := &models.TransactionStats{Overall: sizeStats(sizes), ByUUID: make(map[string]models.SizeStats)}
+	byUUID := make(map[string][]transactionSize)
+	for _, size := range sizes {
+		byUUID[size.uuid] = append(byUUID[size.uuid], size)
+	}
+	for uuid, uuidSizes := range byUUID {
+		stats.ByUUID[uuid] = sizeStats(uuidSizes)
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return stats, &TimeoutError{Timeout: s.config.Timeout, Scanned: scanned, Total: len(files)}
+	}
+	return stats, nil
+}
+
+// sizeStats summarizes transaction sizes; sizes is sorted in place
+func sizeStats(sizes []transactionSize) models.SizeStats {
+	if len(sizes) == 0 {
+		return models.SizeStats{}
+	}
+
+	sort.SliceStable(sizes, func(i, j int) bool { return sizes[i].size < sizes[j].size })
+
+	var total uint64
+	for _, size := range sizes {
+		total += size.size
+	}
+
+	n := len(sizes)
+	largest := sizes[n-1]
+	return models.SizeStats{
+		Count:       uint64(n),
+		Min:         sizes[0].size,
+		Avg:         total / uint64(n),
+		Max:         largest.size,
+		P95:         sizes[(95*n+99)/100-1].size, // Nearest rank: ceil(0.95 n)
+		LargestGTID: largest.gtid,
+	}
+}
@@ -0,0 +1,76 @@
+package searcher
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+func TestTransactionStats(t *testing.T) {
+	uuidA := "3e11fa47-71ca-11e1-9e33-c80aa9429562"
+	uuidB := "4e11fa47-71ca-11e1-9e33-c80aa9429562"
+	targetGTID, _ := mysql.ParseMysqlGTIDSet(fmt.Sprintf("%s:1-100,%s:1-5", uuidA, uuidB))
+
+	// GTID events start at 900, a commit at endPos makes a transaction of endPos-900 bytes
+	xidEvent := func(endPos uint32) *replication.BinlogEvent {
+		return &replication.BinlogEvent{
+			Header: &replication.EventHeader{EventType: replication.XID_EVENT, LogPos: endPos, EventSize: 31},
+			Event:  &replication.XIDEvent{XID: 1},
+		}
+	}
+
+	searcher := &Searcher{
+		config: &models.Config{Parallel: 2},
+		parserFactory: func() BinlogParser {
+			return &SmartMockParser{files: map[string]*MockBinlogParser{
+				"file1": {events: []interface{}{
+					createGTIDEvent(uuidA, 10), xidEvent(1000),
+					createGTIDEvent(uuidA, 200), xidEvent(5000), // Outside the target set
+					createGTIDEvent(uuidA, 11), xidEvent(1300),
+				}},
+				"file2": {events: []interface{}{
+					createGTIDEvent(uuidB, 3), xidEvent(1100),
+					createGTIDEvent(uuidB, 4), // Never committed
+				}},
+			}}
+		},
+	}
+
+	stats, err := searcher.TransactionStats(context.Background(), []string{"file1", "file2"}, &targetGTID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	overall := models.SizeStats{Count: 3, Min: 100, Avg: 233, Max: 400, P95: 400, LargestGTID: uuidA + ":11"}
+	if stats.Overall != overall {
+		t.Errorf("Expected overall %+v, got %+v", overall, stats.Overall)
+	}
+	if got := stats.ByUUID[uuidA]; got.Count != 2 || got.Min != 100 || got.Max != 400 || got.Avg != 250 {
+		t.Errorf("Unexpected stats for %s: %+v", uuidA, got)
+	}
+	if got := stats.ByUUID[uuidB]; got.Count != 1 || got.Min != 200 || got.P95 != 200 {
+		t.Errorf("Unexpected stats for %s: %+v", uuidB, got)
+	}
+}
+
+func TestSizeStats_P95(t *testing.T) {
+	sizes := make([]transactionSize, 20)
+	for i := range sizes {
+		sizes[i] = transactionSize{gtid: fmt.Sprintf("u:%d", i+1), size: uint64(20 - i)}
+	}
+
+	// Nearest rank: the 19th of 20 sorted sizes
+	stats := sizeStats(sizes)
+	if stats.P95 != 19 || stats.Min != 1 || stats.Max != 20 || stats.LargestGTID != "u:1" {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	if empty := sizeStats(nil); empty != (models.SizeStats{}) {
+		t.Errorf("Expected zero stats for no transactions, got %+v", empty)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build linux
+
+package searcher
+
+import "syscall"
+
+// Filesystem magic numbers (statfs f_type) of network filesystems
+var networkFilesystems = map[int64]bool{
+	0x6969:     true, // NFS
+	0xFF534D42: true, // CIFS
+	0xFE534D42: true, // SMB2
+	0x517B:     true, // SMB
+	0x65735546: true, // FUSE (sshfs, s3fs, ...)
+	0x00C36400: true, // Ceph
+	0x47504653: true, // GPFS
+	0x0BD00BD0: true, // Lustre
+}
+
+// isNetworkFilesystem reports whether dir is on a network filesystem
+func isNetworkFilesystem(dir string) bool {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return false
+	}
+	return networkFilesystems[int64(stat.Type)]
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package searcher
+
+// isNetworkFilesystem is only detected on Linux, use the "nfs" storage hint elsewhere
+func isNetworkFilesystem(dir string) bool {
+	return false
+}
@@ -0,0 +1,354 @@
+package searcher
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+	gtidparser "github.com/quyetmv/mysql-gtid-position/parser"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+var (
+	// "# at 197"
+	textDumpAtRegex = regexp.MustCompile(`^# at (\d+)$`)
+	// "#251229 15:09:47 server id 1  end_log_pos 276 CRC32 0x3e9c7a1f 	GTID	last_committed=0 ..."
+	textDumpHeaderRegex = regexp.MustCompile(`^#(\d{6}\s+\d{1,2}:\d{2}:\d{2})\s+server id \d+\s+end_log_pos (\d+)(?:\s+CRC32 0x[0-9a-fA-F]+)?\s+(.*)$`)
+	// "SET @@SESSION.GTID_NEXT= '7396024d-8ec5-11f0-b6ea-fa163e91516e:101'/*!*/;"
+	textDumpGTIDNextRegex = regexp.MustCompile(`^SET @@SESSION\.GTID_NEXT\s*=\s*'([^']+)'`)
+	// "immediate_commit_timestamp=1767000587000000" on GTID headers of MySQL 8.0+
+	textDumpCommitTimestampRegex = regexp.MustCompile(`(immediate_commit_timestamp|original_committed_timestamp)=(\d+)`)
+	// "last_committed=0	sequence_number=1" on GTID headers of MySQL 5.7+
+	textDumpGroupCommitRegex = regexp.MustCompile(`last_committed=(\d+)\s+sequence_number=(\d+)`)
+	// "Start: binlog v 4, server v 8.0.36 created 251229 15:09:40" header of the format description
+	textDumpServerVersionRegex = regexp.MustCompile(`^Start: binlog v \d+, server v (\S+)`)
+	// "use `mydb`/*!*/;"
+	textDumpUseRegex = regexp.MustCompile("^use `([^`]+)`")
+	// "Table_map: `mydb`.`orders` mapped to number 92" header of row events
+	textDumpTableMapRegex = regexp.MustCompile("^Table_map: `([^`]*)`\\.`([^`]*)`")
+)
+
+// isTextDump reports whether a file is a mysqlbinlog text dump rather than a raw binlog.
+// Text dumps start with a "/*!" versioned comment, optionally preceded by "#" comment lines.
+func isTextDump(filepath string) bool {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	head := make([]byte, 4096)
+	n, _ := file.Read(head)
+	head = head[:n]
+
+	// Raw binlogs always begin with the binlog magic
+	if bytes.HasPrefix(head, replication.BinLogFileHeader) {
+		return false
+	}
+
+	for _, line := range strings.Split(string(head), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		return strings.HasPrefix(line, "/*!")
+	}
+
+	return false
+}
+
+// searchTextDumpFile searches for GTID in a mysqlbinlog text dump.
+// Positions come from the "# at" (event start) and "end_log_pos" (event end) comments,
+// so results match what searchBinlogFile returns for the original binlog.
+// With counts non-nil, matches are tallied per server UUID as in scanBinlogFile.
+func (s *Searcher) searchTextDumpFile(ctx context.Context, filepath string, targetGTID *mysql.GTIDSet, counts map[string]uint64) (*models.GTIDPosition, error) {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open text dump: %w", err)
+	}
+	defer file.Close()
+
+	var result *models.GTIDPosition
+	var currentDatabase string                  // Track current database context
+	var currentTransaction *models.GTIDPosition // Track current transaction being processed
+
+	// Current event, taken from the "# at" and header comments
+	var eventStart, eventEnd, eventTimestamp uint32
+	var eventType string
+	var readingPreviousGTIDs bool
+	var txnTimestamp uint32                 // Commit time of the current transaction from its GTID header, 0 before MySQL 8.0
+	var lastCommitted, sequenceNumber int64 // Group commit fields of the current GTID header
+	var tableMatched bool                   // Whether the current transaction wrote a FilterTable table
+	var begun bool                          // Whether the current transaction opened with a BEGIN query
+	var prevGTID, lastGTID string           // GTID before the current one and the current one, in file order
+	endUUID, endGNO, hasEnd := s.endGTID()
+
+	// Convert time filters to Unix timestamps for comparison
+	var startTimestamp, endTimestamp uint32
+	if !s.config.StartTime.IsZero() {
+		startTimestamp = uint32(s.config.StartTime.Unix())
+	}
+	if !s.config.EndTime.IsZero() {
+		endTimestamp = uint32(s.config.EndTime.Unix())
+	}
+
+	executedSet := &mysql.MysqlGTIDSet{Sets: make(map[string]*mysql.UUIDSet)}
+	defer s.observeUUIDs(executedSet)
+
+	// outsideTimeRange reports whether the current event is excluded by the time filters
+	outsideTimeRange := func() bool {
+		if startTimestamp > 0 && eventTimestamp < startTimestamp {
+			return true // Skip events before start time
+		}
+		if endTimestamp > 0 && eventTimestamp > endTimestamp {
+			return true // Skip events after end time
+		}
+		return false
+	}
+
+	// finishTransaction records the current transaction as a match at the current event end
+	finishTransaction := func() {
+		defer func() { currentTransaction = nil }()
+		if s.config.FilterTable != "" && !tableMatched {
+			return // Did not write the filtered table
+		}
+
+		currentTransaction.CommitPosition = eventEnd
+		currentTransaction.ResumePosition = eventEnd // Default resume = commit
+		currentTransaction.Timestamp = eventTimestamp
+		currentTransaction.ExecutedSet = executedSet.String()
+
+		// Keep the best match for the selection mode (highest GNO by default), or only count it
+		if counts != nil {
+			counts[currentTransaction.ServerUUID]++
+		} else if s.preferMatch(currentTransaction, result) {
+			result = currentTransaction
+		}
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024) // Row events can produce very long lines
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return result, err // Partial result of an interrupted scan
+		}
+
+		line := strings.TrimRight(scanner.Text(), "\r")
+
+		// Previous-GTIDs set is printed as "# uuid:1-100," lines after the event header
+		if readingPreviousGTIDs {
+			if strings.HasPrefix(line, "# ") && !textDumpAtRegex.MatchString(line) {
+				previousSet := strings.TrimSpace(strings.TrimPrefix(line, "# "))
+				executedSet.Update(strings.TrimSuffix(previousSet, ","))
+				continue
+			}
+			readingPreviousGTIDs = false
+		}
+
+		if m := textDumpAtRegex.FindStringSubmatch(line); m != nil {
+			pos, _ := strconv.ParseUint(m[1], 10, 32)
+			eventStart = uint32(pos)
+			continue
+		}
+
+		if m := textDumpHeaderRegex.FindStringSubmatch(line); m != nil {
+			end, _ := strconv.ParseUint(m[2], 10, 32)
+			eventEnd = uint32(end)
+			if t, err := time.ParseInLocation("060102 15:04:05", strings.Join(strings.Fields(m[1]), " "), time.Local); err == nil {
+				eventTimestamp = uint32(t.Unix())
+			}
+			eventType = ""
+			if fields := strings.Fields(m[3]); len(fields) > 0 {
+				eventType = fields[0]
+			}
+			if strings.HasPrefix(m[3], "Previous-GTIDs") {
+				readingPreviousGTIDs = true
+			}
+			if v := textDumpServerVersionRegex.FindStringSubmatch(m[3]); v != nil {
+				s.observeServerVersion(filepath, v[1])
+			}
+
+			// Events of a transaction share its commit time when the server provides it
+			if eventType == "GTID" {
+				txnTimestamp = textDumpCommitTimestamp(m[3])
+				lastCommitted, sequenceNumber = textDumpGroupCommit(m[3])
+			}
+			if txnTimestamp > 0 {
+				eventTimestamp = txnTimestamp
+			}
+
+			// Tables written by the transaction, for the table filter
+			if tm := textDumpTableMapRegex.FindStringSubmatch(m[3]); tm != nil && s.matchesTable(tm[1], tm[2]) {
+				tableMatched = true
+			}
+
+			// XID event marks end of InnoDB transaction
+			if eventType == "Xid" && currentTransaction != nil && !outsideTimeRange() {
+				finishTransaction()
+			}
+			continue
+		}
+
+		if m := textDumpUseRegex.FindStringSubmatch(line); m != nil && !outsideTimeRange() {
+			currentDatabase = m[1]
+			if currentTransaction != nil {
+				currentTransaction.Database = currentDatabase // "use" follows the GTID of its transaction
+			}
+			continue
+		}
+
+		if m := textDumpGTIDNextRegex.FindStringSubmatch(line); m != nil {
+			gtidStr := m[1]
+			if gtidStr == "AUTOMATIC" || gtidStr == "ANONYMOUS" {
+				continue
+			}
+
+			// Parse current GTID to check if it's in the target set
+			currentGTID, err := mysql.ParseMysqlGTIDSet(gtidStr)
+			if err != nil {
+				continue // Skip invalid GTIDs
+			}
+			executedSet.Add(*currentGTID.(*mysql.MysqlGTIDSet))
+			prevGTID, lastGTID = lastGTID, gtidStr
+
+			uuidStr, gno, err := gtidparser.ExtractGTIDInfo(gtidStr)
+			if err != nil {
+				continue
+			}
+
+			// Past the end GTID nothing more can match, the scan stops here
+			if hasEnd && strings.EqualFold(uuidStr, endUUID) && gno > endGNO {
+				if result != nil && result.NextGTID == "" {
+					result.NextGTID = gtidStr
+					result.ResumePosition = eventEnd
+				}
+				return result, nil
+			}
+
+			// Filter by time range if specified
+			if outsideTimeRange() {
+				continue
+			}
+
+			// For first/last selection, and with filters for display only, the resume position
+			// is the very next transaction, whether or not it is in the target set or filtered out
+			if result != nil && result.NextGTID == "" && (s.config.Selection.IsOrdered() || s.config.FilterForDisplayOnly) {
+				result.NextGTID = gtidStr
+				result.ResumePosition = eventEnd
+				if s.config.Selection == models.SelectionFirst {
+					return result, nil
+				}
+			}
+
+			// Check if current GTID is contained in target GTID set, from an allowed server
+			if s.gtidMatches(*targetGTID, currentGTID, uuidStr, gno) {
+				// Filter by database if specified
+				if s.config.FilterDatabase != "" && currentDatabase != s.config.FilterDatabase {
+					currentTransaction = nil
+					continue // Skip if database doesn't match
+				}
+
+				// Start tracking this transaction
+				tableMatched, begun = false, false
+				currentTransaction = &models.GTIDPosition{
+					BinlogFile:     filepath,
+					Position:       eventStart, // Start position (GTID event)
+					CommitPosition: eventEnd,   // Will be updated at transaction end
+					ResumePosition: eventEnd,   // Will be updated when next GTID found
+					Timestamp:      eventTimestamp,
+					GTID:           gtidStr,
+					ServerUUID:     uuidStr,
+					GNO:            gno,
+					Database:       currentDatabase,
+					PrevGTID:       prevGTID,
+					LastCommitted:  lastCommitted,
+					SequenceNumber: sequenceNumber,
+					CreatedAt:      time.Now(),
+				}
+				currentTransaction.MatchInterval, _ = gtidparser.MatchInterval(*targetGTID, uuidStr, gno)
+			} else {
+				// GTID outside target range
+				// If we have completed result, this is the next GTID
+				if result != nil && result.NextGTID == "" {
+					result.NextGTID = gtidStr
+					result.ResumePosition = eventEnd // END_LOG_POS of next GTID (same as Kafka Connect)
+					return result, nil
+				}
+				// Resuming at the next transaction, filtered out or not, nothing after it can match
+				if result != nil && s.config.FilterForDisplayOnly && !s.config.Selection.IsOrdered() {
+					return result, nil
+				}
+				currentTransaction = nil
+			}
+			continue
+		}
+
+		// QUERY_EVENT with COMMIT also marks transaction end
+		if currentTransaction != nil && eventType == "Query" && !outsideTimeRange() && isCommitQuery(line) {
+			finishTransaction()
+		}
+
+		// ROLLBACK ends it without a match, the GTID is consumed but nothing was committed
+		if currentTransaction != nil && eventType == "Query" && isRollbackQuery(line) {
+			currentTransaction = nil
+		}
+
+		// Statement lines of a query: BEGIN, or statement-based DML and DDL, matched on
+		// their first line for the table filter. A DDL commits on its own.
+		if currentTransaction != nil && eventType == "Query" {
+			switch {
+			case isStatement(line, "BEGIN"):
+				begun = true
+			case s.config.FilterTable != "" && s.statementMatchesTable(currentDatabase, line):
+				tableMatched = true
+			}
+			if !begun && isDDLStatement(line) && !outsideTimeRange() {
+				finishTransaction()
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read text dump: %w", err)
+	}
+
+	return result, nil
+}
+
+// textDumpCommitTimestamp returns the commit time (Unix seconds) printed on a GTID
+// header, preferring immediate_commit_timestamp, or 0 when the server did not log one
+func textDumpCommitTimestamp(header string) uint32 {
+	var commitTimestamp uint32
+	for _, m := range textDumpCommitTimestampRegex.FindAllStringSubmatch(header, -1) {
+		micros, err := strconv.ParseUint(m[2], 10, 64)
+		if err != nil || micros == 0 {
+			continue
+		}
+		if m[1] == "immediate_commit_timestamp" || commitTimestamp == 0 {
+			commitTimestamp = uint32(micros / 1000000)
+		}
+	}
+	return commitTimestamp
+}
+
+// textDumpGroupCommit returns the last_committed and sequence_number printed on a GTID
+// header, zeros when the server did not log them
+func textDumpGroupCommit(header string) (lastCommitted, sequenceNumber int64) {
+	m := textDumpGroupCommitRegex.FindStringSubmatch(header)
+	if m == nil {
+		return 0, 0
+	}
+	lastCommitted, _ = strconv.ParseInt(m[1], 10, 64)
+	sequenceNumber, _ = strconv.ParseInt(m[2], 10, 64)
+	return lastCommitted, sequenceNumber
+}
@@ -0,0 +1,283 @@
+package searcher
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+
+	"github.com/go-mysql-org/go-mysql/mysql"
+)
+
+const sampleTextDump = `# The proper term is pseudo_replica_mode, but we use this compatibility alias
+# to make the statement usable on server versions 8.0.24 and older.
+/*!50530 SET @@SESSION.PSEUDO_SLAVE_MODE=1*/;
+/*!50003 SET @OLD_COMPLETION_TYPE=@@COMPLETION_TYPE,COMPLETION_TYPE=0*/;
+DELIMITER /*!*/;
+# at 4
+#251229 15:09:40 server id 1  end_log_pos 126 CRC32 0x1f2e3d4c 	Start: binlog v 4, server v 8.0.36 created 251229 15:09:40
+# at 126
+#251229 15:09:40 server id 1  end_log_pos 197 CRC32 0x2a3b4c5d 	Previous-GTIDs
+# 3e11fa47-71ca-11e1-9e33-c80aa9429562:1-9
+# at 197
+#251229 15:09:47 server id 1  end_log_pos 276 CRC32 0x3e9c7a1f 	GTID	last_committed=0	sequence_number=1	rbr_only=yes	original_committed_timestamp=1767000587000000
+/*!50718 SET TRANSACTION ISOLATION LEVEL READ COMMITTED*//*!*/;
+SET @@SESSION.GTID_NEXT= '3e11fa47-71ca-11e1-9e33-c80aa9429562:10'/*!*/;
+# at 276
+#251229 15:09:47 server id 1  end_log_pos 351 CRC32 0x4b5c6d7e 	Query	thread_id=8	exec_time=0	error_code=0
+use ` + "`mydb`" + `/*!*/;
+SET TIMESTAMP=1767000587/*!*/;
+BEGIN
+/*!*/;
+# at 351
+#251229 15:09:47 server id 1  end_log_pos 382 CRC32 0x5c6d7e8f 	Xid = 123
+COMMIT/*!*/;
+# at 382
+#251229 15:09:48 server id 1  end_log_pos 461 CRC32 0x6d7e8f90 	GTID	last_committed=1	sequence_number=2	rbr_only=no	original_committed_timestamp=1767000588000000
+SET @@SESSION.GTID_NEXT= '3e11fa47-71ca-11e1-9e33-c80aa9429562:11'/*!*/;
+# at 461
+#251229 15:09:48 server id 1  end_log_pos 540 CRC32 0x7e8f90a1 	Query	thread_id=8	exec_time=0	error_code=0
+SET TIMESTAMP=1767000588/*!*/;
+BEGIN
+/*!*/;
+# at 540
+#251229 15:09:48 server id 1  end_log_pos 620 CRC32 0x8f90a1b2 	Query	thread_id=8	exec_time=0	error_code=0
+SET TIMESTAMP=1767000588/*!*/;
+COMMIT
+/*!*/;
+SET @@SESSION.GTID_NEXT= 'AUTOMATIC' /* added by mysqlbinlog */ /*!*/;
+DELIMITER ;
+# End of log file
+/*!50003 SET COMPLETION_TYPE=@OLD_COMPLETION_TYPE*/;
+/*!50530 SET @@SESSION.PSEUDO_SLAVE_MODE=0*/;
+`
+
+func writeTextDump(t *testing.T) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "mysql-bin.000001.sql")
+	if err := os.WriteFile(path, []byte(sampleTextDump), 0644); err != nil {
+		t.Fatalf("Failed to create text dump: %v", err)
+	}
+	return path
+}
+
+func TestIsTextDump(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	rawBinlog := filepath.Join(tmpDir, "mysql-bin.000001")
+	if err := os.WriteFile(rawBinlog, []byte{0xfe, 0x62, 0x69, 0x6e, 0x00}, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		want bool
+	}{
+		{name: "text dump", path: writeTextDump(t), want: true},
+		{name: "raw binlog", path: rawBinlog, want: false},
+		{name: "missing file", path: filepath.Join(tmpDir, "missing"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTextDump(tt.path); got != tt.want {
+				t.Errorf("isTextDump() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchTextDumpFile(t *testing.T) {
+	path := writeTextDump(t)
+	targetGTID, _ := mysql.ParseMysqlGTIDSet("3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10")
+
+	searcher := NewSearcher(&models.Config{})
+
+	// searchBinlogFile must detect the dump and branch to the text parser
+	result, err := searcher.searchBinlogFile(context.Background(), path, &targetGTID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result == nil {
+		t.Fatal("Expected result, got nil")
+	}
+
+	if result.GTID != "3e11fa47-71ca-11e1-9e33-c80aa9429562:10" {
+		t.Errorf("Expected GTID :10, got %s", result.GTID)
+	}
+	if result.Position != 197 {
+		t.Errorf("Expected start position 197, got %d", result.Position)
+	}
+	if result.CommitPosition != 382 {
+		t.Errorf("Expected commit position 382, got %d", result.CommitPosition)
+	}
+	if result.ResumePosition != 461 {
+		t.Errorf("Expected resume position 461 (next GTID end_log_pos), got %d", result.ResumePosition)
+	}
+	if result.NextGTID != "3e11fa47-71ca-11e1-9e33-c80aa9429562:11" {
+		t.Errorf("Expected next GTID :11, got %s", result.NextGTID)
+	}
+	if result.Database != "mydb" {
+		t.Errorf("Expected database mydb, got %s", result.Database)
+	}
+	if result.ExecutedSet != "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10" {
+		t.Errorf("Expected executed set :1-10, got %s", result.ExecutedSet)
+	}
+	if result.LastCommitted != 0 || result.SequenceNumber != 1 {
+		t.Errorf("Expected last_committed 0 and sequence_number 1, got %d and %d", result.LastCommitted, result.SequenceNumber)
+	}
+	if version := searcher.ServerVersion(); version != "8.0.36" {
+		t.Errorf("Expected server version 8.0.36, got %q", version)
+	}
+}
+
+func TestSearchTextDumpFile_QueryCommit(t *testing.T) {
+	path := writeTextDump(t)
+	targetGTID, _ := mysql.ParseMysqlGTIDSet("3e11fa47-71ca-11e1-9e33-c80aa9429562:11")
+
+	searcher := NewSearcher(&models.Config{})
+
+	result, err := searcher.searchTextDumpFile(context.Background(), path, &targetGTID, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result == nil {
+		t.Fatal("Expected result, got nil")
+	}
+
+	// Non-InnoDB transaction ends with a COMMIT query event
+	if result.CommitPosition != 620 {
+		t.Errorf("Expected commit position 620, got %d", result.CommitPosition)
+	}
+	if result.ResumePosition != 620 {
+		t.Errorf("Expected resume position 620 (no next GTID), got %d", result.ResumePosition)
+	}
+}
+
+func TestSearchTextDumpFile_TableFilter(t *testing.T) {
+	// Transaction 10 writes mydb.orders, transaction 11 no table
+	dump := strings.Replace(sampleTextDump, "# at 351\n", "# at 320\n"+
+		"#251229 15:09:47 server id 1  end_log_pos 351 CRC32 0x01020304 \tTable_map: `mydb`.`orders` mapped to number 92\n"+
+		"# at 351\n", 1)
+	path := filepath.Join(t.TempDir(), "mysql-bin.000001.sql")
+	if err := os.WriteFile(path, []byte(dump), 0644); err != nil {
+		t.Fatalf("Failed to create text dump: %v", err)
+	}
+	targetGTID, _ := mysql.ParseMysqlGTIDSet("3e11fa47-71ca-11e1-9e33-c80aa9429562:10-11")
+
+	tests := []struct {
+		name    string
+		config  *models.Config
+		wantGNO uint64 // 0 = not found
+	}{
+		{name: "no filter", config: &models.Config{}, wantGNO: 11},
+		{name: "table", config: &models.Config{FilterTable: "orders"}, wantGNO: 10},
+		{name: "qualified table", config: &models.Config{FilterTable: "mydb.orders"}, wantGNO: 10},
+		{name: "other database", config: &models.Config{FilterTable: "orders", FilterDatabase: "other"}, wantGNO: 0},
+		{name: "other table", config: &models.Config{FilterTable: "mydb.users"}, wantGNO: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewSearcher(tt.config).searchTextDumpFile(context.Background(), path, &targetGTID, nil)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var gno uint64
+			if result != nil {
+				gno = result.GNO
+			}
+			if gno != tt.wantGNO {
+				t.Errorf("Expected GNO %d, got %d", tt.wantGNO, gno)
+			}
+		})
+	}
+}
+
+func TestReadPreviousGTIDs_TextDump(t *testing.T) {
+	searcher := &Searcher{config: &models.Config{}}
+
+	previousSet, err := searcher.ReadPreviousGTIDs(writeTextDump(t))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if previousSet.String() != "3e11fa47-71ca-11e1-9e33-c80aa9429562:1-9" {
+		t.Errorf("Expected previous GTIDs 3e11fa47-71ca-11e1-9e33-c80aa9429562:1-9, got %s", previousSet.String())
+	}
+}
+
+func TestReadHeaderGTIDs_TextDumpWithoutHeader(t *testing.T) {
+	// Only the Previous-GTIDs event is dropped, the transactions follow the format description
+	var dump strings.Builder
+	skip := false
+	for _, line := range strings.SplitAfter(sampleTextDump, "\n") {
+		if strings.Contains(line, "Previous-GTIDs") {
+			skip = true
+		} else if skip && strings.HasPrefix(line, "# at ") {
+			skip = false
+		}
+		if !skip {
+			dump.WriteString(line)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "mysql-bin.000002.sql")
+	if err := os.WriteFile(path, []byte(dump.String()), 0644); err != nil {
+		t.Fatalf("Failed to create text dump: %v", err)
+	}
+
+	searcher := &Searcher{config: &models.Config{}}
+	header, err := searcher.readHeaderGTIDs(path)
+	if !errors.Is(err, errNoHeaderGTIDs) {
+		t.Fatalf("Expected errNoHeaderGTIDs, got %v", err)
+	}
+	if header != nil {
+		t.Errorf("Expected a nil header, got %#v", header)
+	}
+
+	previousSet, err := searcher.ReadHeaderGTIDs(path)
+	if err != nil || !previousSet.IsEmpty() {
+		t.Errorf("ReadHeaderGTIDs() = %v, %v, want an empty set", previousSet, err)
+	}
+}
+
+func TestTextDumpCommitTimestamp(t *testing.T) {
+	tests := []struct {
+		header string
+		want   uint32
+	}{
+		{header: "GTID	last_committed=0	sequence_number=1	original_committed_timestamp=1767000587000000	immediate_commit_timestamp=1767000590000000", want: 1767000590},
+		{header: "GTID	last_committed=0	sequence_number=1	original_committed_timestamp=1767000587000000", want: 1767000587},
+		{header: "GTID	last_committed=0	sequence_number=1", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := textDumpCommitTimestamp(tt.header); got != tt.want {
+			t.Errorf("textDumpCommitTimestamp(%q) = %d, want %d", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestTextDumpGroupCommit(t *testing.T) {
+	tests := []struct {
+		header            string
+		wantLastCommitted int64
+		wantSequence      int64
+	}{
+		{header: "GTID	last_committed=41	sequence_number=43	rbr_only=yes", wantLastCommitted: 41, wantSequence: 43},
+		{header: "GTID	last_committed=0	sequence_number=1", wantSequence: 1},
+		{header: "GTID [commit=yes]"}, // MySQL 5.6
+	}
+
+	for _, tt := range tests {
+		lastCommitted, sequenceNumber := textDumpGroupCommit(tt.header)
+		if lastCommitted != tt.wantLastCommitted || sequenceNumber != tt.wantSequence {
+			t.Errorf("textDumpGroupCommit(%q) = %d, %d, want %d, %d", tt.header, lastCommitted, sequenceNumber, tt.wantLastCommitted, tt.wantSequence)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package searcher
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/go-mysql-org/go-mysql/replication"
+)
+
+// VerifyPosition checks that pos is an event boundary of a binlog file: the event
+// starting there decodes cleanly, checksum included, and its header agrees on where
+// it starts. The end of the file is a valid boundary too, nothing was written after.
+// Text dumps are not supported.
+func (s *Searcher) VerifyPosition(file string, pos uint32) error {
+	if isTextDump(file) {
+		return fmt.Errorf("positions of mysqlbinlog text dumps cannot be verified")
+	}
+	if pos < uint32(len(replication.BinLogFileHeader)) {
+		return fmt.Errorf("position %d is inside the binlog file header", pos)
+	}
+
+	if info, err := os.Stat(file); err == nil {
+		if int64(pos) == info.Size() {
+			return nil
+		}
+		if int64(pos) > info.Size() {
+			return fmt.Errorf("position %d is past the end of the file (%d bytes)", pos, info.Size())
+		}
+	}
+
+	verified := false
+	parser := s.parserFactory()
+	err := parser.ParseFile(file, int64(pos), func(e *replication.BinlogEvent) error {
+		// Past the header the parser reads the format description first, then pos
+		start := eventStartPosition(e.Header, 0)
+		if e.Header.EventType == replication.FORMAT_DESCRIPTION_EVENT && start != pos {
+			return nil
+		}
+
+		if e.Header.LogPos != 0 && start != pos {
+			return fmt.Errorf("event header at position %d says it starts at %d", pos, start)
+		}
+		verified = true
+		return errFoundTarget
+	})
+
+	if errors.Is(err, errFoundTarget) {
+		err = nil
+	}
+	if err != nil {
+		return fmt.Errorf("no valid event at position %d: %w", pos, err)
+	}
+	if !verified {
+		return fmt.Errorf("no event at position %d", pos)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package searcher
+
+import (
+	"os"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
+	"github.com/quyetmv/mysql-gtid-position/models"
+)
+
+func TestVerifyPosition(t *testing.T) {
+	files, err := testutil.WriteFixtures(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to write fixtures: %v", err)
+	}
+	file := files[1] // FixtureUUID:101-200, first transaction at 196
+
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatalf("Failed to stat fixture: %v", err)
+	}
+	size := uint32(info.Size())
+
+	tests := []struct {
+		name    string
+		pos     uint32
+		wantErr bool
+	}{
+		{name: "format description", pos: 4},
+		{name: "first GTID event", pos: 196},
+		{name: "after GTID event", pos: 196 + testutil.GTIDEventSize},
+		{name: "second transaction", pos: 196 + testutil.TransactionSize},
+		{name: "end of file", pos: size},
+		{name: "file header", pos: 2, wantErr: true},
+		{name: "inside an event", pos: 197, wantErr: true},
+		{name: "past the end", pos: size + 10, wantErr: true},
+	}
+
+	searcher := NewSearcher(&models.Config{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := searcher.VerifyPosition(file, tt.pos)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyPosition(%d) error = %v, wantErr %v", tt.pos, err, tt.wantErr)
+			}
+		})
+	}
+}
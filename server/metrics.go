@@ -0,0 +1,64 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Lookup outcomes, used as the "result" label of the lookup counter
+const (
+	lookupFound    = "found"
+	lookupNotFound = "not_found"
+	lookupTimeout  = "timeout"
+	lookupError    = "error"
+)
+
+// metrics holds the Prometheus collectors of a server on its own registry,
+// so several servers (or tests) never clash on the default registry
+type metrics struct {
+	registry      *prometheus.Registry
+	lookups       *prometheus.CounterVec
+	filesScanned  prometheus.Counter
+	lookupLatency prometheus.Histogram
+}
+
+// newMetrics creates and registers the server collectors
+func newMetrics() *metrics {
+	m := &metrics{
+		registry: prometheus.NewRegistry(),
+		lookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "gtid_lookups_total",
+			Help: "GTID lookups served, by result (found, not_found, timeout, error).",
+		}, []string{"result"}),
+		filesScanned: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "gtid_binlog_files_scanned_total",
+			Help: "Binlog files scanned to the end by lookups.",
+		}),
+		lookupLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "gtid_lookup_duration_seconds",
+			Help:    "Latency of GTID lookups.",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300},
+		}),
+	}
+	m.registry.MustRegister(m.lookups, m.filesScanned, m.lookupLatency)
+	return m
+}
+
+// FileScanned implements searcher.Metrics
+func (m *metrics) FileScanned() {
+	m.filesScanned.Inc()
+}
+
+// observeLookup records a finished lookup and its latency
+func (m *metrics) observeLookup(result string, elapsed time.Duration) {
+	m.lookups.WithLabelValues(result).Inc()
+	m.lookupLatency.Observe(elapsed.Seconds())
+}
+
+// handler serves the collectors in the Prometheus exposition format
+func (m *metrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
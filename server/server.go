@@ -0,0 +1,127 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+	"github.com/quyetmv/mysql-gtid-position/parser"
+	"github.com/quyetmv/mysql-gtid-position/searcher"
+)
+
+// Server answers GTID position lookups over HTTP using searcher.Find
+type Server struct {
+	config  *models.Config // Defaults for every lookup (dir, pattern, parallel, ...)
+	metrics *metrics
+
+	// newSearcher creates the searcher of a lookup, reading the S3 or SSH source of the
+	// config if any; replaced by a fake store in tests
+	newSearcher func(ctx context.Context, config *models.Config) (*searcher.Searcher, error)
+}
+
+// NewServer creates a new Server with the given default config
+func NewServer(config *models.Config) *Server {
+	return &Server{
+		config:      config,
+		metrics:     newMetrics(),
+		newSearcher: searcher.NewSearcherForSource,
+	}
+}
+
+// Handler returns the HTTP routes of the server
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /find", s.handleFind)
+	mux.HandleFunc("GET /healthz", s.handleHealthz)
+	mux.Handle("GET /metrics", s.metrics.handler())
+	return mux
+}
+
+// ListenAndServe serves lookups on addr (e.g. ":8080") until the listener fails
+func (s *Server) ListenAndServe(addr string) error {
+	return http.ListenAndServe(addr, s.Handler())
+}
+
+// handleFind serves GET /find?gtid=UUID:5&dir=/data, returning the models.GTIDPosition as JSON
+func (s *Server) handleFind(w http.ResponseWriter, r *http.Request) {
+	cfg := *s.config // Per-request copy, lookups must not share state
+	cfg.TargetGTID = r.URL.Query().Get("gtid")
+	if dir := r.URL.Query().Get("dir"); dir != "" {
+		cfg.BinlogDir = dir
+		cfg.S3Source, cfg.SSHSource = "", "" // An explicit directory overrides the default remote source
+	}
+
+	if cfg.TargetGTID == "" {
+		writeError(w, http.StatusBadRequest, "gtid parameter is required")
+		return
+	}
+	if _, err := parser.ParseGTID(cfg.TargetGTID); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid GTID format: "+err.Error())
+		return
+	}
+	remote := cfg.S3Source != "" || cfg.SSHSource != ""
+	if cfg.BinlogDir == "" && !remote {
+		writeError(w, http.StatusBadRequest, "dir parameter is required")
+		return
+	}
+	if _, err := os.Stat(cfg.BinlogDir); !remote && os.IsNotExist(err) {
+		writeError(w, http.StatusBadRequest, "binlog directory does not exist: "+cfg.BinlogDir)
+		return
+	}
+
+	start := time.Now()
+	srch, err := s.newSearcher(r.Context(), &cfg)
+	if err != nil {
+		s.metrics.observeLookup(lookupError, time.Since(start))
+		writeError(w, http.StatusInternalServerError, "failed to open the binlog source: "+err.Error())
+		return
+	}
+	srch.SetMetrics(s.metrics)
+	result, err := srch.Find(r.Context())
+
+	var purgedErr *searcher.PurgedError
+	if errors.As(err, &purgedErr) {
+		s.metrics.observeLookup(lookupNotFound, time.Since(start))
+		writeError(w, http.StatusGone, err.Error())
+		return
+	}
+
+	var timeoutErr *searcher.TimeoutError
+	if errors.As(err, &timeoutErr) {
+		s.metrics.observeLookup(lookupTimeout, time.Since(start))
+		writeError(w, http.StatusGatewayTimeout, err.Error())
+		return
+	}
+	if err != nil {
+		s.metrics.observeLookup(lookupError, time.Since(start))
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if result == nil {
+		s.metrics.observeLookup(lookupNotFound, time.Since(start))
+		writeError(w, http.StatusNotFound, "GTID not found in binlog files")
+		return
+	}
+
+	s.metrics.observeLookup(lookupFound, time.Since(start))
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleHealthz reports that the server is up
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,186 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/quyetmv/mysql-gtid-position/internal/testutil"
+	"github.com/quyetmv/mysql-gtid-position/models"
+	"github.com/quyetmv/mysql-gtid-position/searcher"
+)
+
+// Minimal mysqlbinlog text dump with one committed transaction (GNO 10)
+const textDump = `/*!50530 SET @@SESSION.PSEUDO_SLAVE_MODE=1*/;
+DELIMITER /*!*/;
+# at 197
+#251229 15:09:47 server id 1  end_log_pos 276 CRC32 0x3e9c7a1f 	GTID	last_committed=0	sequence_number=1
+SET @@SESSION.GTID_NEXT= '3e11fa47-71ca-11e1-9e33-c80aa9429562:10'/*!*/;
+# at 276
+#251229 15:09:47 server id 1  end_log_pos 351 CRC32 0x4b5c6d7e 	Query	thread_id=8	exec_time=0	error_code=0
+BEGIN
+/*!*/;
+# at 351
+#251229 15:09:47 server id 1  end_log_pos 382 CRC32 0x5c6d7e8f 	Xid = 123
+COMMIT/*!*/;
+DELIMITER ;
+`
+
+func TestHandler(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mysql-bin.000001"), []byte(textDump), 0644); err != nil {
+		t.Fatalf("Failed to create binlog: %v", err)
+	}
+
+	srv := NewServer(&models.Config{
+		FilePattern: "mysql-bin.*",
+		Parallel:    2,
+		LogFormat:   models.LogFormatText,
+	})
+	handler := srv.Handler()
+
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+	}{
+		{name: "healthz", url: "/healthz", wantStatus: http.StatusOK},
+		{name: "found", url: "/find?gtid=3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10&dir=" + dir, wantStatus: http.StatusOK},
+		{name: "not found", url: "/find?gtid=3e11fa47-71ca-11e1-9e33-c80aa9429562:20-30&dir=" + dir, wantStatus: http.StatusNotFound},
+		{name: "missing gtid", url: "/find?dir=" + dir, wantStatus: http.StatusBadRequest},
+		{name: "invalid gtid", url: "/find?gtid=invalid&dir=" + dir, wantStatus: http.StatusBadRequest},
+		{name: "missing dir", url: "/find?gtid=3e11fa47-71ca-11e1-9e33-c80aa9429562:10", wantStatus: http.StatusBadRequest},
+		{name: "nonexistent dir", url: "/find?gtid=3e11fa47-71ca-11e1-9e33-c80aa9429562:10&dir=/nonexistent", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Expected JSON content type, got %q", ct)
+			}
+		})
+	}
+
+	// Found lookup returns the GTIDPosition
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/find?gtid=3e11fa47-71ca-11e1-9e33-c80aa9429562:1-10&dir="+dir, nil))
+
+	var pos models.GTIDPosition
+	if err := json.Unmarshal(rec.Body.Bytes(), &pos); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if pos.GNO != 10 || pos.Position != 197 || pos.CommitPosition != 382 {
+		t.Errorf("Unexpected position: %+v", pos)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "mysql-bin.000001"), []byte(textDump), 0644); err != nil {
+		t.Fatalf("Failed to create binlog: %v", err)
+	}
+
+	srv := NewServer(&models.Config{FilePattern: "mysql-bin.*", Parallel: 2})
+	handler := srv.Handler()
+
+	for _, gtid := range []string{"3e11fa47-71ca-11e1-9e33-c80aa9429562:10", "3e11fa47-71ca-11e1-9e33-c80aa9429562:20"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/find?gtid="+gtid+"&dir="+dir, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		`gtid_lookups_total{result="found"} 1`,
+		`gtid_lookups_total{result="not_found"} 1`,
+		`gtid_binlog_files_scanned_total 2`,
+		`gtid_lookup_duration_seconds_count 2`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected metrics to contain %q", want)
+		}
+	}
+}
+
+// memStore is an in-memory searcher.ObjectStore keyed by "bucket/key"
+type memStore struct {
+	objects map[string][]byte
+}
+
+func (m *memStore) List(ctx context.Context, bucket, prefix string) ([]string, error) {
+	var keys []string
+	for name := range m.objects {
+		if key, ok := strings.CutPrefix(name, bucket+"/"); ok && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (m *memStore) Open(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+	data, ok := m.objects[bucket+"/"+key]
+	if !ok {
+		return nil, fmt.Errorf("no such key: %s", key)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// TestHandler_S3Source checks that a lookup without dir reads the default S3 source,
+// not the working directory, and that a store failing to open is a server error
+func TestHandler_S3Source(t *testing.T) {
+	data, err := (&testutil.Binlog{Transactions: testutil.Sequence(testutil.FixtureUUID, 1, 100)}).Bytes()
+	if err != nil {
+		t.Fatalf("Failed to build binlog: %v", err)
+	}
+	store := &memStore{objects: map[string][]byte{"backups/prod/mysql-bin.000001": data}}
+
+	srv := NewServer(&models.Config{S3Source: "s3://backups/prod/mysql-bin.*", Parallel: 2})
+	srv.newSearcher = func(ctx context.Context, config *models.Config) (*searcher.Searcher, error) {
+		if config.S3Source == "" {
+			return searcher.NewSearcher(config), nil
+		}
+		return searcher.NewObjectStoreSearcher(config, store), nil
+	}
+	handler := srv.Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/find?gtid="+testutil.FixtureUUID+":50", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var pos models.GTIDPosition
+	if err := json.Unmarshal(rec.Body.Bytes(), &pos); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if pos.GNO != 50 || pos.BinlogFile != "s3://backups/prod/mysql-bin.000001" {
+		t.Errorf("Expected GNO 50 in the S3 object, got %+v", pos)
+	}
+
+	srv.newSearcher = func(ctx context.Context, config *models.Config) (*searcher.Searcher, error) {
+		return nil, errors.New("no credentials")
+	}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/find?gtid="+testutil.FixtureUUID+":50", nil))
+	if rec.Code != http.StatusInternalServerError || !strings.Contains(rec.Body.String(), "no credentials") {
+		t.Errorf("Expected status 500 with the store error, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
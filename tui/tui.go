@@ -0,0 +1,130 @@
+//go:build tui
+
+// Package tui browses found GTID positions in an interactive terminal table
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+
+	"github.com/quyetmv/mysql-gtid-position/exporter"
+	"github.com/quyetmv/mysql-gtid-position/models"
+)
+
+// helpLine lists the keys of the table view
+const helpLine = "↑/↓ move • c copy resume position • s CHANGE REPLICATION statement • q quit"
+
+// model is the bubbletea model of the position table
+type model struct {
+	table     table.Model
+	positions []*models.GTIDPosition
+	status    string // Result of the last action, shown under the table
+	statement string // Last generated statement, printed again on exit
+}
+
+// Run shows positions in a navigable table until the user quits, with timestamps in
+// timeFormat (see models.FormatTimestamp). The last generated CHANGE REPLICATION
+// statement is printed to stdout on exit.
+func Run(positions []*models.GTIDPosition, timeFormat string) error {
+	columns := []table.Column{
+		{Title: "File", Width: 18},
+		{Title: "Start", Width: 11},
+		{Title: "Commit", Width: 11},
+		{Title: "Resume", Width: 11},
+		{Title: "GTID", Width: 48},
+		{Title: "Timestamp", Width: 25},
+	}
+
+	rows := make([]table.Row, len(positions))
+	for i, pos := range positions {
+		rows[i] = table.Row{
+			filepath.Base(pos.BinlogFile),
+			strconv.FormatUint(uint64(pos.Position), 10),
+			strconv.FormatUint(uint64(pos.CommitPosition), 10),
+			strconv.FormatUint(uint64(pos.ResumePosition), 10),
+			pos.GTID,
+			models.FormatTimestamp(pos.Timestamp, timeFormat),
+		}
+	}
+
+	m := model{
+		table: table.New(
+			table.WithColumns(columns),
+			table.WithRows(rows),
+			table.WithFocused(true),
+			table.WithHeight(min(len(rows)+1, 20)),
+		),
+		positions: positions,
+	}
+
+	final, err := tea.NewProgram(m).Run()
+	if err != nil {
+		return fmt.Errorf("TUI error: %w", err)
+	}
+	if statement := final.(model).statement; statement != "" {
+		fmt.Println(statement)
+	}
+	return nil
+}
+
+func (m model) Init() tea.Cmd {
+	return nil
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.table.SetHeight(max(msg.Height-4, 3)) // Room for the help and status lines
+		return m, nil
+
+	case tea.KeyMsg:
+		pos := m.selected()
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "c":
+			if pos != nil {
+				resume := fmt.Sprintf("%s:%d", filepath.Base(pos.ResumeBinlogFile()), pos.ResumePosition)
+				termenv.Copy(resume) // OSC 52, supported by most terminals and tmux
+				m.status = "Copied " + resume
+			}
+			return m, nil
+		case "s":
+			if pos != nil {
+				m.statement = exporter.ChangeReplicationSQL(pos)
+				termenv.Copy(m.statement)
+				m.status = "Copied " + m.statement
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.table, cmd = m.table.Update(msg)
+	return m, cmd
+}
+
+func (m model) View() string {
+	var b strings.Builder
+	b.WriteString(m.table.View())
+	b.WriteString("\n" + helpLine + "\n")
+	if m.status != "" {
+		b.WriteString(m.status + "\n")
+	}
+	return b.String()
+}
+
+// selected returns the position of the selected row, nil without rows
+func (m model) selected() *models.GTIDPosition {
+	cursor := m.table.Cursor()
+	if cursor < 0 || cursor >= len(m.positions) {
+		return nil
+	}
+	return m.positions[cursor]
+}
@@ -0,0 +1,15 @@
+//go:build !tui
+
+// Package tui browses found GTID positions in an interactive terminal table
+package tui
+
+import (
+	"fmt"
+
+	"github.com/quyetmv/mysql-gtid-position/models"
+)
+
+// Run reports that the TUI was left out of this build
+func Run(positions []*models.GTIDPosition, timeFormat string) error {
+	return fmt.Errorf("TUI support is not built in, rebuild with: go build -tags tui")
+}